@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tobi/try/internal/workspace"
+)
+
+var (
+	cloneQuiet bool
+	cloneFlat  bool
+	cloneInto  string
+)
+
+var cloneCmd = &cobra.Command{
+	Use:   "clone <url>",
+	Short: "Clone a repository and output a cd script",
+	Long: `Clone a git repository into a new date-prefixed workspace and cd into it.
+
+Unlike 'try <url>', which leaves the clone to the shell, try runs git
+itself: progress is shown as it clones, and the variables in the
+[clone_env] config table (e.g. GIT_SSH_COMMAND) are set for git.
+
+With --quiet, git's output is only shown if the clone fails. With --flat,
+the workspace is named user-repo, without the date prefix, for repos you
+mean to keep.
+
+With --into, the repository is cloned inside an existing workspace, named
+by a query as for 'go-try which' or by its path, into a directory named
+after the repo:
+
+  try clone --into redis https://github.com/redis/hiredis`,
+	Args: cobra.ExactArgs(1),
+	RunE: runClone,
+}
+
+func init() {
+	cloneCmd.Flags().BoolVarP(&cloneQuiet, "quiet", "q", false, "don't show clone progress")
+	cloneCmd.Flags().BoolVar(&cloneFlat, "flat", false, "name the workspace user-repo, without the date prefix")
+	cloneCmd.Flags().StringVar(&cloneInto, "into", "", "clone inside this existing workspace")
+	cloneCmd.MarkFlagsMutuallyExclusive("flat", "into")
+	execCmd.AddCommand(cloneCmd)
+}
+
+func runClone(cmd *cobra.Command, args []string) error {
+	basePath := getTriesPath()
+
+	// Ensure tries directory exists
+	if err := workspace.EnsureDir(basePath); err != nil {
+		return fmt.Errorf("failed to create tries directory: %w", err)
+	}
+
+	opts := workspace.CloneOptions{Env: config.cloneEnv(), Flat: cloneFlat}
+	if cloneInto != "" {
+		parent, err := cloneParent(cloneInto)
+		if err != nil {
+			return err
+		}
+		opts.Into = parent
+	}
+	if !cloneQuiet {
+		opts.Progress = os.Stderr
+	}
+	path, err := workspace.CloneWithOptions(basePath, args[0], opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(getDialect().CD(path))
+	return nil
+}
+
+// cloneParent resolves the workspace a clone goes inside: a path if arg
+// starts like one, else a query resolved as by 'go-try which'.
+func cloneParent(arg string) (string, error) {
+	if filepath.IsAbs(arg) || strings.HasPrefix(arg, ".") || strings.HasPrefix(arg, "~") {
+		return workspace.NormalizePath(arg), nil
+	}
+	ws, err := triesDir().Resolve(arg)
+	if err != nil {
+		return "", err
+	}
+	return ws.Path, nil
+}
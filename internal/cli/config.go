@@ -0,0 +1,238 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/tobi/try/internal/tui"
+	"github.com/tobi/try/internal/workspace"
+)
+
+// Config holds settings read from the config file.
+type Config struct {
+	// Keys maps TUI action names (delete, new, new_undated, group, search,
+	// clone, note, tags, kind, repos, hidden, refresh, more, base, remote,
+	// info, quit) to key strings such as "ctrl+x".
+	Keys map[string]string `toml:"keys"`
+
+	// TrashRetentionDays is how long deleted workspaces stay restorable
+	// (default 7).
+	TrashRetentionDays int `toml:"trash_retention_days"`
+
+	// Recency chooses what orders the list: "mtime" (default) or "git",
+	// which uses the latest commit of git repositories. "git" runs a git
+	// process per repository, so it is opt-in.
+	Recency string `toml:"recency"`
+
+	// CloneEnv sets environment variables for git clones run by try, such
+	// as GIT_SSH_COMMAND or HTTPS_PROXY.
+	CloneEnv map[string]string `toml:"clone_env"`
+
+	// MaxResults caps how many workspaces the picker lists at once
+	// (default 300); more load on demand. -1 lists everything.
+	MaxResults int `toml:"max_results"`
+
+	// UpdateCheck lets the selector ask GitHub for new releases at most
+	// once a day. Off by default; see 'go-try update-check'.
+	UpdateCheck bool `toml:"update_check"`
+
+	// DateFormat is the Go time layout of new workspaces' date prefix
+	// (default "2006-01-02"), such as "2006-01-02-1504" or "20060102".
+	DateFormat string `toml:"date_format"`
+
+	// CloneNameTemplate is a text/template for clone directory names,
+	// using .Host, .User, .Repo and .Date (default
+	// "{{.Date}}-{{.User}}-{{.Repo}}").
+	CloneNameTemplate string `toml:"clone_name_template"`
+
+	// TimeFormat chooses how last activity is shown in the selector and
+	// 'go-try list --long': "relative" (default), such as "3d ago", or
+	// "absolute", such as "2025-01-19 14:05".
+	TimeFormat string `toml:"time_format"`
+
+	// IncludeFiles lists regular files in the tries directory alongside
+	// directories, for tries that are a single script. Choosing one cds to
+	// the directory it is in.
+	IncludeFiles bool `toml:"include_files"`
+
+	// LowercaseNames lowercases the names of new workspaces and clones, so
+	// a clone of Repo.JS is named repo.js.
+	LowercaseNames bool `toml:"lowercase_names"`
+
+	// ExportVar names an environment variable, such as TRY_LAST, that the
+	// shell wrapper sets to the path of the workspace it cds to, for other
+	// tools to read. Empty (the default) sets none.
+	ExportVar string `toml:"export_var"`
+
+	// SizeMaxDepth limits how many directory levels below a workspace are
+	// walked to size it up for the delete bar and details panel (default
+	// 0, no limit). Sizes cut short are shown with a "+".
+	SizeMaxDepth int `toml:"size_max_depth"`
+
+	// SizeSkip names directories not walked when sizing up a workspace
+	// (default node_modules, .git and target). An empty list walks
+	// everything.
+	SizeSkip []string `toml:"size_skip"`
+
+	// unknown lists settings in the file that Config doesn't have, such as
+	// misspelled ones, as dotted keys.
+	unknown []string
+}
+
+// defaultMaxResults applies when the config doesn't set max_results.
+const defaultMaxResults = 300
+
+// maxResults returns the picker's result limit, or 0 for no limit.
+func (c Config) maxResults() int {
+	switch {
+	case c.MaxResults < 0:
+		return 0
+	case c.MaxResults == 0:
+		return defaultMaxResults
+	}
+	return c.MaxResults
+}
+
+// defaultTrashRetentionDays applies when the config doesn't set one.
+const defaultTrashRetentionDays = 7
+
+// trashRetention returns how long trashed workspaces are kept.
+func (c Config) trashRetention() time.Duration {
+	days := c.TrashRetentionDays
+	if days <= 0 {
+		days = defaultTrashRetentionDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// sizeSkip returns the directory names skipped when sizing workspaces.
+func (c Config) sizeSkip() []string {
+	if c.SizeSkip == nil {
+		return workspace.DefaultUsageSkip
+	}
+	return c.SizeSkip
+}
+
+// gitRecency reports whether the list is ordered by commit time.
+func (c Config) gitRecency() (bool, error) {
+	switch c.Recency {
+	case "", "mtime":
+		return false, nil
+	case "git":
+		return true, nil
+	}
+	return false, fmt.Errorf("unknown recency %q (valid: mtime, git)", c.Recency)
+}
+
+// timeFormat returns how times are shown, from --time-format if given,
+// else from the config.
+func (c Config) timeFormat() (workspace.TimeFormat, error) {
+	if timeFormatFlag != "" {
+		return workspace.ParseTimeFormat(timeFormatFlag)
+	}
+	return workspace.ParseTimeFormat(c.TimeFormat)
+}
+
+// Validate checks every setting in c and returns all the problems found,
+// joined with errors.Join, or nil if there are none.
+func (c Config) Validate() error {
+	var errs []error
+	for _, key := range c.unknown {
+		errs = append(errs, fmt.Errorf("unknown setting %q", key))
+	}
+	if _, err := tui.ParseKeyMap(c.Keys); err != nil {
+		errs = append(errs, fmt.Errorf("invalid [keys]: %w", err))
+	}
+	if c.TrashRetentionDays < 0 {
+		errs = append(errs, fmt.Errorf("trash_retention_days must not be negative, got %d", c.TrashRetentionDays))
+	}
+	if _, err := c.gitRecency(); err != nil {
+		errs = append(errs, err)
+	}
+	for name := range c.CloneEnv {
+		if name == "" || strings.ContainsAny(name, "= \t") {
+			errs = append(errs, fmt.Errorf("invalid [clone_env] variable name %q", name))
+		}
+	}
+	if c.MaxResults < -1 {
+		errs = append(errs, fmt.Errorf("max_results must be -1 (no limit) or more, got %d", c.MaxResults))
+	}
+	if c.ExportVar != "" && !envName.MatchString(c.ExportVar) {
+		errs = append(errs, fmt.Errorf("export_var %q is not a valid environment variable name", c.ExportVar))
+	}
+	if c.SizeMaxDepth < 0 {
+		errs = append(errs, fmt.Errorf("size_max_depth must not be negative, got %d", c.SizeMaxDepth))
+	}
+	for _, name := range c.SizeSkip {
+		if name == "" || strings.ContainsRune(name, '/') {
+			errs = append(errs, fmt.Errorf("invalid size_skip entry %q: want a directory name", name))
+		}
+	}
+	if c.DateFormat != "" {
+		if err := workspace.ValidateDateFormat(c.DateFormat); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if c.CloneNameTemplate != "" {
+		if err := workspace.ValidateCloneNameTemplate(c.CloneNameTemplate); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if _, err := workspace.ParseTimeFormat(c.TimeFormat); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// envName matches the environment variable names every shell can set.
+var envName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// cloneEnv returns CloneEnv as KEY=value pairs in a stable order.
+func (c Config) cloneEnv() []string {
+	env := make([]string, 0, len(c.CloneEnv))
+	for k, v := range c.CloneEnv {
+		env = append(env, k+"="+v)
+	}
+	sort.Strings(env)
+	return env
+}
+
+// config is the loaded config file, or the zero Config if there is none.
+var config Config
+
+// configPath returns the config file location: $TRY_CONFIG, or
+// try/config.toml in the user config directory.
+func configPath() string {
+	if p := os.Getenv("TRY_CONFIG"); p != "" {
+		return p
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "try", "config.toml")
+}
+
+// loadConfig reads the config file. A missing file is not an error.
+func loadConfig(path string) (Config, error) {
+	var cfg Config
+	md, err := toml.DecodeFile(path, &cfg)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Config{}, nil
+		}
+		return Config{}, fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+	for _, key := range md.Undecoded() {
+		cfg.unknown = append(cfg.unknown, key.String())
+	}
+	return cfg, nil
+}
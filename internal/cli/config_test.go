@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		toml string
+		want []string
+	}{
+		{"empty", "", nil},
+		{"valid", "recency = \"git\"\nmax_results = -1\ntime_format = \"absolute\"\n[keys]\ndelete = \"ctrl+x\"\n", nil},
+		{"typo", "recncy = \"git\"\n", []string{`unknown setting "recncy"`}},
+		{"bad keys", "[keys]\nteleport = \"ctrl+x\"\n", []string{"invalid [keys]", "unknown key action"}},
+		{"negatives", "trash_retention_days = -1\nmax_results = -5\n", []string{"trash_retention_days", "max_results"}},
+		{"size bounds", "size_max_depth = -1\nsize_skip = [\"node_modules\", \"a/b\"]\n", []string{"size_max_depth", `invalid size_skip entry "a/b"`}},
+		{"export var", "export_var = \"TRY-LAST\"\n", []string{`export_var "TRY-LAST"`}},
+		{"clone env", "[clone_env]\n\"GIT SSH\" = \"ssh\"\n", []string{"invalid [clone_env] variable name"}},
+		{
+			"several",
+			"recency = \"atime\"\ndate_format = \"2006/01/02\"\nclone_name_template = \"{{.Owner}}\"\ntime_format = \"iso\"\n",
+			[]string{"unknown recency", "invalid date format", "invalid clone name template", "unknown time format"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.toml")
+			if err := os.WriteFile(path, []byte(tt.toml), 0644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := loadConfig(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = cfg.Validate()
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate() = nil, want errors containing %q", tt.want)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Validate() = %q, should mention %q", err, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/cobra"
+	"github.com/tobi/try/internal/tui"
+	"github.com/tobi/try/internal/workspace"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "View and change settings in the config file",
+	Long: `Read and write settings in the config file without editing TOML by hand.
+
+Keys are the top-level settings (max_results, recency, ...) or a table
+entry written as table.name:
+
+  go-try config get max_results
+  go-try config set recency git
+  go-try config set keys.delete ctrl+x
+  go-try config set clone_env.GIT_SSH_COMMAND "ssh -i ~/.ssh/work_key"
+
+'set' keeps the other settings but rewrites the file, so comments in it
+are lost.`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print a setting, or its default if unset",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		value, err := configGet(configPath(), args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Println(value)
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Change a setting in the config file",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return configSet(configPath(), args[0], args[1])
+	},
+}
+
+var configPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Print the config file location",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println(configPath())
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configGetCmd, configSetCmd, configPathCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+// configSetting describes a top-level setting of Config.
+type configSetting struct {
+	kind string // "int", "bool", "string" or "list", a comma-separated []string
+	def  string // printed by get when the setting is unset
+}
+
+// configSettings lists the top-level settings, by TOML key.
+var configSettings = map[string]configSetting{
+	"trash_retention_days": {"int", strconv.Itoa(defaultTrashRetentionDays)},
+	"recency":              {"string", "mtime"},
+	"max_results":          {"int", strconv.Itoa(defaultMaxResults)},
+	"update_check":         {"bool", "false"},
+	"date_format":          {"string", workspace.DefaultDateFormat},
+	"clone_name_template":  {"string", workspace.DefaultCloneNameTemplate},
+	"time_format":          {"string", string(workspace.TimeRelative)},
+	"include_files":        {"bool", "false"},
+	"lowercase_names":      {"bool", "false"},
+	"export_var":           {"string", ""},
+	"size_max_depth":       {"int", "0"},
+	"size_skip":            {"list", strings.Join(workspace.DefaultUsageSkip, ",")},
+}
+
+// configTables are the tables of Config, whose entries are set as
+// table.name.
+var configTables = []string{"keys", "clone_env"}
+
+// parseConfigKey splits key into a table and an entry name, or a setting
+// and "" for top-level settings. Unknown keys are an error.
+func parseConfigKey(key string) (string, string, error) {
+	if table, name, ok := strings.Cut(key, "."); ok {
+		for _, t := range configTables {
+			if t == table && name != "" {
+				return table, name, nil
+			}
+		}
+	} else if _, ok := configSettings[key]; ok {
+		return key, "", nil
+	}
+	return "", "", fmt.Errorf("unknown config key %q (valid: %s)", key, strings.Join(configKeyNames(), ", "))
+}
+
+// configKeyNames returns the valid keys for error messages.
+func configKeyNames() []string {
+	var names []string
+	for name := range configSettings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, t := range configTables {
+		names = append(names, t+".<name>")
+	}
+	return names
+}
+
+// readRawConfig reads the config file as plain TOML, so settings try
+// doesn't know survive a rewrite. A missing file is empty.
+func readRawConfig(path string) (map[string]any, error) {
+	raw := map[string]any{}
+	if _, err := toml.DecodeFile(path, &raw); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+	return raw, nil
+}
+
+// configGet returns the value of key in the config file at path, or its
+// default if it's unset.
+func configGet(path, key string) (string, error) {
+	table, name, err := parseConfigKey(key)
+	if err != nil {
+		return "", err
+	}
+	raw, err := readRawConfig(path)
+	if err != nil {
+		return "", err
+	}
+
+	if name == "" {
+		if v, ok := raw[table]; ok {
+			if list, ok := v.([]any); ok {
+				items := make([]string, len(list))
+				for i, item := range list {
+					items[i] = fmt.Sprint(item)
+				}
+				return strings.Join(items, ","), nil
+			}
+			return fmt.Sprint(v), nil
+		}
+		return configSettings[table].def, nil
+	}
+
+	if entries, ok := raw[table].(map[string]any); ok {
+		if v, ok := entries[name]; ok {
+			return fmt.Sprint(v), nil
+		}
+	}
+	if table == "keys" {
+		if keys, ok := tui.DefaultKeyMap().ActionKeys(name); ok {
+			return strings.Join(keys, ","), nil
+		}
+	}
+	return "", fmt.Errorf("%s is not set", key)
+}
+
+// configSet sets key to value in the config file at path, creating it if
+// needed. The result must be a valid config, or nothing is written.
+func configSet(path, key, value string) error {
+	table, name, err := parseConfigKey(key)
+	if err != nil {
+		return err
+	}
+	raw, err := readRawConfig(path)
+	if err != nil {
+		return err
+	}
+
+	if name == "" {
+		v, err := parseConfigValue(configSettings[table].kind, value)
+		if err != nil {
+			return fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+		raw[table] = v
+	} else {
+		entries, ok := raw[table].(map[string]any)
+		if !ok {
+			entries = map[string]any{}
+			raw[table] = entries
+		}
+		entries[name] = value
+	}
+
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(raw); err != nil {
+		return err
+	}
+
+	// Check the new file the way try will read it
+	var cfg Config
+	if _, err := toml.Decode(buf.String(), &cfg); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// parseConfigValue converts value to the TOML type of kind.
+func parseConfigValue(kind, value string) (any, error) {
+	switch kind {
+	case "int":
+		return strconv.Atoi(value)
+	case "bool":
+		return strconv.ParseBool(value)
+	case "list":
+		items := []string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items, nil
+	}
+	return value, nil
+}
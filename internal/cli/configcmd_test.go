@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigSetGet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "try", "config.toml")
+
+	// Defaults come back before anything is set
+	for key, want := range map[string]string{
+		"max_results": "300",
+		"recency":     "mtime",
+		"keys.delete": "ctrl+d",
+		"size_skip":   "node_modules,.git,target",
+	} {
+		if got, err := configGet(path, key); err != nil || got != want {
+			t.Errorf("get %s = %q, %v; want %q", key, got, err, want)
+		}
+	}
+
+	os.MkdirAll(filepath.Dir(path), 0755)
+	os.WriteFile(path, []byte("recency = \"git\"\nsomething_else = 1\n\n[keys]\nquit = \"esc,ctrl+q\"\n"), 0644)
+
+	sets := [][2]string{
+		{"max_results", "50"},
+		{"update_check", "true"},
+		{"keys.delete", "ctrl+x"},
+		{"clone_env.GIT_SSH_COMMAND", "ssh -i key"},
+		{"size_skip", "node_modules, vendor"},
+	}
+	for _, kv := range sets {
+		if err := configSet(path, kv[0], kv[1]); err != nil {
+			t.Fatalf("set %s: %v", kv[0], err)
+		}
+	}
+
+	for key, want := range map[string]string{
+		"max_results":               "50",
+		"update_check":              "true",
+		"recency":                   "git",
+		"keys.delete":               "ctrl+x",
+		"keys.quit":                 "esc,ctrl+q",
+		"clone_env.GIT_SSH_COMMAND": "ssh -i key",
+		"size_skip":                 "node_modules,vendor",
+	} {
+		if got, err := configGet(path, key); err != nil || got != want {
+			t.Errorf("get %s = %q, %v; want %q", key, got, err, want)
+		}
+	}
+
+	// The file still loads, with its unknown keys kept
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.MaxResults != 50 || !cfg.UpdateCheck || cfg.Keys["delete"] != "ctrl+x" {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "something_else") {
+		t.Errorf("unknown keys should be kept:\n%s", data)
+	}
+}
+
+func TestConfigSetInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+
+	tests := []struct {
+		key, value, want string
+	}{
+		{"colour", "red", "unknown config key"},
+		{"keys", "ctrl+x", "unknown config key"},
+		{"max_results", "lots", "invalid value for max_results"},
+		{"recency", "atime", "unknown recency"},
+		{"keys.teleport", "ctrl+x", "unknown key action"},
+		{"keys.delete", "ctrl+n", "bound to both"},
+		{"date_format", "2006/01/02", "invalid date format"},
+	}
+	for _, tt := range tests {
+		err := configSet(path, tt.key, tt.value)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("set %s %s: got %v, want error containing %q", tt.key, tt.value, err, tt.want)
+		}
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("invalid settings should not be written")
+	}
+}
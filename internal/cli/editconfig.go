@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var editConfigEditor string
+
+var editConfigCmd = &cobra.Command{
+	Use:   "edit-config",
+	Short: "Open the config file in your editor",
+	Long: `Open the config file in an editor, creating it from a commented
+template first if it doesn't exist. When the editor exits, the config is
+checked and any problems in it are reported.
+
+The editor is --editor if given, else $VISUAL, else $EDITOR, else vi. It
+may include arguments, such as "code --wait".`,
+	Args: cobra.NoArgs,
+	RunE: runEditConfig,
+}
+
+func init() {
+	editConfigCmd.Flags().StringVar(&editConfigEditor, "editor", "", "editor to open the config in (default: $VISUAL, else $EDITOR, else vi)")
+	rootCmd.AddCommand(editConfigCmd)
+}
+
+func runEditConfig(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	path := configPath()
+	if err := ensureConfigFile(path); err != nil {
+		return err
+	}
+
+	argv := editorCommand(editConfigEditor)
+	editor := exec.Command(argv[0], append(argv[1:], path)...)
+	editor.Stdin, editor.Stdout, editor.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := editor.Run(); err != nil {
+		return fmt.Errorf("editor %s failed: %w", argv[0], err)
+	}
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("problems in config %s:\n  %s", path, strings.ReplaceAll(err.Error(), "\n", "\n  "))
+	}
+	return nil
+}
+
+// editorCommand splits the editor to run into a program and its
+// arguments: flag if set, else $VISUAL, else $EDITOR, else vi.
+func editorCommand(flag string) []string {
+	for _, editor := range []string{flag, os.Getenv("VISUAL"), os.Getenv("EDITOR")} {
+		if argv := strings.Fields(editor); len(argv) > 0 {
+			return argv
+		}
+	}
+	return []string{"vi"}
+}
+
+// configTemplate is written by edit-config when there is no config file.
+// Every setting is commented out, so it behaves like no file at all.
+const configTemplate = `# try config; see 'go-try config' and the README for details.
+
+# How long deleted workspaces stay restorable, in days.
+# trash_retention_days = 7
+
+# What orders the list: "mtime", or "git" for the latest commit.
+# recency = "mtime"
+
+# Entries listed at once; -1 for all.
+# max_results = 300
+
+# Check GitHub for new releases once a day.
+# update_check = false
+
+# Date prefix of new workspaces, as a Go time layout.
+# date_format = "2006-01-02"
+
+# Directory names for clones: .Host, .User, .Repo and .Date.
+# clone_name_template = "{{.Date}}-{{.User}}-{{.Repo}}"
+
+# How last activity is shown: "relative" or "absolute".
+# time_format = "relative"
+
+# List single-file tries alongside directories.
+# include_files = false
+
+# Lowercase the names of new workspaces and clones.
+# lowercase_names = false
+
+# Environment variable to set to the path of the workspace you land in.
+# export_var = "TRY_LAST"
+
+# Bounds on sizing up workspaces: levels walked (0 for no limit) and
+# directory names skipped.
+# size_max_depth = 0
+# size_skip = ["node_modules", ".git", "target"]
+
+# Key bindings by action: delete, new, new_undated, group, search, clone,
+# note, tags, kind, repos, hidden, refresh, more, base, remote, info, quit.
+[keys]
+# delete = "ctrl+x"
+# quit = "esc,ctrl+q"
+
+# Environment variables for git clones.
+[clone_env]
+# GIT_SSH_COMMAND = "ssh -i ~/.ssh/work_key"
+`
+
+// ensureConfigFile writes configTemplate to path unless a file is already
+// there.
+func ensureConfigFile(path string) error {
+	if _, err := os.Stat(path); err == nil || !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(configTemplate), 0644)
+}
@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestEnsureConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "try", "config.toml")
+	if err := ensureConfigFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// The template is valid and sets nothing
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("template has problems: %v", err)
+	}
+	// Its tables are there, but empty
+	cfg.Keys, cfg.CloneEnv = nil, nil
+	if !reflect.DeepEqual(cfg, Config{}) {
+		t.Errorf("template should leave every setting at its default, got %+v", cfg)
+	}
+
+	// An existing file is left alone
+	os.WriteFile(path, []byte("recency = \"git\"\n"), 0644)
+	if err := ensureConfigFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "recency = \"git\"\n" {
+		t.Errorf("existing config was overwritten:\n%s", data)
+	}
+}
+
+func TestEditorCommand(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
+	if got := editorCommand(""); !reflect.DeepEqual(got, []string{"vi"}) {
+		t.Errorf("default editor = %q, want vi", got)
+	}
+
+	t.Setenv("EDITOR", "nano")
+	t.Setenv("VISUAL", "code --wait")
+	if got := editorCommand(""); !reflect.DeepEqual(got, []string{"code", "--wait"}) {
+		t.Errorf("editor = %q, want $VISUAL", got)
+	}
+	if got := editorCommand("hx"); !reflect.DeepEqual(got, []string{"hx"}) {
+		t.Errorf("editor = %q, want --editor", got)
+	}
+}
@@ -1,32 +1,86 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/muesli/termenv"
 	"github.com/spf13/cobra"
 	"github.com/tobi/try/internal/shell"
+	"github.com/tobi/try/internal/theme"
 	"github.com/tobi/try/internal/tui"
 	"github.com/tobi/try/internal/workspace"
 )
 
 var execCmd = &cobra.Command{
-	Use:   "exec [query]",
+	Use:   "exec [query...]",
 	Short: "Run selector and output shell script",
 	Long: `Run the interactive selector and output a shell script to stdout.
 
 This command is typically called via the shell wrapper function created by 'try init'.
 The output is meant to be eval'd by the shell.
 
-If a git URL is provided instead of a query, it will clone the repository.`,
-	Args: cobra.MaximumNArgs(1),
+A query of several words is joined with hyphens, so 'try my cool idea'
+filters for, or creates, my-cool-idea. Words that don't fit the
+subcommand they start with are a query too, so 'try run fast' filters
+for run-fast; to search for words a subcommand would take, such as
+'new york trip', put -- first: 'try -- new york trip'.
+
+If a git URL is provided instead of a query, it will clone the repository.
+--clone-into clones it inside an existing workspace instead, named by a
+query or path, and cds there.
+Only complete URLs (git@host:user/repo, https://host/user/repo) count;
+anything else is a query. --query and --clone force either reading, e.g.
+to filter for github.com-notes.
+
+With --protocol v1, the output is one action per line as tab-separated
+fields (CD, MKDIR, TOUCH, ECHO, CLONE, RM, EXPORT) for the shell wrapper
+to parse instead of eval'ing raw shell code.
+
+With --print-path, choosing or creating a directory prints just its
+absolute path, for use without the shell wrapper:
+
+  cd "$(go-try exec --print-path)"
+
+With --select first, the top entry is chosen without opening the picker:
+the most recent workspace, or the only one matching a query. A query
+that matches several (or none) still opens the picker.
+
+  try --select first         # back to whatever you were doing`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return validateProtocol()
+	},
 	RunE: runExec,
 }
 
+var (
+	execQuery  string
+	execClone  bool
+	execSticky bool
+	execDryRun bool
+	execSelect string
+)
+
 func init() {
+	execCmd.Flags().StringVar(&execQuery, "query", "", "filter by this text, even if it looks like a URL")
+	execCmd.Flags().BoolVar(&execClone, "clone", false, "treat the argument as a git URL to clone")
+	execCmd.Flags().BoolVar(&cloneFlat, "flat", false, "name a clone user-repo, without the date prefix")
+	execCmd.Flags().StringVar(&cloneInto, "clone-into", "", "clone inside this existing workspace")
+	execCmd.MarkFlagsMutuallyExclusive("flat", "clone-into")
+	execCmd.Flags().BoolVar(&execSticky, "sticky", false, "stay open after deleting, to delete several workspaces")
+	execCmd.Flags().BoolVar(&execDryRun, "dry-run", false, "make deleting only show how much space it would free")
+	execCmd.Flags().StringVar(&execSelect, "select", "", "choose without the picker when unambiguous (first)")
+	execCmd.Flags().BoolVarP(&createQuiet, "quiet", "q", false, "don't say when a new workspace's name was taken")
+	execCmd.PersistentFlags().StringVar(&protocol, "protocol", "",
+		"emit structured protocol lines instead of shell code (v1)")
+	execCmd.PersistentFlags().BoolVar(&printPath, "print-path", false,
+		"print only the chosen directory's path instead of a script")
 	rootCmd.AddCommand(execCmd)
 }
 
@@ -38,43 +92,161 @@ func runExec(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to create tries directory: %w", err)
 	}
 
-	// Check if arg is a git URL
-	if len(args) > 0 && workspace.IsGitURL(args[0]) {
-		return handleClone(basePath, args[0])
+	purgeTrash(basePath)
+	noteWrapperVersion(os.Stderr)
+	// Clones pended by an earlier run have finished or failed by now
+	_ = workspace.ClaimPendingMeta(basePath)
+
+	err := dispatchExec(basePath, args)
+	if errors.Is(err, ErrCancelled) {
+		// Nothing for the shell to do, but no failure to report either
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+		fmt.Fprintln(os.Stderr, "Cancelled.")
+	}
+	return err
+}
+
+// queryFallback makes the exec subcommand cmd take arguments its Args
+// reject as a query instead, named by the subcommand and the words after
+// it, as if it weren't a subcommand at all.
+func queryFallback(cmd *cobra.Command) {
+	validate, run := cmd.Args, cmd.RunE
+	cmd.Args = cobra.ArbitraryArgs
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if validate(cmd, args) == nil {
+			return run(cmd, args)
+		}
+		return runExec(cmd, append([]string{cmd.Name()}, args...))
 	}
+}
+
+// dispatchExec picks between cloning and the selector for exec's flags and
+// arguments.
+func dispatchExec(basePath string, args []string) error {
+	switch {
+	case execSelect != "" && execSelect != "first":
+		return fmt.Errorf("unknown --select %q (valid: first)", execSelect)
+
+	case execQuery != "" && (execClone || cloneInto != "" || len(args) > 0):
+		return fmt.Errorf("--query can't be combined with --clone, --clone-into or an argument")
+
+	case execQuery != "":
+		return runSelector(basePath, execQuery)
 
-	// Run interactive selector
-	query := ""
-	if len(args) > 0 {
-		query = args[0]
+	case cloneInto != "":
+		if len(args) != 1 {
+			return fmt.Errorf("--clone-into needs one git URL")
+		}
+		return handleClone(basePath, args[0])
+
+	case execClone:
+		if len(args) != 1 {
+			return fmt.Errorf("--clone needs one git URL")
+		}
+		return handleClone(basePath, args[0])
+
+	case len(args) > 0 && workspace.IsCloneURL(args[0]):
+		if len(args) > 1 {
+			return fmt.Errorf("a git URL can't be followed by other arguments")
+		}
+		return handleClone(basePath, args[0])
+
+	case len(args) > 0:
+		// Words typed without quotes name one workspace, as spaces do
+		// in the picker
+		return runSelector(basePath, strings.Join(args, "-"))
 	}
 
-	return runSelector(basePath, query)
+	return runSelector(basePath, "")
 }
 
 func runSelector(basePath, query string) error {
-	// Create TUI model
-	opts := []tui.Option{
-		tui.WithTheme(getTheme()),
+	keys, err := tui.ParseKeyMap(config.Keys)
+	if err != nil {
+		return fmt.Errorf("invalid [keys] in %s: %w", configPath(), err)
 	}
-	if query != "" {
-		opts = append(opts, tui.WithInitialQuery(query))
+	gitRecency, err := config.gitRecency()
+	if err != nil {
+		return fmt.Errorf("invalid config %s: %w", configPath(), err)
+	}
+	timeFormat, err := config.timeFormat()
+	if err != nil {
+		return err
 	}
 
-	m := tui.New(basePath, opts...)
+	// Tests drive the pipeline without a terminal
+	if spec := os.Getenv(testActionEnv); spec != "" {
+		action, err := headlessAction(basePath, query, spec, gitRecency)
+		if err != nil {
+			return err
+		}
+		return outputScript(action, basePath)
+	}
+
+	if execSelect == "first" {
+		action, err := selectFirst(basePath, query, gitRecency)
+		if err != nil {
+			return err
+		}
+		if action != nil {
+			return outputScript(action, basePath)
+		}
+	}
 
-	// Run Bubble Tea program
 	// Open /dev/tty directly for TUI rendering to ensure it works
 	// even when stdout is captured by the shell wrapper
 	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
 	if err != nil {
-		return fmt.Errorf("failed to open /dev/tty: %w", err)
+		tty = nil
+	} else {
+		defer tty.Close()
+	}
+
+	// Without a terminal Bubble Tea can drive, ask on stderr and stdin
+	if dumbTerminal(tty) {
+		action, err := plainSelect(basePath, query, gitRecency, os.Stdin, os.Stderr)
+		if err != nil {
+			return err
+		}
+		return outputScript(action, basePath)
+	}
+
+	// Runs while the picker is open; its notice, if any, is printed after
+	notifyUpdate := startUpdateCheck()
+
+	t := getTheme()
+	if themeName == "auto" && !noColors {
+		t = autoTheme(tty)
+	}
+
+	// Create TUI model
+	opts := []tui.Option{
+		tui.WithTheme(t),
+		tui.WithKeyMap(keys),
+		tui.WithCache(useCache),
+		tui.WithScanDepth(scanDepth),
+		tui.WithIncludeFiles(config.IncludeFiles),
+		tui.WithGrouping(groupDates),
+		tui.WithNoColor(noColors),
+		tui.WithGitRecency(gitRecency),
+		tui.WithMaxResults(config.maxResults()),
+		tui.WithSticky(execSticky),
+		tui.WithDryRun(execDryRun),
+		tui.WithFlatClones(cloneFlat),
+		tui.WithTimeFormat(timeFormat),
+		tui.WithUsageBounds(config.SizeMaxDepth, config.sizeSkip()),
+	}
+	if query != "" {
+		opts = append(opts, tui.WithInitialQuery(query))
 	}
-	defer tty.Close()
 
-	// Force lipgloss to use colors since stdout may not be a TTY
-	// when run through the shell wrapper (stdout is captured)
-	lipgloss.DefaultRenderer().SetColorProfile(termenv.TrueColor)
+	m := tui.New(basePath, opts...)
+
+	// Run Bubble Tea program
+	// Detect colors from the TTY we render to, not stdout, which is
+	// captured by the shell wrapper
+	lipgloss.DefaultRenderer().SetColorProfile(colorProfile(tty))
 
 	p := tea.NewProgram(m,
 		tea.WithAltScreen(),
@@ -90,8 +262,7 @@ func runSelector(basePath, query string) error {
 	// Get the action from the final model
 	model, ok := finalModel.(*tui.Model)
 	if !ok || model == nil {
-		fmt.Fprintln(os.Stderr, "Cancelled.")
-		os.Exit(1)
+		return ErrCancelled
 	}
 	if model.GetError() != nil {
 		return model.GetError()
@@ -99,56 +270,210 @@ func runSelector(basePath, query string) error {
 
 	action := model.GetAction()
 	if action == nil {
-		fmt.Fprintln(os.Stderr, "Cancelled.")
-		os.Exit(1)
+		return ErrCancelled
 	}
 
 	// Output the appropriate shell script
-	return outputScript(action, basePath)
+	if err := outputScript(action, basePath); err != nil {
+		return err
+	}
+	notifyUpdate(os.Stderr)
+	return nil
+}
+
+// colorProfile returns the color profile to render the TUI with.
+// --no-colors and NO_COLOR force plain text; otherwise the profile is
+// detected from the terminal, falling back to TrueColor only when there is
+// no terminal to inspect.
+func colorProfile(tty io.Writer) termenv.Profile {
+	if noColors {
+		return termenv.Ascii
+	}
+	if tty == nil {
+		return termenv.TrueColor
+	}
+	return termenv.NewOutput(tty).Profile
+}
+
+// autoTheme picks the light or the default (dark) theme to match the
+// terminal's background. The query runs before the TUI takes over the
+// terminal; terminals that don't answer it (termenv gives up after its OSC
+// timeout) or aren't terminals at all are treated as dark.
+func autoTheme(tty *os.File) theme.Theme {
+	if termenv.NewOutput(tty).HasDarkBackground() {
+		return theme.Default
+	}
+	return theme.Light
 }
 
+// ErrCancelled is returned when the user leaves the selector without
+// choosing anything. Execute returns it like any error, so try still exits
+// with status 1 and the shell wrapper doesn't act.
+var ErrCancelled = errors.New("cancelled")
+
+// outputScript prints the shell script for action.
 func outputScript(action *tui.Action, basePath string) error {
-	var script string
+	script, err := scriptFor(action, basePath, os.Stderr)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(script)
+	return nil
+}
+
+// selectFirst returns the action for --select first: cd into the top
+// entry for query, if that is unambiguous. Without a query that is the
+// most recent workspace; with one it must be the only match. Otherwise
+// it returns nil and the picker decides.
+func selectFirst(basePath, query string, gitRecency bool) (*tui.Action, error) {
+	entries, err := selectorEntries(basePath, query, gitRecency)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 || (query != "" && len(entries) > 1) || entries[0].Broken {
+		return nil, nil
+	}
+	return &tui.Action{Type: tui.ActionCD, Path: entries[0].Path, BaseDir: basePath}, nil
+}
+
+// scriptFor carries out the filesystem side of action (creating or
+// trashing directories) and returns the script for the shell to run,
+// writing notes for the user to stderr. Actions that don't choose anything
+// give ErrCancelled.
+func scriptFor(action *tui.Action, basePath string, stderr io.Writer) (string, error) {
+	dialect := getDialect()
 
 	switch action.Type {
 	case tui.ActionCD:
+		// The touch below changes the entry's mtime without touching the
+		// tries dir, so the cached ordering would go stale
+		if useCache {
+			_ = workspace.InvalidateCache(basePath)
+		}
+		// touch would create a broken link's target rather than fail
+		info, err := os.Stat(action.Path)
+		if _, lerr := os.Lstat(action.Path); err != nil && lerr == nil {
+			return "", fmt.Errorf("%s is a broken symlink", action.Path)
+		}
+		// A file try is entered by going to the directory it is in
+		if err == nil && !info.IsDir() {
+			if printPath {
+				_ = workspace.Touch(action.Path)
+				return filepath.Dir(action.Path) + "\n", nil
+			}
+			return dialect.CDFile(action.Path), nil
+		}
+		if printPath {
+			// No shell to run the touch, so do it here
+			_ = workspace.Touch(action.Path)
+			return action.Path + "\n", nil
+		}
 		// Touch to update mtime, then cd
-		script = shell.CD(action.Path)
+		return dialect.CD(action.Path), nil
 
 	case tui.ActionCreate:
-		// Create new directory with date prefix
-		path, err := workspace.Create(basePath, action.Path)
+		// Create new directory, date-prefixed unless asked otherwise
+		create := workspace.Create
+		if action.NoDate {
+			create = workspace.CreateRaw
+		}
+		path, err := create(basePath, action.Path)
 		if err != nil {
-			return fmt.Errorf("failed to create directory: %w", err)
+			return "", fmt.Errorf("failed to create directory: %w", err)
+		}
+		noteRenamed(stderr, action.Path, !action.NoDate, path)
+		if printPath {
+			return path + "\n", nil
 		}
-		script = shell.MkdirCD(path)
+		return dialect.MkdirCD(path), nil
 
 	case tui.ActionClone:
-		script = shell.Clone(action.Path, action.URL)
+		// A missing record only loses the clone's kind
+		_ = workspace.PendClone(basePath, action.Path, action.URL)
+		return dialect.Clone(action.Path, action.URL), nil
+
+	case tui.ActionCDBase:
+		// No touch: bumping the tries dir's mtime would only invalidate
+		// the scan cache
+		if printPath {
+			return basePath + "\n", nil
+		}
+		return shell.NewFor(dialect).AddCD(basePath).String(), nil
 
 	case tui.ActionDelete:
-		script = shell.Delete(action.Paths, basePath)
+		// Checked first: the current directory may be about to go
+		inDeleted := cwdInside(action.Paths)
 
-	case tui.ActionCancel:
-		fmt.Fprintln(os.Stderr, "Cancelled.")
-		os.Exit(1)
+		// Move to the trash rather than rm -rf so 'try undo' can bring it back
+		for _, p := range action.Paths {
+			if _, err := workspace.Trash(basePath, p); err != nil {
+				return "", fmt.Errorf("failed to delete %s: %w", p, err)
+			}
+			fmt.Fprintf(stderr, "Moved %s to trash (restore with 'try undo')\n", filepath.Base(p))
+		}
+		// Only a shell left inside a deleted workspace is moved, to the
+		// base dir; anywhere else it stays put
+		if !inDeleted {
+			return "", nil
+		}
+		return shell.NewFor(dialect).AddCD(basePath).String(), nil
+	}
 
-	default:
-		fmt.Fprintln(os.Stderr, "Cancelled.")
-		os.Exit(1)
+	return "", ErrCancelled
+}
+
+// cwdInside reports whether the current directory, which is the calling
+// shell's, is one of paths or inside one. Both the paths as given and
+// with symlinks resolved are compared, since either may be how the shell
+// got there.
+func cwdInside(paths []string) bool {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return false
+	}
+	realCwd, _ := filepath.EvalSymlinks(cwd)
+	for _, p := range paths {
+		if within(p, cwd) {
+			return true
+		}
+		if realP, err := filepath.EvalSymlinks(p); err == nil && realCwd != "" && within(realP, realCwd) {
+			return true
+		}
 	}
+	return false
+}
 
-	fmt.Print(script)
-	return nil
+// within reports whether path is dir or inside it.
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
 }
 
 func handleClone(basePath, url string) error {
-	path, cloneURL, err := workspace.CloneScript(basePath, url)
+	if cloneInto != "" {
+		parent, err := cloneParent(cloneInto)
+		if err != nil {
+			return err
+		}
+		path, cloneURL, err := workspace.CloneIntoScript(basePath, parent, url)
+		if err != nil {
+			return err
+		}
+		fmt.Print(getDialect().Clone(path, cloneURL))
+		return nil
+	}
+
+	path, cloneURL, err := workspace.CloneScript(basePath, url, cloneFlat)
 	if err != nil {
 		return fmt.Errorf("failed to parse git URL: %w", err)
 	}
 
-	script := shell.Clone(path, cloneURL)
+	_ = workspace.PendClone(basePath, path, cloneURL)
+	script := getDialect().Clone(path, cloneURL)
 	fmt.Print(script)
 	return nil
 }
@@ -0,0 +1,323 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tobi/try/internal/shell"
+	"github.com/tobi/try/internal/tui"
+	"github.com/tobi/try/internal/workspace"
+)
+
+// withFlags sets the exec flags scriptFor reads for the rest of the test.
+func withFlags(t *testing.T, shellFlag, protocolFlag string, printPathFlag bool) {
+	oldShell, oldProtocol, oldPrintPath := shellName, protocol, printPath
+	shellName, protocol, printPath = shellFlag, protocolFlag, printPathFlag
+	t.Cleanup(func() { shellName, protocol, printPath = oldShell, oldProtocol, oldPrintPath })
+}
+
+func TestScriptFor(t *testing.T) {
+	base := t.TempDir()
+	existing := filepath.Join(base, "2025-01-19-redis")
+	os.Mkdir(existing, 0755)
+	script := filepath.Join(base, "scrape.py")
+	os.WriteFile(script, nil, 0644)
+	dated := filepath.Join(base, workspace.DatePrefix()+"-demo")
+
+	tests := []struct {
+		name   string
+		shell  string
+		action tui.Action
+		want   string
+	}{
+		{"cd", "", tui.Action{Type: tui.ActionCD, Path: existing}, shell.CD(existing)},
+		{"cd elvish", "elvish", tui.Action{Type: tui.ActionCD, Path: existing}, shell.Elvish.CD(existing)},
+		{"cd file", "", tui.Action{Type: tui.ActionCD, Path: script}, shell.POSIX.CDFile(script)},
+		{"create", "", tui.Action{Type: tui.ActionCreate, Path: "demo"}, shell.MkdirCD(dated)},
+		{"create undated", "tcsh", tui.Action{Type: tui.ActionCreate, Path: "dotfiles", NoDate: true},
+			shell.Csh.MkdirCD(filepath.Join(base, "dotfiles"))},
+		{"clone", "", tui.Action{Type: tui.ActionClone, Path: "/tries/2025-01-19-user-repo", URL: "git@github.com:user/repo.git"},
+			shell.Clone("/tries/2025-01-19-user-repo", "git@github.com:user/repo.git")},
+		{"base", "fish", tui.Action{Type: tui.ActionCDBase, Path: base}, shell.New().AddCD(base).String()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withFlags(t, tt.shell, "", false)
+			got, err := scriptFor(&tt.action, base, &bytes.Buffer{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := os.Stat(dated); err != nil {
+		t.Errorf("create should make the directory: %v", err)
+	}
+}
+
+func TestHeadlessCloneFlat(t *testing.T) {
+	base := t.TempDir()
+	t.Cleanup(func() { cloneFlat = false })
+
+	for flat, want := range map[bool]string{
+		false: workspace.DatePrefix() + "-user-repo",
+		true:  "user-repo",
+	} {
+		cloneFlat = flat
+		a, err := headlessAction(base, "", "clone:git@github.com:user/repo.git", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a.Path != base+"/"+want {
+			t.Errorf("flat=%v: clone into %s, want %s", flat, a.Path, want)
+		}
+	}
+}
+
+func TestScriptForCreateTaken(t *testing.T) {
+	base := t.TempDir()
+	os.Mkdir(filepath.Join(base, "project"), 0755)
+	withFlags(t, "", "", false)
+	action := &tui.Action{Type: tui.ActionCreate, Path: "project", NoDate: true}
+
+	var stderr bytes.Buffer
+	if _, err := scriptFor(action, base, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	if want := "a similar workspace 'project' already exists; created 'project-2'\n"; stderr.String() != want {
+		t.Errorf("stderr = %q, want %q", stderr.String(), want)
+	}
+
+	createQuiet = true
+	t.Cleanup(func() { createQuiet = false })
+	stderr.Reset()
+	if _, err := scriptFor(action, base, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("--quiet should leave out the note, got %q", stderr.String())
+	}
+}
+
+func TestScriptForBrokenLink(t *testing.T) {
+	base := t.TempDir()
+	link := filepath.Join(base, "dangling")
+	os.Symlink(filepath.Join(base, "missing"), link)
+	withFlags(t, "", "", false)
+
+	_, err := scriptFor(&tui.Action{Type: tui.ActionCD, Path: link}, base, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "broken symlink") {
+		t.Errorf("cd into a broken link: got %v, want broken symlink error", err)
+	}
+	if _, err := os.Stat(filepath.Join(base, "missing")); !os.IsNotExist(err) {
+		t.Error("the link's target should not be created")
+	}
+}
+
+func TestScriptForDelete(t *testing.T) {
+	withFlags(t, "", "v1", false)
+	base := t.TempDir()
+	target := filepath.Join(base, "2025-01-19-old")
+	os.MkdirAll(filepath.Join(target, "src"), 0755)
+	chdir(t, filepath.Join(target, "src"))
+
+	var stderr bytes.Buffer
+	got, err := scriptFor(&tui.Action{Type: tui.ActionDelete, Paths: []string{target}}, base, &stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := shell.NewFor(shell.ProtocolV1).AddCD(base).String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Error("deleted workspace should be moved away")
+	}
+	if !strings.Contains(stderr.String(), "Moved 2025-01-19-old to trash") {
+		t.Errorf("stderr = %q", stderr.String())
+	}
+}
+
+func TestScriptForDeleteElsewhere(t *testing.T) {
+	withFlags(t, "", "", false)
+	base := t.TempDir()
+	target := filepath.Join(base, "2025-01-19-old")
+	os.Mkdir(target, 0755)
+	os.Mkdir(filepath.Join(base, "2025-01-20-new"), 0755)
+	chdir(t, filepath.Join(base, "2025-01-20-new"))
+
+	// A shell somewhere else stays where it is
+	got, err := scriptFor(&tui.Action{Type: tui.ActionDelete, Paths: []string{target}}, base, &bytes.Buffer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "" {
+		t.Errorf("got %q, want no script", got)
+	}
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Error("deleted workspace should be moved away")
+	}
+}
+
+// chdir changes to dir for the rest of the test.
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func TestScriptForPrintPath(t *testing.T) {
+	withFlags(t, "", "", true)
+	base := t.TempDir()
+
+	got, err := scriptFor(&tui.Action{Type: tui.ActionCreate, Path: "x", NoDate: true}, base, &bytes.Buffer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != filepath.Join(base, "x")+"\n" {
+		t.Errorf("got %q, want just the path", got)
+	}
+}
+
+func TestScriptForCancel(t *testing.T) {
+	withFlags(t, "", "", false)
+	for _, typ := range []tui.ActionType{tui.ActionCancel, tui.ActionNone} {
+		_, err := scriptFor(&tui.Action{Type: typ}, t.TempDir(), &bytes.Buffer{})
+		if !errors.Is(err, ErrCancelled) {
+			t.Errorf("action %d: got %v, want ErrCancelled", typ, err)
+		}
+	}
+}
+
+func TestSelectorCancel(t *testing.T) {
+	t.Setenv(testActionEnv, "cancel")
+	if err := runSelector(t.TempDir(), ""); !errors.Is(err, ErrCancelled) {
+		t.Errorf("got %v, want ErrCancelled", err)
+	}
+}
+
+func TestSelectFirst(t *testing.T) {
+	base := t.TempDir()
+	for i, name := range []string{"2025-01-18-redis-old", "2025-01-19-redis", "2025-01-19-postgres"} {
+		os.Mkdir(filepath.Join(base, name), 0755)
+		mtime := time.Now().Add(-time.Duration(i) * time.Hour)
+		os.Chtimes(filepath.Join(base, name), mtime, mtime)
+	}
+
+	tests := []struct {
+		query string
+		want  string // "" when the picker should decide
+	}{
+		{"", "2025-01-18-redis-old"},
+		{"postgres", "2025-01-19-postgres"},
+		{"redis", ""},
+		{"mysql", ""},
+	}
+	for _, tt := range tests {
+		action, err := selectFirst(base, tt.query, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch {
+		case tt.want == "" && action != nil:
+			t.Errorf("query %q: got %+v, want the picker", tt.query, action)
+		case tt.want != "" && (action == nil || action.Path != filepath.Join(base, tt.want)):
+			t.Errorf("query %q: got %+v, want cd into %s", tt.query, action, tt.want)
+		}
+	}
+}
+
+func TestExecJoinsWords(t *testing.T) {
+	base := t.TempDir()
+	for i, name := range []string{"2025-01-19-foo", "2025-01-18-foo-bar"} {
+		os.Mkdir(filepath.Join(base, name), 0755)
+		mtime := time.Now().Add(-time.Duration(i) * time.Hour)
+		os.Chtimes(filepath.Join(base, name), mtime, mtime)
+	}
+	withFlags(t, "bash", "", true)
+	t.Setenv(testActionEnv, "select")
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	err = dispatchExec(base, []string{"foo", "bar"})
+	os.Stdout = stdout
+	w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	out.ReadFrom(r)
+	if got, want := strings.TrimSpace(out.String()), filepath.Join(base, "2025-01-18-foo-bar"); got != want {
+		t.Errorf("try foo bar chose %q, want %q as the query foo-bar would", got, want)
+	}
+
+	if err := dispatchExec(base, []string{"https://github.com/tobi/try", "extra"}); err == nil {
+		t.Error("a URL with more arguments should be an error")
+	}
+}
+
+func TestExecSubcommandWords(t *testing.T) {
+	base := t.TempDir()
+	for _, name := range []string{"2025-01-19-run-fast", "2025-01-18-new-york-trip"} {
+		os.Mkdir(filepath.Join(base, name), 0755)
+	}
+	withFlags(t, "bash", "", true)
+	t.Setenv(testActionEnv, "select")
+	oldPath := triesPath
+	triesPath = base
+	t.Cleanup(func() { triesPath = oldPath })
+
+	// -- keeps words a subcommand would take for exec itself
+	cmd, _, err := rootCmd.Find([]string{"exec", "--", "new", "york", "trip"})
+	if err != nil || cmd != execCmd {
+		t.Errorf("try -- new york trip found %v, %v; want exec", cmd.Name(), err)
+	}
+
+	// Words run can't take are a query, like any other words
+	if got, want := captureStdout(t, func() error { return runCmd.RunE(runCmd, []string{"fast"}) }), filepath.Join(base, "2025-01-19-run-fast"); got != want {
+		t.Errorf("try run fast chose %q, want %q", got, want)
+	}
+
+	// new without a name searches for "new" rather than failing
+	if got, want := captureStdout(t, func() error { return newCmd.RunE(newCmd, nil) }), filepath.Join(base, "2025-01-18-new-york-trip"); got != want {
+		t.Errorf("try new chose %q, want %q", got, want)
+	}
+}
+
+// captureStdout runs fn and returns what it printed, trimmed.
+func captureStdout(t *testing.T, fn func() error) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	err = fn()
+	os.Stdout = stdout
+	w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	out.ReadFrom(r)
+	return strings.TrimSpace(out.String())
+}
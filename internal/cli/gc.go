@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tobi/try/internal/workspace"
+)
+
+var (
+	gcYes    bool
+	gcDryRun bool
+)
+
+var gcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Remove workspaces that were created but never used",
+	Long: `Find workspaces that are empty, list them, and delete them after
+asking.
+
+Unlike deleting from the selector, this removes them for good rather than
+moving them to the trash, since there is nothing in them to restore.
+
+  go-try gc            # lists empty workspaces and asks before deleting
+  go-try gc --yes      # deletes without asking
+  go-try gc --dry-run  # says how much space deleting them would free`,
+	Args: cobra.NoArgs,
+	RunE: runGC,
+}
+
+func init() {
+	gcCmd.Flags().BoolVarP(&gcYes, "yes", "y", false, "delete without asking")
+	gcCmd.Flags().BoolVarP(&gcDryRun, "dry-run", "n", false, "only say what would be deleted and the space freed")
+	gcCmd.MarkFlagsMutuallyExclusive("yes", "dry-run")
+	rootCmd.AddCommand(gcCmd)
+}
+
+func runGC(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	basePath := getTriesPath()
+
+	entries, err := workspace.ScanWithOptions(basePath, workspace.ScanOptions{MaxDepth: scanDepth})
+	if err != nil {
+		return fmt.Errorf("failed to scan tries directory: %w", err)
+	}
+
+	var empty []workspace.Entry
+	for _, e := range entries {
+		// A link's target lives elsewhere; it isn't ours to judge
+		if e.Symlink {
+			continue
+		}
+		if ok, err := workspace.IsEmpty(e.Path); err == nil && ok {
+			empty = append(empty, e)
+		}
+	}
+	if len(empty) == 0 {
+		fmt.Fprintln(os.Stderr, "No empty workspaces.")
+		return nil
+	}
+
+	if gcDryRun {
+		reportReclaim(os.Stderr, empty)
+		return nil
+	}
+
+	for _, e := range empty {
+		fmt.Fprintln(os.Stderr, "  "+e.Name)
+	}
+	if !gcYes && !confirm(fmt.Sprintf("Delete %d empty workspace(s)?", len(empty))) {
+		fmt.Fprintln(os.Stderr, "Nothing deleted.")
+		return nil
+	}
+
+	removed := 0
+	for _, e := range empty {
+		// It may have been used since it was listed
+		if ok, err := workspace.IsEmpty(e.Path); err != nil || !ok {
+			continue
+		}
+		if err := workspace.Delete(basePath, e.Path); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to delete %s: %v\n", filepath.Base(e.Path), err)
+			continue
+		}
+		removed++
+	}
+	fmt.Fprintf(os.Stderr, "Removed %d empty workspace(s).\n", removed)
+	return nil
+}
+
+// reportReclaim lists entries with their sizes on w, then how much space
+// deleting them all would free. Sizes are walked within the size_max_depth
+// and size_skip bounds, so they can be lower bounds, shown with a "+".
+func reportReclaim(w io.Writer, entries []workspace.Entry) {
+	opts := workspace.UsageOptions{MaxDepth: config.SizeMaxDepth, Skip: config.sizeSkip()}
+
+	var total workspace.Usage
+	for _, e := range entries {
+		usage, err := workspace.DirUsageWithOptions(e.Path, opts)
+		if err != nil {
+			fmt.Fprintf(w, "  %s (size unknown: %v)\n", e.Name, err)
+			continue
+		}
+		fmt.Fprintf(w, "  %s (%s)\n", e.Name, usage.Size())
+		total = total.Add(usage)
+	}
+
+	noun := "workspaces"
+	if len(entries) == 1 {
+		noun = "workspace"
+	}
+	fmt.Fprintf(w, "would delete %d %s, freeing %s\n", len(entries), noun, total.Size())
+}
+
+// confirm asks question on stderr and reports whether the answer on stdin
+// was yes. No answer, as when stdin is closed, is no.
+func confirm(question string) bool {
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tobi/try/internal/workspace"
+)
+
+func TestReportReclaim(t *testing.T) {
+	base := t.TempDir()
+	var entries []workspace.Entry
+	for name, size := range map[string]int{"a": 1500, "b": 500} {
+		path := filepath.Join(base, name)
+		os.Mkdir(path, 0755)
+		os.WriteFile(filepath.Join(path, "data"), make([]byte, size), 0644)
+		entries = append(entries, workspace.Entry{Name: name, Path: path})
+	}
+	os.MkdirAll(filepath.Join(base, "a", "node_modules"), 0755)
+
+	var out bytes.Buffer
+	reportReclaim(&out, entries)
+	got := out.String()
+	for _, want := range []string{"  a (1.5 kB+)\n", "  b (500 B)\n", "would delete 2 workspaces, freeing 2.0 kB+\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+	for _, e := range entries {
+		if _, err := os.Stat(e.Path); err != nil {
+			t.Errorf("reporting must not delete %s", e.Name)
+		}
+	}
+}
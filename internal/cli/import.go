@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tobi/try/internal/workspace"
+)
+
+var importCopy bool
+
+var importCmd = &cobra.Command{
+	Use:   "import <dir>...",
+	Short: "Move existing directories into the tries folder",
+	Long: `Bring existing project directories under try by moving them into the
+tries folder as YYYY-MM-DD-<name>, dated by each directory's mtime.
+Names that already start with a date are kept as they are.
+
+With --copy the directories are copied and the originals left in place.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runImport,
+}
+
+func init() {
+	importCmd.Flags().BoolVar(&importCopy, "copy", false, "copy instead of moving")
+	rootCmd.AddCommand(importCmd)
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	basePath := getTriesPath()
+
+	// Ensure tries directory exists
+	if err := workspace.EnsureDir(basePath); err != nil {
+		return fmt.Errorf("failed to create tries directory: %w", err)
+	}
+
+	failed := 0
+	for _, src := range args {
+		dest, err := workspace.Import(basePath, src, importCopy)
+		if errors.Is(err, workspace.ErrNotDirectory) {
+			fmt.Fprintf(os.Stderr, "skipped %s: not a directory\n", src)
+			continue
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to import %s: %v\n", src, err)
+			failed++
+			continue
+		}
+		fmt.Printf("%s -> %s\n", src, dest)
+	}
+
+	if failed > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d of %d imports failed", failed, len(args))
+	}
+	return nil
+}
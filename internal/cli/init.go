@@ -1,9 +1,16 @@
 package cli
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -23,18 +30,63 @@ Add to your shell config:
   # fish (~/.config/fish/config.fish)
   eval (try init | string collect)
 
+  # elvish (~/.config/elvish/rc.elv); defines 'tryit' since 'try' is reserved
+  eval (try init --shell elvish | slurp)
+
+  # tcsh/csh: save the alias once, then source it from ~/.tcshrc
+  try init --shell tcsh > ~/.try.csh
+  source ~/.try.csh
+
 Optionally specify a custom tries directory:
 
-  eval "$(try init ~/code/experiments)"`,
+  eval "$(try init ~/code/experiments)"
+
+To keep the wrapper in a file instead, for example one a dotfile manager
+tracks, write it with --output and source that file from your config:
+
+  try init --output ~/.config/try/wrapper.sh
+
+Use --protocol v1 (bash, zsh, fish) for a wrapper that parses structured
+actions from exec instead of eval'ing its output.
+
+The wrapper exports TRY_WRAPPER, a fingerprint of its code. After
+upgrading, run 'try init --check' with the same arguments used to
+generate the wrapper to see whether the shell's copy is out of date.
+To see how it differs, pipe the loaded function to --print-init-diff:
+
+  declare -f try | try init --print-init-diff      # bash, zsh
+  functions try | try init --print-init-diff       # fish`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runInit,
 }
 
+var (
+	initCheck  bool
+	initOutput string
+	initForce  bool
+	initDiff   bool
+)
+
 func init() {
+	initCmd.Flags().StringVar(&protocol, "protocol", "",
+		"generate a wrapper using the structured exec protocol (v1)")
+	initCmd.Flags().BoolVar(&initCheck, "check", false,
+		"report whether the wrapper loaded in this shell is up to date")
+	initCmd.Flags().StringVarP(&initOutput, "output", "o", "",
+		"write the wrapper to this file and print the line that sources it")
+	initCmd.Flags().BoolVar(&initForce, "force", false,
+		"overwrite the --output file if it exists")
+	initCmd.Flags().BoolVar(&initDiff, "print-init-diff", false,
+		"compare the wrapper read from stdin with a fresh one and print the differences")
+	initCmd.MarkFlagsMutuallyExclusive("check", "print-init-diff", "output")
 	rootCmd.AddCommand(initCmd)
 }
 
 func runInit(cmd *cobra.Command, args []string) error {
+	if err := validateProtocol(); err != nil {
+		return err
+	}
+
 	// Get the path to the try binary
 	scriptPath, err := os.Executable()
 	if err != nil {
@@ -59,24 +111,317 @@ func runInit(cmd *cobra.Command, args []string) error {
 	// Detect shell
 	shellType := detectShell()
 
-	var script string
-	if shellType == "fish" {
-		script = shell.InitFish(scriptPath, tryPath)
-	} else {
-		script = shell.InitBash(scriptPath, tryPath)
+	script, err := initScript(shellType, scriptPath, tryPath)
+	if err != nil {
+		return err
+	}
+	if initCheck {
+		cmd.SilenceUsage = true
+		return checkWrapper(shellType, script)
+	}
+	if initDiff {
+		cmd.SilenceUsage = true
+		return diffWrapper(shellType, os.Stdin, os.Stdout, script)
+	}
+
+	script = shell.WithFingerprint(shellType, script)
+	if initOutput != "" {
+		cmd.SilenceUsage = true
+		return writeWrapper(shellType, initOutput, script, initForce)
 	}
 
 	fmt.Print(script)
 	return nil
 }
 
+// writeWrapper saves script to path, creating its directory, and prints the
+// line that sources it. An existing file is only replaced when force is set.
+func writeWrapper(shellType, path, script string, force bool) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if force {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	f, err := os.OpenFile(path, flags, 0644)
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("%s already exists; use --force to overwrite it", path)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot write wrapper: %w", err)
+	}
+	if _, err := f.WriteString(script); err != nil {
+		f.Close()
+		return fmt.Errorf("cannot write wrapper: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("cannot write wrapper: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Wrote %s. Add this line to your shell config:\n", path)
+	fmt.Println(shell.SourceLine(shellType, path))
+	return nil
+}
+
+// initScript returns the wrapper for shellType, without its fingerprint.
+func initScript(shellType, scriptPath, tryPath string) (string, error) {
+	if protocol != "" {
+		return protocolInitScript(shellType, scriptPath, tryPath)
+	}
+
+	switch shellType {
+	case "fish":
+		return shell.InitFish(scriptPath, tryPath, Version), nil
+	case "elvish":
+		return shell.InitElvish(scriptPath, tryPath, Version), nil
+	case "tcsh", "csh":
+		return shell.InitTcsh(scriptPath, tryPath, Version), nil
+	default:
+		return shell.InitBash(scriptPath, tryPath, Version), nil
+	}
+}
+
+// protocolInitScript returns the protocol-parsing wrapper for shellType.
+func protocolInitScript(shellType, scriptPath, tryPath string) (string, error) {
+	switch shellType {
+	case "fish":
+		return shell.InitFishProtocol(scriptPath, tryPath, Version), nil
+	case "bash", "zsh":
+		return shell.InitBashProtocol(scriptPath, tryPath, Version), nil
+	}
+	return "", fmt.Errorf("protocol %s is not supported for %s (supported: bash, zsh, fish)", protocol, shellType)
+}
+
+// checkWrapper compares the wrapper fingerprint exported by the running
+// shell with that of script, and explains how to reload it if they differ.
+func checkWrapper(shellType, script string) error {
+	loaded := os.Getenv(shell.WrapperEnv)
+	if loaded == "" {
+		return fmt.Errorf("no try wrapper found in this shell (%s is not set); load it with:\n  %s",
+			shell.WrapperEnv, reloadHint(shellType))
+	}
+	if loaded != shell.Fingerprint(script) {
+		return fmt.Errorf("the try wrapper loaded in this shell is out of date; reload it with:\n  %s",
+			reloadHint(shellType))
+	}
+	fmt.Fprintln(os.Stderr, "The try wrapper is up to date.")
+	return nil
+}
+
+// noteWrapperVersion tells w when the wrapper running exec, which passes
+// its version as shell.WrapperVersionEnv, was generated by another version
+// of try, such as the one before an upgrade.
+func noteWrapperVersion(w io.Writer) {
+	version := os.Getenv(shell.WrapperVersionEnv)
+	if version == "" || version == Version {
+		return
+	}
+	fmt.Fprintf(w, "The try wrapper in this shell is from %s (you have %s); reload it, or see 'go-try init --check'.\n",
+		version, Version)
+}
+
+// diffWrapper compares the wrapper read from loaded, as printed by the
+// shell or as saved, with script, and writes the lines that differ to w.
+// script is compared both as it is and as the shell prints the function
+// it defines, whichever is closer. Indentation and blank lines are
+// ignored, and so is the fingerprint line, which --check covers.
+func diffWrapper(shellType string, loaded io.Reader, w io.Writer, script string) error {
+	data, err := io.ReadAll(loaded)
+	if err != nil {
+		return fmt.Errorf("failed to read the loaded wrapper: %w", err)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return fmt.Errorf("no wrapper on stdin; pipe in the loaded function, such as with 'declare -f try'")
+	}
+
+	lines := wrapperLines(string(data))
+	diff := lineDiff(lines, wrapperLines(script))
+	if printed, err := printFunction(shellType, script); err == nil {
+		if d := lineDiff(lines, wrapperLines(printed)); len(d) < len(diff) {
+			diff = d
+		}
+	}
+	if len(diff) == 0 {
+		fmt.Fprintln(os.Stderr, "The loaded try wrapper matches a fresh one.")
+		return nil
+	}
+	fmt.Fprintln(w, "--- loaded")
+	fmt.Fprintln(w, "+++ fresh")
+	for _, line := range diff {
+		fmt.Fprintln(w, line)
+	}
+	return fmt.Errorf("the loaded try wrapper differs from a fresh one; reload it with:\n  %s", reloadHint(shellType))
+}
+
+// printFunction returns the try function script defines as shellType
+// prints it, with declare -f or functions, by loading it in a new shell.
+// Shells reformat functions they print, adding semicolons and dropping
+// comments, so this is what a loaded wrapper piped to --print-init-diff
+// looks like.
+func printFunction(shellType, script string) (string, error) {
+	var cmd *exec.Cmd
+	switch shellType {
+	case "bash", "zsh":
+		cmd = exec.Command(shellType, "-c", `eval "$1" && declare -f try`, shellType, script)
+	case "fish":
+		cmd = exec.Command("fish", "-c", "eval $argv[1]; and functions try", script)
+	default:
+		return "", fmt.Errorf("can't print %s functions", shellType)
+	}
+	out, err := cmd.Output()
+	return string(out), err
+}
+
+// wrapperLines splits a wrapper into its trimmed, non-blank lines, leaving
+// out the fingerprint.
+func wrapperLines(script string) []string {
+	var lines []string
+	for _, line := range strings.Split(script, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.Contains(line, shell.WrapperEnv+"=") || strings.Contains(line, shell.WrapperEnv+" ") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// lineDiff returns the lines that differ between a and b, in order, as
+// "- line" for lines only in a and "+ line" for lines only in b. It is
+// empty if a and b are equal.
+func lineDiff(a, b []string) []string {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var diff []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			diff = append(diff, "- "+a[i])
+			i++
+		default:
+			diff = append(diff, "+ "+b[j])
+			j++
+		}
+	}
+	return diff
+}
+
+// reloadHint returns the command that loads a freshly generated wrapper in
+// shellType, mirroring the setup instructions in init's help.
+func reloadHint(shellType string) string {
+	// The same command line, minus --check or --print-init-diff
+	parts := []string{filepath.Base(os.Args[0])}
+	for _, a := range os.Args[1:] {
+		if a != "--check" && a != "--check=true" && a != "--print-init-diff" && a != "--print-init-diff=true" {
+			parts = append(parts, a)
+		}
+	}
+	command := strings.Join(parts, " ")
+
+	switch shellType {
+	case "fish":
+		return fmt.Sprintf("eval (%s | string collect)", command)
+	case "elvish":
+		return fmt.Sprintf("eval (%s | slurp)", command)
+	case "tcsh", "csh":
+		return fmt.Sprintf("%s > ~/.try.csh; source ~/.try.csh", command)
+	}
+	return fmt.Sprintf(`eval "$(%s)"`, command)
+}
+
+// detectShell returns the shell to write the wrapper for: --shell if
+// given, else the login shell in $SHELL, else the shell running try.
 func detectShell() string {
-	// Check SHELL env var first
-	shellEnv := os.Getenv("SHELL")
-	if strings.Contains(shellEnv, "fish") {
-		return "fish"
+	// An explicit --shell wins
+	if shellName != "" {
+		return shellName
 	}
 
-	// Could also check parent process, but SHELL is usually sufficient
+	// $SHELL is the login shell, which needn't be the one running: someone
+	// whose login shell is zsh may have started fish. It still wins unless
+	// it is missing, unknown or a plain POSIX sh, since try is often run
+	// from sh -c or a script whatever the user's shell is.
+	login := os.Getenv("SHELL")
+	if name := knownShell(login); name != "" && !posixShell(login) {
+		return name
+	}
+	if name := knownShell(parentProcessName()); name != "" {
+		return name
+	}
 	return "bash"
 }
+
+// knownShell returns the shell type for a shell's path or process name,
+// or "" if it names no shell init knows. Other POSIX shells get bash's
+// wrapper.
+func knownShell(name string) string {
+	switch base := shellBase(name); base {
+	case "bash", "zsh", "fish", "elvish", "tcsh", "csh":
+		return base
+	case "sh", "dash", "ksh", "mksh":
+		return "bash"
+	}
+	return ""
+}
+
+// posixShell reports whether name is a POSIX shell with no wrapper of its
+// own, which says little about the shell the user actually works in.
+func posixShell(name string) bool {
+	switch shellBase(name) {
+	case "sh", "dash", "ksh", "mksh":
+		return true
+	}
+	return false
+}
+
+// shellBase returns the program name of a shell's path or process name.
+func shellBase(name string) string {
+	// Login shells run as "-zsh"
+	return strings.TrimPrefix(filepath.Base(name), "-")
+}
+
+// parentProcessName returns the name of the process that started try, or
+// "" if it can't be found out. It reads /proc on Linux and asks ps
+// elsewhere. A variable so tests can replace it.
+var parentProcessName = func() string {
+	ppid := os.Getppid()
+	switch runtime.GOOS {
+	case "linux":
+		data, err := os.ReadFile(fmt.Sprintf("/proc/%d/comm", ppid))
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(data))
+	case "windows":
+		return ""
+	}
+	out, err := exec.Command("ps", "-o", "comm=", "-p", strconv.Itoa(ppid)).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"bytes"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tobi/try/internal/shell"
+)
+
+func TestLineDiff(t *testing.T) {
+	tests := []struct {
+		a, b []string
+		want []string
+	}{
+		{[]string{"x", "y"}, []string{"x", "y"}, nil},
+		{[]string{"x", "old", "z"}, []string{"x", "new", "z"}, []string{"- old", "+ new"}},
+		{nil, []string{"x"}, []string{"+ x"}},
+		{[]string{"x", "y"}, []string{"y"}, []string{"- x"}},
+	}
+	for _, tt := range tests {
+		if got := lineDiff(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("lineDiff(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestDiffWrapper(t *testing.T) {
+	fresh := shell.InitBash("/usr/local/bin/try", "", "v1.2.0")
+
+	// The loaded copy, reindented and with its fingerprint, is the same
+	loaded := strings.ReplaceAll(shell.WithFingerprint("bash", fresh), "  ", "\t")
+	var out bytes.Buffer
+	if err := diffWrapper("bash", strings.NewReader(loaded), &out, fresh); err != nil || out.Len() != 0 {
+		t.Errorf("matching wrapper: got %v and diff:\n%s", err, out.String())
+	}
+
+	stale := shell.InitBash("/usr/local/bin/try", "", "v1.1.0")
+	out.Reset()
+	if err := diffWrapper("bash", strings.NewReader(stale), &out, fresh); err == nil {
+		t.Error("a stale wrapper should be reported")
+	}
+	if !strings.Contains(out.String(), "- # try wrapper v1.1.0") || !strings.Contains(out.String(), "+ # try wrapper v1.2.0") {
+		t.Errorf("diff should show the version lines:\n%s", out.String())
+	}
+
+	if err := diffWrapper("bash", strings.NewReader(""), &out, fresh); err == nil {
+		t.Error("empty stdin should be an error")
+	}
+}
+
+func TestDiffWrapperDeclareF(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not installed")
+	}
+	fresh := shell.InitBash("/usr/local/bin/go-try", "", "v1.2.3")
+
+	// What 'declare -f try' prints in bash 5 once the wrapper is loaded
+	const declared = `try () 
+{ 
+    local out;
+    out=$(/usr/bin/env 'TRY_WRAPPER_VERSION=v1.2.3' '/usr/local/bin/go-try' exec "$@" 2> /dev/tty);
+    if [ $? -eq 0 ]; then
+        eval "$out";
+    else
+        echo "$out";
+    fi
+}
+`
+	var out bytes.Buffer
+	if err := diffWrapper("bash", strings.NewReader(declared), &out, fresh); err != nil || out.Len() != 0 {
+		t.Errorf("a freshly loaded wrapper should match: got %v and diff:\n%s", err, out.String())
+	}
+
+	out.Reset()
+	stale := strings.ReplaceAll(declared, "v1.2.3", "v1.1.0")
+	if err := diffWrapper("bash", strings.NewReader(stale), &out, fresh); err == nil {
+		t.Error("a stale wrapper should be reported")
+	}
+	if !strings.Contains(out.String(), "TRY_WRAPPER_VERSION=v1.1.0") || strings.Contains(out.String(), "eval") {
+		t.Errorf("diff should show only the changed line:\n%s", out.String())
+	}
+}
+
+func TestNoteWrapperVersion(t *testing.T) {
+	var out bytes.Buffer
+	for _, version := range []string{"", Version} {
+		t.Setenv(shell.WrapperVersionEnv, version)
+		noteWrapperVersion(&out)
+	}
+	if out.Len() != 0 {
+		t.Errorf("no note expected without a wrapper or from a current one, got %q", out.String())
+	}
+
+	t.Setenv(shell.WrapperVersionEnv, "v0.1.0")
+	noteWrapperVersion(&out)
+	if !strings.Contains(out.String(), "from v0.1.0") {
+		t.Errorf("expected a note naming the wrapper's version, got %q", out.String())
+	}
+}
+
+func TestDetectShell(t *testing.T) {
+	oldParent, oldShell := parentProcessName, shellName
+	t.Cleanup(func() { parentProcessName, shellName = oldParent, oldShell })
+
+	tests := []struct {
+		name, flag, parent, env, want string
+	}{
+		{"flag wins", "elvish", "fish", "/bin/zsh", "elvish"},
+		{"login shell over running shell", "", "fish", "/bin/zsh", "zsh"},
+		{"posix parent doesn't override", "", "dash", "/usr/bin/fish", "fish"},
+		{"sh -c doesn't override", "", "sh", "/usr/bin/fish", "fish"},
+		{"no $SHELL", "", "-zsh", "", "zsh"},
+		{"unknown $SHELL", "", "fish", "/usr/bin/nu", "fish"},
+		{"posix $SHELL", "", "fish", "/bin/sh", "fish"},
+		{"posix $SHELL and parent", "", "dash", "/bin/sh", "bash"},
+		{"parent not a shell", "", "make", "/usr/local/bin/fish", "fish"},
+		{"parent unknown", "", "", "/bin/tcsh", "tcsh"},
+		{"nothing known", "", "", "/usr/bin/nu", "bash"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shellName = tt.flag
+			parentProcessName = func() string { return tt.parent }
+			t.Setenv("SHELL", tt.env)
+			if got := detectShell(); got != tt.want {
+				t.Errorf("detectShell() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
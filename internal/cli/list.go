@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tobi/try/internal/workspace"
+	"github.com/tobi/try/trycore"
+)
+
+var (
+	listTags   []string
+	listLong   bool
+	listCount  bool
+	listRepos  bool
+	listAll    bool
+	listFilter string
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List workspaces, most recent first",
+	Long: `Print the name of every workspace, most recently used first.
+
+With --tag, only workspaces carrying that tag are listed; given several
+times, a workspace needs all of them. Tags are set with ctrl+t in the
+selector. With --repos-only, only git repositories are listed. With --all,
+hidden workspaces, whose names start with ".", are listed too.
+
+With --filter, only workspaces matching a query are listed, best match
+first, ranked exactly as the picker's filter ranks them.
+
+With --long, each name is followed by when it was last used, relative or
+absolute as set by --time-format or time_format in the config. With
+--count, only the number of workspaces is printed, for shell prompts.
+
+  go-try list --tag work --tag spike
+  go-try list --filter redis
+  go-try list --long --time-format absolute
+  go-try list --count`,
+	Args: cobra.NoArgs,
+	RunE: runList,
+}
+
+func init() {
+	listCmd.Flags().StringArrayVar(&listTags, "tag", nil, "only list workspaces with this tag (repeatable)")
+	listCmd.Flags().BoolVarP(&listLong, "long", "l", false, "show when each workspace was last used")
+	listCmd.Flags().BoolVarP(&listCount, "count", "c", false, "print only the number of workspaces")
+	listCmd.Flags().BoolVar(&listRepos, "repos-only", false, "only list git repositories")
+	listCmd.Flags().BoolVarP(&listAll, "all", "a", false, "also list hidden workspaces")
+	listCmd.Flags().StringVarP(&listFilter, "filter", "f", "", "only list workspaces matching this query, best first")
+	listCmd.MarkFlagsMutuallyExclusive("long", "count")
+	rootCmd.AddCommand(listCmd)
+}
+
+func runList(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	timeFormat, err := config.timeFormat()
+	if err != nil {
+		return err
+	}
+
+	tries := triesDir()
+	tries.IncludeHidden = listAll
+	list := tries.List
+	if listFilter != "" {
+		list = func() ([]trycore.Workspace, error) { return tries.Match(listFilter) }
+	}
+	all, err := list()
+	if err != nil {
+		return err
+	}
+
+	var listed []trycore.Workspace
+	for _, ws := range all {
+		// Checked last: it is the only filter that touches the disk
+		if hasAllTags(ws.Tags, listTags) && (!listRepos || workspace.IsGitRepo(ws.Path)) {
+			listed = append(listed, ws)
+		}
+	}
+	if listCount {
+		fmt.Println(len(listed))
+		return nil
+	}
+
+	now := time.Now()
+	for _, ws := range listed {
+		if listLong {
+			when := workspace.FormatTime(ws.ModTime, now, timeFormat)
+			if ws.Broken {
+				when = "broken link"
+			}
+			fmt.Printf("%s\t%s\n", ws.Name, when)
+		} else {
+			fmt.Println(ws.Name)
+		}
+	}
+	return nil
+}
+
+// hasAllTags reports whether have contains every tag in want.
+func hasAllTags(have, want []string) bool {
+	for _, t := range want {
+		if !workspace.HasTag(have, t) {
+			return false
+		}
+	}
+	return true
+}
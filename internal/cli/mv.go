@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tobi/try/internal/workspace"
+)
+
+var mvCmd = &cobra.Command{
+	Use:   "mv <query> <dir>",
+	Short: "Move a workspace to another tries directory and output a cd script",
+	Long: `Move the workspace matching a query out of the tries directory into
+another one, such as a folder of long-lived projects, and cd there.
+
+The query is resolved like 'go-try which' resolves it. The destination
+must be an existing directory; the workspace keeps its name, with a
+suffix if the destination already has one like it. Its note and tags go
+along, into the destination's own .try-notes.json and .try-tags.json.
+
+  try mv redis ~/src        # ~/src/2025-01-19-redis
+
+Across filesystems the workspace is copied and the original removed.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMv,
+}
+
+func init() {
+	execCmd.AddCommand(mvCmd)
+}
+
+func runMv(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	tries := triesDir()
+	ws, err := tries.Resolve(args[0])
+	if err != nil {
+		return err
+	}
+
+	dest, err := workspace.MoveToBase(tries.Path, ws.Path, workspace.NormalizePath(args[1]))
+	if dest == "" {
+		return fmt.Errorf("failed to move %s: %w", ws.Name, err)
+	}
+	if err != nil {
+		// Moved all the same, so still land there
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Moved %s to %s\n", ws.Name, dest)
+	if printPath {
+		fmt.Println(dest)
+		return nil
+	}
+	fmt.Print(getDialect().CD(dest))
+	return nil
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tobi/try/internal/workspace"
+)
+
+var (
+	noDate      bool
+	createQuiet bool
+)
+
+var newCmd = &cobra.Command{
+	Use:   "new <name...>",
+	Short: "Create a new workspace and output a cd script",
+	Long: `Create a new workspace without opening the selector.
+
+The directory is prefixed with today's date unless --no-date is given.
+Like exec, the output is meant to be eval'd by the shell wrapper, so
+'try new redis-test' creates the directory and cds into it.
+
+Multiple words are joined with hyphens: 'try new quick test' creates
+YYYY-MM-DD-quick-test.
+
+If the name is taken, a suffix is added (quick-test-2) and a note on
+stderr names the existing workspace, in case you meant that one; --quiet
+leaves it out.
+
+Without a name, 'try new' is a search for "new", like 'try run' alone is
+for "run": the selector opens filtered on it, and typing a name there and
+pressing ctrl+n creates it.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runNew,
+}
+
+func init() {
+	newCmd.Flags().BoolVar(&noDate, "no-date", false, "don't prefix the name with today's date")
+	newCmd.Flags().BoolVarP(&createQuiet, "quiet", "q", false, "don't say when the name was taken")
+	queryFallback(newCmd)
+	execCmd.AddCommand(newCmd)
+}
+
+func runNew(cmd *cobra.Command, args []string) error {
+	basePath := getTriesPath()
+
+	// Ensure tries directory exists
+	if err := workspace.EnsureDir(basePath); err != nil {
+		return fmt.Errorf("failed to create tries directory: %w", err)
+	}
+
+	name := strings.Join(args, " ")
+	create := workspace.Create
+	if noDate {
+		create = workspace.CreateRaw
+	}
+	path, err := create(basePath, name)
+	if err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	noteRenamed(os.Stderr, name, !noDate, path)
+
+	if printPath {
+		fmt.Println(path)
+		return nil
+	}
+	fmt.Print(getDialect().MkdirCD(path))
+	return nil
+}
+
+// noteRenamed tells the user on w when the workspace created at path for
+// name got a suffix because a workspace already had that name, unless
+// --quiet is set.
+func noteRenamed(w io.Writer, name string, dated bool, path string) {
+	want := workspace.DirName(name, dated)
+	if createQuiet || filepath.Base(path) == want {
+		return
+	}
+	fmt.Fprintf(w, "a similar workspace '%s' already exists; created '%s'\n", want, filepath.Base(path))
+}
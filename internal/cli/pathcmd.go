@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var pathVerbose bool
+
+var pathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Print the tries directory",
+	Long: `Print the tries directory try uses, absolute and normalized.
+
+It comes from --path if given, else $TRY_PATH, else ~/src/tries. With
+--verbose, which of these it came from is written to stderr.
+
+  cd "$(go-try path)"`,
+	Args: cobra.NoArgs,
+	RunE: runPath,
+}
+
+func init() {
+	pathCmd.Flags().BoolVarP(&pathVerbose, "verbose", "v", false, "say where the path came from on stderr")
+	rootCmd.AddCommand(pathCmd)
+}
+
+func runPath(cmd *cobra.Command, args []string) error {
+	fmt.Println(getTriesPath())
+	if pathVerbose {
+		fmt.Fprintf(os.Stderr, "from %s\n", triesPathSource(cmd))
+	}
+	return nil
+}
+
+// triesPathSource names where the tries directory was set.
+func triesPathSource(cmd *cobra.Command) string {
+	switch {
+	case cmd.Flags().Changed("path"):
+		return "--path"
+	case os.Getenv("TRY_PATH") != "":
+		return "$TRY_PATH"
+	}
+	return "the default"
+}
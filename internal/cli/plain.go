@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/tobi/try/internal/fuzzy"
+	"github.com/tobi/try/internal/tui"
+	"github.com/tobi/try/internal/workspace"
+)
+
+// dumbTerminal reports whether the selector should fall back to plain
+// prompts: TERM is dumb, or there is no terminal to draw on.
+func dumbTerminal(tty *os.File) bool {
+	if os.Getenv("TERM") == "dumb" {
+		return true
+	}
+	return tty == nil || !term.IsTerminal(tty.Fd())
+}
+
+// selectorEntries returns the workspaces the selector would list for
+// query, best first, ranked like the picker's filter, tags and notes
+// included.
+func selectorEntries(basePath, query string, gitRecency bool) ([]workspace.Entry, error) {
+	entries, err := workspace.ScanWithOptions(basePath, scanOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan tries directory: %w", err)
+	}
+	if gitRecency {
+		workspace.ApplyCommitTimes(entries)
+		workspace.SortByRecency(entries)
+	}
+	if query != "" {
+		// Like the picker, rows just lack what can't be read
+		notes, _ := workspace.LoadNotes(basePath)
+		tags, _ := workspace.LoadTags(basePath)
+		entries = fuzzy.Rank(entries, query, notes, tags)
+	}
+	return entries, nil
+}
+
+// plainSelect is the selector without a TUI: it prints a numbered list of
+// workspaces to out and reads a choice from in. A number picks that
+// workspace, anything else is the name of a new one, and nothing cancels.
+func plainSelect(basePath, query string, gitRecency bool, in io.Reader, out io.Writer) (*tui.Action, error) {
+	entries, err := selectorEntries(basePath, query, gitRecency)
+	if err != nil {
+		return nil, err
+	}
+	if limit := config.maxResults(); limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	for i, e := range entries {
+		fmt.Fprintf(out, "%3d  %s\n", i+1, e.Name)
+	}
+	if len(entries) > 0 {
+		fmt.Fprint(out, "Number to open, or a name to create: ")
+	} else {
+		fmt.Fprint(out, "Name to create: ")
+	}
+
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return nil, ErrCancelled
+	}
+
+	if n, err := strconv.Atoi(answer); err == nil {
+		if n < 1 || n > len(entries) {
+			return nil, fmt.Errorf("no workspace numbered %d", n)
+		}
+		return &tui.Action{Type: tui.ActionCD, Path: entries[n-1].Path, BaseDir: basePath}, nil
+	}
+	return &tui.Action{Type: tui.ActionCreate, Path: answer, BaseDir: basePath}, nil
+}
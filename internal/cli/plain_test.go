@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tobi/try/internal/tui"
+)
+
+func TestPlainSelect(t *testing.T) {
+	base := t.TempDir()
+	// redis was used last, so it is listed first
+	now := time.Now()
+	for i, name := range []string{"2025-01-01-redis", "2025-01-02-postgres"} {
+		os.Mkdir(filepath.Join(base, name), 0755)
+		mtime := now.Add(-time.Duration(i) * time.Hour)
+		os.Chtimes(filepath.Join(base, name), mtime, mtime)
+	}
+
+	tests := []struct {
+		query, input string
+		want         tui.Action
+	}{
+		{"", "1\n", tui.Action{Type: tui.ActionCD, Path: filepath.Join(base, "2025-01-01-redis")}},
+		{"post", "1\n", tui.Action{Type: tui.ActionCD, Path: filepath.Join(base, "2025-01-02-postgres")}},
+		{"", "new idea\n", tui.Action{Type: tui.ActionCreate, Path: "new idea"}},
+		{"", "  2", tui.Action{Type: tui.ActionCD, Path: filepath.Join(base, "2025-01-02-postgres")}},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		a, err := plainSelect(base, tt.query, false, strings.NewReader(tt.input), &out)
+		if err != nil {
+			t.Errorf("%q/%q: %v", tt.query, tt.input, err)
+			continue
+		}
+		if a.Type != tt.want.Type || a.Path != tt.want.Path {
+			t.Errorf("%q/%q: got %+v, want %+v", tt.query, tt.input, a, tt.want)
+		}
+		if strings.Contains(out.String(), "\x1b[") {
+			t.Errorf("plain output should have no escape codes: %q", out.String())
+		}
+	}
+
+	var out bytes.Buffer
+	if _, err := plainSelect(base, "", false, strings.NewReader("\n"), &out); !errors.Is(err, ErrCancelled) {
+		t.Errorf("empty answer should cancel, got %v", err)
+	}
+	if !strings.Contains(out.String(), "  1  2025-01-01-redis\n") {
+		t.Errorf("entries should be numbered, got:\n%s", out.String())
+	}
+	if _, err := plainSelect(base, "", false, strings.NewReader("3\n"), &out); err == nil {
+		t.Error("out of range number should fail")
+	}
+}
@@ -3,11 +3,16 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
+	"github.com/charmbracelet/lipgloss"
 	"github.com/spf13/cobra"
+	"github.com/tobi/try/internal/shell"
 	"github.com/tobi/try/internal/theme"
 	"github.com/tobi/try/internal/workspace"
+	"github.com/tobi/try/trycore"
 )
 
 var (
@@ -18,6 +23,14 @@ var (
 	triesPath  string
 	themeName  string
 	noColors   bool
+	useCache   bool
+	shellName  string
+	protocol   string
+	printPath  bool
+	scanDepth  int
+	groupDates bool
+
+	timeFormatFlag string
 )
 
 // rootCmd is the base command
@@ -55,24 +68,74 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&triesPath, "path", "", 
 		fmt.Sprintf("tries directory (default: %s)", workspace.DefaultPath()))
 	rootCmd.PersistentFlags().StringVar(&themeName, "theme", "default",
-		fmt.Sprintf("color theme (%v)", theme.Names()))
+		fmt.Sprintf("color theme (%s, random, auto; list to preview)", strings.Join(theme.Names(), ", ")))
 	rootCmd.PersistentFlags().BoolVar(&noColors, "no-colors", false,
 		"disable colors")
+	rootCmd.PersistentFlags().BoolVar(&useCache, "cache", false,
+		"cache scan results between invocations")
+	rootCmd.PersistentFlags().IntVar(&scanDepth, "depth", 1,
+		"directory levels to scan for workspaces")
+	rootCmd.PersistentFlags().BoolVar(&groupDates, "group", false,
+		"group the list by date (toggle with alt+g)")
+	rootCmd.PersistentFlags().StringVar(&timeFormatFlag, "time-format", "",
+		"how to show last activity: relative or absolute (default: time_format from the config, else relative)")
+	rootCmd.PersistentFlags().StringVar(&shellName, "shell", "",
+		"shell to generate code for (bash, zsh, fish, elvish, tcsh; default: $SHELL, then the running shell)")
 
 	// Hide help command
 	rootCmd.CompletionOptions.HiddenDefaultCmd = true
 }
 
 func initConfig() {
-	// Set tries path from flag or default
+	// Load the config file; a broken one shouldn't stop try from working
+	cfg, err := loadConfig(configPath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	config = cfg
+
+	// Report every problem at once rather than misbehaving quietly
+	if err := config.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: problems in config %s:\n  %s\n",
+			configPath(), strings.ReplaceAll(err.Error(), "\n", "\n  "))
+	}
+
+	// Invalid formats were reported above and keep their defaults
+	if config.DateFormat != "" {
+		_ = workspace.SetDateFormat(config.DateFormat)
+	}
+	if config.CloneNameTemplate != "" {
+		_ = workspace.SetCloneNameTemplate(config.CloneNameTemplate)
+	}
+	workspace.SetLowercaseNames(config.LowercaseNames)
+	if config.ExportVar == "" || envName.MatchString(config.ExportVar) {
+		shell.SetExportVar(config.ExportVar)
+	}
+
+	// Set tries path from flag or default, normalized once so every
+	// command and the shell wrapper see the same path
 	if triesPath == "" {
 		triesPath = workspace.DefaultPath()
 	}
+	triesPath = workspace.NormalizePath(triesPath)
+
+	// Handle TRY_CACHE env var
+	if os.Getenv("TRY_CACHE") != "" {
+		useCache = true
+	}
 
 	// Handle NO_COLOR env var
 	if os.Getenv("NO_COLOR") != "" {
 		noColors = true
 	}
+
+	// --theme list previews the themes instead of running the command.
+	// It prints to stderr so the shell wrapper shows it rather than
+	// eval'ing it.
+	if themeName == "list" {
+		printThemes(os.Stderr)
+		os.Exit(0)
+	}
 }
 
 // getTriesPath returns the configured tries path.
@@ -80,7 +143,70 @@ func getTriesPath() string {
 	return triesPath
 }
 
-// getTheme returns the configured theme.
+// scanOptions returns how commands scan the tries directory: to --depth,
+// and including files if the config asks for them.
+func scanOptions() workspace.ScanOptions {
+	return workspace.ScanOptions{MaxDepth: scanDepth, IncludeFiles: config.IncludeFiles}
+}
+
+// triesDir returns the tries directory for the trycore API, scanned like
+// scanOptions.
+func triesDir() trycore.Dir {
+	return trycore.Dir{Path: getTriesPath(), Depth: scanDepth, IncludeFiles: config.IncludeFiles}
+}
+
+// getDialect returns the script dialect for the shell selected with
+// --shell, or the structured protocol when --protocol is set.
+func getDialect() shell.Dialect {
+	if protocol == "v1" {
+		return shell.ProtocolV1
+	}
+	return shell.DialectFor(shellName)
+}
+
+// validateProtocol rejects unknown --protocol versions.
+func validateProtocol() error {
+	if protocol != "" && protocol != "v1" {
+		return fmt.Errorf("unknown protocol %q (supported: v1)", protocol)
+	}
+	return nil
+}
+
+// getTheme returns the configured theme. With colors disabled the
+// monochrome theme is used regardless of --theme; "random" picks a
+// different theme each run. "auto" needs the terminal and is resolved by
+// autoTheme; without one it is the default theme.
 func getTheme() theme.Theme {
+	if noColors {
+		return theme.Monochrome
+	}
+	if themeName == "random" {
+		_, t := theme.Random()
+		return t
+	}
 	return theme.Get(themeName)
 }
+
+// printThemes writes each theme name with a swatch of its colors, or just
+// the names with colors disabled.
+func printThemes(w io.Writer) {
+	r := lipgloss.NewRenderer(w)
+
+	for _, name := range theme.Names() {
+		if noColors {
+			fmt.Fprintln(w, name)
+			continue
+		}
+
+		t := theme.Themes[name]
+		swatch := ""
+		for _, c := range []lipgloss.Color{
+			t.Primary, t.Secondary, t.Accent, t.Highlight,
+			t.Success, t.Warning, t.Error,
+			t.BackgroundSelected, t.BackgroundDanger,
+		} {
+			swatch += r.NewStyle().Foreground(c).Render("██")
+		}
+		fmt.Fprintf(w, "%-12s %s\n", name, swatch)
+	}
+}
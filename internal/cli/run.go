@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run <query> -- <command...>",
+	Short: "Output a script that runs a command in a workspace and comes back",
+	Long: `Run a command in the workspace matching a query without staying there:
+the shell wrapper starts it with sh in that directory, so you're left
+where you were.
+
+  try run server -- npm test
+
+The query is resolved like 'go-try which' resolves it, and several words
+are joined with hyphens. Everything after -- is the command, each word
+quoted as it is, so run it through sh -c for pipes or globs; shell
+functions and aliases aren't available to it:
+
+  try run server -- sh -c 'npm test | tail'
+
+This needs a wrapper that evals scripts; --protocol v1 has no way to
+run commands.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		dash := cmd.ArgsLenAtDash()
+		if dash < 1 || dash == len(args) {
+			return errors.New("requires a query, then -- and a command")
+		}
+		return nil
+	},
+	RunE: runRun,
+}
+
+func init() {
+	queryFallback(runCmd)
+	execCmd.AddCommand(runCmd)
+}
+
+func runRun(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	if protocol != "" || printPath {
+		return errors.New("run can't be used with --protocol or --print-path")
+	}
+
+	dash := cmd.ArgsLenAtDash()
+	ws, err := triesDir().Resolve(strings.Join(args[:dash], "-"))
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(getDialect().Run(ws.Path, args[dash:]))
+	return nil
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tobi/try/internal/workspace"
+)
+
+var (
+	staleOlderThan string
+	staleArchive   bool
+)
+
+var staleCmd = &cobra.Command{
+	Use:   "stale",
+	Short: "List workspaces unused for a while, as candidates for archiving",
+	Long: `List the workspaces not modified within --older-than (default 60d),
+oldest first, with when they were last used and their size. Sizes are
+walked within the size_max_depth and size_skip bounds, so a "+" marks a
+lower bound.
+
+With --archive they are also moved to <path>/.archive, out of the list
+but kept as they were, notes and tags included. Nothing is deleted.
+
+--older-than takes days (60d), weeks (8w) or a Go duration (36h).
+
+  go-try stale
+  go-try stale --older-than 8w --archive`,
+	Args: cobra.NoArgs,
+	RunE: runStale,
+}
+
+func init() {
+	staleCmd.Flags().StringVar(&staleOlderThan, "older-than", "60d", "how long unused, e.g. 60d, 8w or 36h")
+	staleCmd.Flags().BoolVar(&staleArchive, "archive", false, "move the stale workspaces to <path>/.archive")
+	rootCmd.AddCommand(staleCmd)
+}
+
+func runStale(cmd *cobra.Command, args []string) error {
+	age, err := parseAge(staleOlderThan)
+	if err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+
+	timeFormat, err := config.timeFormat()
+	if err != nil {
+		return err
+	}
+
+	basePath := getTriesPath()
+	entries, err := workspace.ScanWithOptions(basePath, scanOptions())
+	if err != nil {
+		return fmt.Errorf("failed to scan tries directory: %w", err)
+	}
+	stale := staleEntries(entries, time.Now().Add(-age))
+	if len(stale) == 0 {
+		fmt.Fprintf(os.Stderr, "No workspaces unused for %s.\n", staleOlderThan)
+		return nil
+	}
+
+	now := time.Now()
+	opts := workspace.UsageOptions{MaxDepth: config.SizeMaxDepth, Skip: config.sizeSkip()}
+	for _, e := range stale {
+		size := "?"
+		if usage, err := workspace.DirUsageWithOptions(e.Path, opts); err == nil {
+			size = usage.Size()
+		}
+		fmt.Printf("%s\t%s\t%s\n", e.Name, workspace.FormatTime(e.ModTime, now, timeFormat), size)
+	}
+	if !staleArchive {
+		return nil
+	}
+
+	archived := 0
+	for _, e := range stale {
+		if _, err := workspace.Archive(basePath, e.Path); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to archive %s: %v\n", e.Name, err)
+			continue
+		}
+		archived++
+	}
+	fmt.Fprintf(os.Stderr, "Archived %d workspace(s) to %s.\n", archived, workspace.ArchiveDir)
+	return nil
+}
+
+// staleEntries returns the entries last modified before cutoff, oldest
+// first. Symlinks are left out: what they point at isn't ours to archive.
+func staleEntries(entries []workspace.Entry, cutoff time.Time) []workspace.Entry {
+	var stale []workspace.Entry
+	for _, e := range entries {
+		if !e.Symlink && e.ModTime.Before(cutoff) {
+			stale = append(stale, e)
+		}
+	}
+	sort.SliceStable(stale, func(i, j int) bool {
+		return stale[i].ModTime.Before(stale[j].ModTime)
+	})
+	return stale
+}
+
+// parseAge parses a duration in days ("60d"), weeks ("8w"), or anything
+// time.ParseDuration takes.
+func parseAge(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			if count, err := strconv.Atoi(n); err == nil && count >= 0 {
+				return time.Duration(count) * unit, nil
+			}
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q: want e.g. 60d, 8w or 36h", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tobi/try/internal/workspace"
+)
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"60d", 60 * 24 * time.Hour},
+		{"8w", 8 * 7 * 24 * time.Hour},
+		{"36h", 36 * time.Hour},
+		{"0d", 0},
+	}
+	for _, tt := range tests {
+		if got, err := parseAge(tt.in); err != nil || got != tt.want {
+			t.Errorf("parseAge(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"", "d", "-3d", "soon", "1.5d"} {
+		if _, err := parseAge(bad); err == nil {
+			t.Errorf("parseAge(%q) should fail", bad)
+		}
+	}
+}
+
+func TestStaleEntries(t *testing.T) {
+	now := time.Now()
+	entries := []workspace.Entry{
+		{Name: "fresh", ModTime: now.Add(-time.Hour)},
+		{Name: "old", ModTime: now.Add(-90 * 24 * time.Hour)},
+		{Name: "link", ModTime: now.Add(-400 * 24 * time.Hour), Symlink: true},
+		{Name: "older", ModTime: now.Add(-200 * 24 * time.Hour)},
+	}
+
+	stale := staleEntries(entries, now.Add(-60*24*time.Hour))
+	var names []string
+	for _, e := range stale {
+		names = append(names, e.Name)
+	}
+	if len(names) != 2 || names[0] != "older" || names[1] != "old" {
+		t.Errorf("got %v, want [older old]", names)
+	}
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/tobi/try/internal/workspace"
+)
+
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Inspect workspace templates",
+	Long: `Inspect the workspace templates in the templates folder, which is
+try/templates in the user config directory (next to config.toml).
+
+Each directory in the templates folder is one template.`,
+}
+
+var templateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List available templates",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		names, err := workspace.ListTemplates(templatesDir())
+		if err != nil {
+			return fmt.Errorf("failed to list templates: %w", err)
+		}
+		for _, name := range names {
+			fmt.Println(name)
+		}
+		return nil
+	},
+}
+
+var templateShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Print the file tree of a template",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := workspace.TemplatePath(templatesDir(), args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Println(args[0] + "/")
+		return printTree(os.Stdout, path, "")
+	},
+}
+
+func init() {
+	templateCmd.AddCommand(templateListCmd, templateShowCmd)
+	rootCmd.AddCommand(templateCmd)
+}
+
+// templatesDir returns the templates folder, which sits next to the
+// config file.
+func templatesDir() string {
+	return filepath.Join(filepath.Dir(configPath()), "templates")
+}
+
+// printTree writes the contents of dir as an indented tree.
+func printTree(w io.Writer, dir, indent string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for i, e := range entries {
+		branch, next := "├── ", "│   "
+		if i == len(entries)-1 {
+			branch, next = "└── ", "    "
+		}
+
+		name := e.Name()
+		if e.IsDir() {
+			name += "/"
+		}
+		fmt.Fprintln(w, indent+branch+name)
+
+		if e.IsDir() {
+			if err := printTree(w, filepath.Join(dir, e.Name()), indent+next); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tobi/try/internal/fuzzy"
+	"github.com/tobi/try/internal/tui"
+	"github.com/tobi/try/internal/workspace"
+)
+
+// testActionEnv names the environment variable that makes the selector
+// skip the TUI and act as if the user had chosen an action, so tests and
+// screenshot scripts can run exec without a terminal. Its value is one of:
+//
+//	select              the entry the picker would highlight for the query
+//	select:<query>      the workspace 'go-try which <query>' prints
+//	create:<name>       create a date-prefixed workspace
+//	create-undated:<name>
+//	clone:<url>         clone into a new workspace
+//	delete:<query>      move the matching workspace to the trash
+//	base                cd into the tries directory itself
+//	cancel              quit without choosing
+const testActionEnv = "TRY_TEST_ACTION"
+
+// headlessAction returns the action described by spec (see testActionEnv)
+// for the selector opened on basePath with query.
+func headlessAction(basePath, query, spec string, gitRecency bool) (*tui.Action, error) {
+	verb, arg, _ := strings.Cut(spec, ":")
+
+	// find resolves arg, or the query, like the picker would
+	find := func() (workspace.Entry, error) {
+		if arg != "" {
+			entries, err := selectorEntries(basePath, "", gitRecency)
+			if err != nil {
+				return workspace.Entry{}, err
+			}
+			notes, _ := workspace.LoadNotes(basePath)
+			tags, _ := workspace.LoadTags(basePath)
+			return fuzzy.BestMatch(arg, entries, notes, tags)
+		}
+		entries, err := selectorEntries(basePath, query, gitRecency)
+		if err != nil {
+			return workspace.Entry{}, err
+		}
+		if len(entries) == 0 {
+			return workspace.Entry{}, fmt.Errorf("%w: %q", fuzzy.ErrNoMatch, query)
+		}
+		return entries[0], nil
+	}
+
+	switch verb {
+	case "select":
+		e, err := find()
+		if err != nil {
+			return nil, err
+		}
+		return &tui.Action{Type: tui.ActionCD, Path: e.Path, BaseDir: basePath}, nil
+
+	case "create", "create-undated":
+		if arg == "" {
+			return nil, fmt.Errorf("%s=%s needs a name", testActionEnv, verb)
+		}
+		return &tui.Action{Type: tui.ActionCreate, Path: arg, BaseDir: basePath, NoDate: verb == "create-undated"}, nil
+
+	case "clone":
+		path, url, err := workspace.CloneScript(basePath, arg, cloneFlat)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse git URL: %w", err)
+		}
+		return &tui.Action{Type: tui.ActionClone, Path: path, URL: url, BaseDir: basePath}, nil
+
+	case "delete":
+		if arg == "" {
+			return nil, fmt.Errorf("%s=delete needs a query", testActionEnv)
+		}
+		e, err := find()
+		if err != nil {
+			return nil, err
+		}
+		return &tui.Action{Type: tui.ActionDelete, Paths: []string{e.Path}, BaseDir: basePath}, nil
+
+	case "base":
+		return &tui.Action{Type: tui.ActionCDBase, Path: basePath, BaseDir: basePath}, nil
+
+	case "cancel":
+		return &tui.Action{Type: tui.ActionCancel}, nil
+	}
+	return nil, fmt.Errorf("unknown %s %q (valid: select, create, create-undated, clone, delete, base, cancel)", testActionEnv, spec)
+}
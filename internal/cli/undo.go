@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tobi/try/internal/workspace"
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Restore the most recently deleted workspace",
+	Long: `Restore the most recently deleted workspace from the trash and cd into it.
+
+Deleted workspaces are kept in <path>/.trash for trash_retention_days
+(default 7) before being purged.`,
+	Args: cobra.NoArgs,
+	RunE: runUndo,
+}
+
+func init() {
+	queryFallback(undoCmd)
+	execCmd.AddCommand(undoCmd)
+}
+
+func runUndo(cmd *cobra.Command, args []string) error {
+	basePath := getTriesPath()
+	purgeTrash(basePath)
+
+	path, err := workspace.Restore(basePath)
+	if errors.Is(err, workspace.ErrTrashEmpty) {
+		return fmt.Errorf("nothing to undo: %w", err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to restore: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Restored %s\n", path)
+	fmt.Print(getDialect().CD(path))
+	return nil
+}
+
+// purgeTrash drops trashed workspaces past their retention. It runs on
+// every invocation, so failures are silently retried next time.
+func purgeTrash(basePath string) {
+	_, _ = workspace.PurgeTrash(basePath, config.trashRetention())
+}
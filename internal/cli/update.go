@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tobi/try/internal/update"
+)
+
+// updateCheckTimeout bounds the GitHub API request so a slow or absent
+// network never holds up the shell for long.
+const updateCheckTimeout = 3 * time.Second
+
+// updateCheckInterval is how often the opt-in background check runs.
+const updateCheckInterval = 24 * time.Hour
+
+var updateCheckCmd = &cobra.Command{
+	Use:   "update-check",
+	Short: "Check GitHub for a newer release",
+	Long: `Ask the GitHub releases API for the latest release of try and compare it
+to this build's version.
+
+try never contacts the network on its own. To have the selector check at
+most once a day and mention new releases on stderr, set
+
+  update_check = true
+
+in the config file. Setting TRY_NO_UPDATE_CHECK disables both this command
+and the periodic check.`,
+	Args: cobra.NoArgs,
+	RunE: runUpdateCheck,
+}
+
+func init() {
+	rootCmd.AddCommand(updateCheckCmd)
+}
+
+func runUpdateCheck(cmd *cobra.Command, args []string) error {
+	if updateCheckDisabled() {
+		fmt.Fprintln(os.Stderr, "Update checks are disabled by TRY_NO_UPDATE_CHECK.")
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), updateCheckTimeout)
+	defer cancel()
+
+	rel, err := update.Latest(ctx, http.DefaultClient, update.Repo)
+	if err != nil {
+		// Being offline isn't worth a failing exit status
+		fmt.Fprintf(os.Stderr, "warning: could not check for updates: %v\n", err)
+		return nil
+	}
+
+	newer, ok := update.Newer(rel.Tag, Version)
+	switch {
+	case !ok:
+		fmt.Printf("Latest release is %s; this is a development build (%s).\n", rel.Tag, Version)
+		fmt.Println(rel.URL)
+	case newer:
+		fmt.Printf("Update available: %s (you have %s)\n", rel.Tag, Version)
+		fmt.Println(rel.URL)
+	default:
+		fmt.Printf("try %s is up to date.\n", Version)
+	}
+	return nil
+}
+
+// updateCheckDisabled reports whether TRY_NO_UPDATE_CHECK is set.
+func updateCheckDisabled() bool {
+	return os.Getenv("TRY_NO_UPDATE_CHECK") != ""
+}
+
+// updateStampPath returns the file whose mtime records the last periodic
+// check.
+func updateStampPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "try", "last-update-check")
+}
+
+// startUpdateCheck begins the opt-in periodic check in the background if
+// it is enabled and due. The returned function prints a notice to w if a
+// newer release was found by then; it never waits for the request.
+func startUpdateCheck() func(w io.Writer) {
+	nop := func(io.Writer) {}
+	if !config.UpdateCheck || updateCheckDisabled() || Version == "dev" {
+		return nop
+	}
+
+	stamp := updateStampPath()
+	if stamp == "" {
+		return nop
+	}
+	if info, err := os.Stat(stamp); err == nil && time.Since(info.ModTime()) < updateCheckInterval {
+		return nop
+	}
+
+	// Record the attempt up front so an offline machine doesn't retry on
+	// every run
+	if err := os.MkdirAll(filepath.Dir(stamp), 0755); err != nil {
+		return nop
+	}
+	if err := os.WriteFile(stamp, nil, 0644); err != nil {
+		return nop
+	}
+
+	found := make(chan update.Release, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), updateCheckTimeout)
+		defer cancel()
+		rel, err := update.Latest(ctx, http.DefaultClient, update.Repo)
+		if err != nil {
+			return
+		}
+		if newer, ok := update.Newer(rel.Tag, Version); ok && newer {
+			found <- rel
+		}
+	}()
+
+	return func(w io.Writer) {
+		select {
+		case rel := <-found:
+			fmt.Fprintf(w, "try %s is available (you have %s): %s\n", rel.Tag, Version, rel.URL)
+		default:
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tobi/try/trycore"
+)
+
+var whichAll bool
+
+var whichCmd = &cobra.Command{
+	Use:   "which <query>",
+	Short: "Print the path of the workspace matching a query",
+	Long: `Resolve a query to a workspace and print its absolute path.
+
+The query is matched like in the selector's filter: fuzzily by name,
+then by tag, then by note. A workspace whose name, or name without the
+date prefix, equals the query wins outright; otherwise the best match is
+printed if it is unambiguous. With --all, every match is printed, best
+first, in the same order.
+
+Unlike selecting a workspace, this never touches its mtime and never
+emits shell code, so it is safe in substitutions:
+
+  ls "$(go-try which redis)"`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runWhich,
+}
+
+func init() {
+	whichCmd.Flags().BoolVar(&whichAll, "all", false, "print every match, best first")
+	rootCmd.AddCommand(whichCmd)
+}
+
+func runWhich(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	tries := triesDir()
+
+	// Words are joined like names are: 'which redis test' finds redis-test
+	query := strings.Join(args, "-")
+	if whichAll {
+		matches, err := tries.Match(query)
+		if err != nil {
+			return err
+		}
+		if len(matches) == 0 {
+			return fmt.Errorf("%w: %q", trycore.ErrNoMatch, query)
+		}
+		for _, ws := range matches {
+			fmt.Println(ws.Path)
+		}
+		return nil
+	}
+
+	ws, err := tries.Resolve(query)
+	if err != nil {
+		return err
+	}
+	fmt.Println(ws.Path)
+	return nil
+}
@@ -0,0 +1,215 @@
+// Package fuzzy ranks workspaces against a query. The picker's filter and
+// the CLI commands (which, run, list --filter and the plain selector) all
+// go through it, so they find and order workspaces the same way.
+package fuzzy
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sahilm/fuzzy"
+	"github.com/tobi/try/internal/workspace"
+)
+
+// LabelBoost is added to the score of a match on a name's label, so that
+// "foo" ranks 2024-01-15-foo above names that only match across the date.
+const LabelBoost = 1000
+
+// NameMatch is a name that matched a fuzzy query.
+type NameMatch struct {
+	Index   int   // index into the names passed to MatchNames
+	Score   int   // higher is better
+	Matched []int // byte indexes of the matched characters in the name
+	Field   Field // what matched; MatchNames only matches FieldName
+}
+
+// Field says which part of a Target a query matched.
+type Field string
+
+// Fields in the order their matches rank.
+const (
+	FieldName Field = "name"
+	FieldTag  Field = "tag"
+	FieldNote Field = "note"
+)
+
+// Target is a workspace name together with other text it can be found by.
+type Target struct {
+	Name string
+	Tags []string
+	Note string
+}
+
+// nameSeparators join the words of workspace names. Queries may use any of
+// them, or none, between words.
+const nameSeparators = "-_ "
+
+// stripSeparators removes nameSeparators from s.
+func stripSeparators(s string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(nameSeparators, r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// MatchNames fuzzy-matches query against each name's label (the part after
+// the YYYY-MM-DD- date prefix) as well as the full name, preferring label
+// matches, and returns the matches best first. Ties keep the order of
+// names. Matched indexes always point into the full name.
+//
+// Matching ignores case, and separators in the query are dropped, so
+// "myproject", "my project" and "my_project" all find my-project.
+func MatchNames(query string, names []string) []NameMatch {
+	if q := stripSeparators(query); q != "" {
+		query = q
+	}
+
+	labels := make([]string, len(names))
+	offsets := make([]int, len(names))
+	for i, n := range names {
+		_, label, _ := workspace.ParseName(filepath.Base(n))
+		labels[i] = label
+		offsets[i] = len(n) - len(label)
+	}
+
+	best := map[int]NameMatch{}
+	for _, m := range fuzzy.Find(query, names) {
+		best[m.Index] = NameMatch{Index: m.Index, Score: m.Score, Matched: m.MatchedIndexes, Field: FieldName}
+	}
+	for _, m := range fuzzy.Find(query, labels) {
+		score := m.Score + LabelBoost
+		if prev, ok := best[m.Index]; ok && prev.Score >= score {
+			continue
+		}
+		matched := make([]int, len(m.MatchedIndexes))
+		for i, idx := range m.MatchedIndexes {
+			matched[i] = idx + offsets[m.Index]
+		}
+		best[m.Index] = NameMatch{Index: m.Index, Score: score, Matched: matched, Field: FieldName}
+	}
+
+	result := make([]NameMatch, 0, len(best))
+	for _, m := range best {
+		result = append(result, m)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Score != result[j].Score {
+			return result[i].Score > result[j].Score
+		}
+		return result[i].Index < result[j].Index
+	})
+	return result
+}
+
+// MatchTargets matches query against each target's name like MatchNames,
+// then finds the remaining targets with a tag containing query (a leading
+// # is ignored), then those whose note contains query, ignoring case. Name
+// matches come first, best first; tag and note matches follow in target
+// order, with Matched empty since nothing in the name matched.
+func MatchTargets(query string, targets []Target) []NameMatch {
+	names := make([]string, len(targets))
+	for i, t := range targets {
+		names[i] = t.Name
+	}
+	result := MatchNames(query, names)
+
+	q := strings.ToLower(strings.TrimSpace(query))
+	if q == "" {
+		return result
+	}
+	seen := make(map[int]bool, len(result))
+	for _, m := range result {
+		seen[m.Index] = true
+	}
+
+	tag := workspace.NormalizeTag(q)
+	for i, t := range targets {
+		if seen[i] || tag == "" {
+			continue
+		}
+		for _, tt := range t.Tags {
+			if strings.Contains(tt, tag) {
+				result = append(result, NameMatch{Index: i, Field: FieldTag})
+				seen[i] = true
+				break
+			}
+		}
+	}
+	for i, t := range targets {
+		if !seen[i] && strings.Contains(strings.ToLower(t.Note), q) {
+			result = append(result, NameMatch{Index: i, Field: FieldNote})
+		}
+	}
+	return result
+}
+
+// ErrNoMatch is returned by BestMatch when nothing matches the query.
+var ErrNoMatch = errors.New("no matching workspace")
+
+// MatchEntries returns the entries whose names match query, best first:
+// Rank without tags or notes.
+func MatchEntries(query string, entries []workspace.Entry) []workspace.Entry {
+	return Rank(entries, query, nil, nil)
+}
+
+// Rank returns the entries matching query, best first, exactly as the
+// picker's filter ranks them: see MatchTargets. notes and tags are keyed by
+// entry name, as workspace.LoadNotes and LoadTags return them; either may be nil.
+func Rank(entries []workspace.Entry, query string, notes map[string]string, tags map[string][]string) []workspace.Entry {
+	var result []workspace.Entry
+	for _, m := range MatchTargets(query, targets(entries, notes, tags)) {
+		result = append(result, entries[m.Index])
+	}
+	return result
+}
+
+// targets pairs each entry's name with its tags and note.
+func targets(entries []workspace.Entry, notes map[string]string, tags map[string][]string) []Target {
+	targets := make([]Target, len(entries))
+	for i, e := range entries {
+		targets[i] = Target{Name: e.Name, Tags: tags[e.Name], Note: notes[e.Name]}
+	}
+	return targets
+}
+
+// BestMatch resolves query to a single entry. A name or label equal to
+// query, ignoring case and separators, wins outright (the first one, if
+// entries are sorted by recency); otherwise the entries are ranked as Rank
+// ranks them, and the first is used unless the runner-up matched the same
+// field with the same score. notes and tags are as for Rank. Fails with
+// ErrNoMatch, or with an error listing the candidates when the query is
+// ambiguous.
+func BestMatch(query string, entries []workspace.Entry, notes map[string]string, tags map[string][]string) (workspace.Entry, error) {
+	q := stripSeparators(query)
+	for _, e := range entries {
+		_, label, _ := workspace.ParseName(filepath.Base(e.Name))
+		if strings.EqualFold(e.Name, query) || (q != "" && strings.EqualFold(stripSeparators(label), q)) {
+			return e, nil
+		}
+	}
+
+	matches := MatchTargets(query, targets(entries, notes, tags))
+	if len(matches) == 0 {
+		return workspace.Entry{}, fmt.Errorf("%w: %q", ErrNoMatch, query)
+	}
+	tied := func(m NameMatch) bool {
+		return m.Field == matches[0].Field && m.Score == matches[0].Score
+	}
+	if len(matches) == 1 || !tied(matches[1]) {
+		return entries[matches[0].Index], nil
+	}
+
+	var candidates []string
+	for _, m := range matches {
+		if !tied(m) {
+			break
+		}
+		candidates = append(candidates, entries[m.Index].Name)
+	}
+	return workspace.Entry{}, fmt.Errorf("%q is ambiguous: %s", query, strings.Join(candidates, ", "))
+}
@@ -0,0 +1,261 @@
+package fuzzy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sahilm/fuzzy"
+	"github.com/tobi/try/internal/workspace"
+)
+
+func TestMatchNamesIgnoresDatePrefix(t *testing.T) {
+	targets := []string{
+		"2024-01-15-bar",
+		"2024-01-15-xfxoxo",
+		"2024-01-15-foo",
+		"foo",
+	}
+
+	scores := MatchNames("foo", targets)
+	if len(scores) != 3 {
+		t.Fatalf("expected 3 matches, got %+v", scores)
+	}
+
+	byName := map[string]NameMatch{}
+	for _, s := range scores {
+		byName[targets[s.Index]] = s
+	}
+
+	dated := byName["2024-01-15-foo"]
+	if dated.Score < LabelBoost {
+		t.Errorf("label match score %d should include the label boost", dated.Score)
+	}
+	// The date prefix costs nothing: the dated name scores like an undated one
+	if dated.Score != byName["foo"].Score {
+		t.Errorf("2024-01-15-foo scored %d, foo scored %d", dated.Score, byName["foo"].Score)
+	}
+	if dated.Score <= byName["2024-01-15-xfxoxo"].Score {
+		t.Errorf("contiguous label match should outrank a scattered one")
+	}
+
+	// Without the label handling the prefix drags the score down
+	raw := fuzzy.Find("foo", []string{"2024-01-15-foo"})
+	if len(raw) != 1 || raw[0].Score >= dated.Score {
+		t.Errorf("label score %d should beat raw full-name score %+v", dated.Score, raw)
+	}
+
+	// Matched indexes point into the full name, past the date
+	for _, idx := range dated.Matched {
+		if idx < len("2024-01-15-") {
+			t.Errorf("matched index %d falls in the date prefix", idx)
+		}
+	}
+}
+
+func TestMatchNamesMatchesDate(t *testing.T) {
+	matches := MatchNames("2024-02", []string{"2024-01-15-foo", "2024-02-01-bar", "undated"})
+	if len(matches) == 0 || matches[0].Index != 1 {
+		t.Errorf("date search should still match full names, got %+v", matches)
+	}
+}
+
+func TestMatchNamesIgnoresSeparators(t *testing.T) {
+	names := []string{"2024-01-15-my-project", "2024-01-16-other", "2024-01-17-MyOtherProject"}
+
+	for _, query := range []string{"myproject", "my project", "my_project", "my-project", "MyProject", "MY PROJECT"} {
+		matches := MatchNames(query, names)
+		if len(matches) == 0 || matches[0].Index != 0 {
+			t.Errorf("%q should find my-project first, got %+v", query, matches)
+			continue
+		}
+		for _, idx := range matches[0].Matched {
+			if c := names[0][idx]; c == '-' {
+				t.Errorf("%q: matched index %d is a separator", query, idx)
+			}
+		}
+	}
+
+	// A query of only separators still matches literally
+	if len(MatchNames("-", []string{"a-b"})) != 1 {
+		t.Error("a lone separator should match names containing it")
+	}
+
+	e, err := BestMatch("my project", []workspace.Entry{{Name: "2024-01-15-my-project-two"}, {Name: "2024-01-15-my_project"}}, nil, nil)
+	if err != nil || e.Name != "2024-01-15-my_project" {
+		t.Errorf("BestMatch should treat separators alike, got %v, %v", e.Name, err)
+	}
+}
+
+func TestBestMatch(t *testing.T) {
+	entries := []workspace.Entry{
+		{Name: "2025-01-19-redis-test"},
+		{Name: "2025-01-18-redis"},
+		{Name: "2025-01-17-python"},
+		{Name: "2025-01-16-api-old"},
+		{Name: "2025-01-15-api-new"},
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"redis", "2025-01-18-redis"},              // exact label beats a newer fuzzy match
+		{"2025-01-17-python", "2025-01-17-python"}, // exact full name
+		{"pyth", "2025-01-17-python"},              // single fuzzy match
+		{"REDIS", "2025-01-18-redis"},              // case-insensitive
+	}
+	for _, tt := range tests {
+		got, err := BestMatch(tt.query, entries, nil, nil)
+		if err != nil {
+			t.Errorf("BestMatch(%q): %v", tt.query, err)
+			continue
+		}
+		if got.Name != tt.want {
+			t.Errorf("BestMatch(%q) = %s, want %s", tt.query, got.Name, tt.want)
+		}
+	}
+
+	if _, err := BestMatch("zzz", entries, nil, nil); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("expected ErrNoMatch, got %v", err)
+	}
+	if _, err := BestMatch("api", entries, nil, nil); err == nil || errors.Is(err, ErrNoMatch) {
+		t.Errorf("expected an ambiguity error, got %v", err)
+	}
+}
+
+func TestBestMatchTagsAndNotes(t *testing.T) {
+	entries := []workspace.Entry{
+		{Name: "2025-01-19-redis"},
+		{Name: "2025-01-18-cache"},
+		{Name: "2025-01-17-queue"},
+		{Name: "2025-01-16-spike"},
+	}
+	notes := map[string]string{"2025-01-18-cache": "LRU in front of postgres", "2025-01-17-queue": "also postgres"}
+	tags := map[string][]string{"2025-01-16-spike": {"work"}, "2025-01-19-redis": {"work-old"}}
+
+	// Ranked like Rank: by tag, then by note, when no name matches
+	for query, want := range map[string]string{
+		"#old":  "2025-01-19-redis",
+		"lru":   "2025-01-18-cache",
+		"spike": "2025-01-16-spike",
+	} {
+		if got, err := BestMatch(query, entries, notes, tags); err != nil || got.Name != want {
+			t.Errorf("BestMatch(%q) = %s, %v; want %s", query, got.Name, err, want)
+		}
+		if got := Rank(entries, query, notes, tags); len(got) == 0 || got[0].Name != want {
+			t.Errorf("Rank(%q) should put %s first, got %v", query, want, got)
+		}
+	}
+
+	// Two notes or two tags match equally well
+	if _, err := BestMatch("postgres", entries, notes, tags); err == nil || errors.Is(err, ErrNoMatch) {
+		t.Errorf("expected an ambiguity error for two notes, got %v", err)
+	}
+	if _, err := BestMatch("#work", entries, notes, tags); err == nil || errors.Is(err, ErrNoMatch) {
+		t.Errorf("expected an ambiguity error for two tags, got %v", err)
+	}
+}
+
+func TestMatchEntries(t *testing.T) {
+	entries := []workspace.Entry{
+		{Name: "2025-01-19-redis-test"},
+		{Name: "2025-01-17-python"},
+		{Name: "2025-01-18-redis"},
+	}
+
+	got := MatchEntries("redis", entries)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 matches, got %+v", got)
+	}
+	for _, e := range got {
+		if e.Name == "2025-01-17-python" {
+			t.Error("python should not match")
+		}
+	}
+}
+
+func TestMatchTargets(t *testing.T) {
+	targets := []Target{
+		{Name: "2024-01-15-api", Note: "port 8080 demo"},
+		{Name: "2024-01-16-web-8080"},
+		{Name: "2024-01-17-cli", Note: "PORT 8080 too"},
+		{Name: "2024-01-18-db", Note: "nothing here"},
+	}
+
+	matches := MatchTargets("8080", targets)
+	if len(matches) != 3 {
+		t.Fatalf("expected 3 matches, got %+v", matches)
+	}
+
+	// The name match ranks first, then notes in order
+	want := []struct {
+		index int
+		field Field
+	}{{1, FieldName}, {0, FieldNote}, {2, FieldNote}}
+	for i, w := range want {
+		if matches[i].Index != w.index || matches[i].Field != w.field {
+			t.Errorf("match %d = %+v, want index %d field %s", i, matches[i], w.index, w.field)
+		}
+	}
+	if len(matches[1].Matched) != 0 {
+		t.Errorf("note match should not highlight the name, got %v", matches[1].Matched)
+	}
+
+	// A target matching by name isn't repeated for its note
+	if got := MatchTargets("api", targets); len(got) != 1 || got[0].Field != FieldName {
+		t.Errorf("expected one name match, got %+v", got)
+	}
+}
+
+func TestMatchTargetsTags(t *testing.T) {
+	targets := []Target{
+		{Name: "2024-01-15-api", Note: "work in progress"},
+		{Name: "2024-01-16-web", Tags: []string{"spike", "work"}},
+		{Name: "2024-01-17-workbench"},
+	}
+
+	// With a #, only tags match
+	matches := MatchTargets("#work", targets)
+	if len(matches) != 1 || matches[0].Index != 1 || matches[0].Field != FieldTag {
+		t.Fatalf("expected just the tagged workspace, got %+v", matches)
+	}
+
+	// Without it, the name ranks above the tag, and the tag above the note
+	matches = MatchTargets("work", targets)
+	want := []struct {
+		index int
+		field Field
+	}{{2, FieldName}, {1, FieldTag}, {0, FieldNote}}
+	if len(matches) != len(want) {
+		t.Fatalf("expected %d matches, got %+v", len(want), matches)
+	}
+	for i, w := range want {
+		if matches[i].Index != w.index || matches[i].Field != w.field {
+			t.Errorf("match %d = %+v, want index %d field %s", i, matches[i], w.index, w.field)
+		}
+	}
+}
+
+func TestRank(t *testing.T) {
+	entries := []workspace.Entry{{Name: "2025-01-19-cache"}, {Name: "2025-01-18-redis"}, {Name: "dotfiles"}}
+	notes := map[string]string{"2025-01-19-cache": "redis as an LRU"}
+	tags := map[string][]string{"dotfiles": {"redis-conf"}}
+
+	var got []string
+	for _, e := range Rank(entries, "redis", notes, tags) {
+		got = append(got, e.Name)
+	}
+	want := []string{"2025-01-18-redis", "dotfiles", "2025-01-19-cache"}
+	if len(got) != len(want) {
+		t.Fatalf("got %q, want name, then tag, then note matches: %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %q, want name, then tag, then note matches: %q", got, want)
+		}
+	}
+
+	if got := Rank(entries, "redis", nil, nil); len(got) != 1 {
+		t.Errorf("without tags or notes only names match, got %+v", got)
+	}
+}
@@ -2,21 +2,69 @@
 package shell
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"path/filepath"
 	"strings"
 )
 
 const scriptWarning = "# if you can read this, you didn't launch try from an alias. run try --help."
 
+// Dialect selects the shell syntax a Script renders to.
+type Dialect int
+
+const (
+	// POSIX is sh syntax, which bash, zsh and fish all evaluate.
+	POSIX Dialect = iota
+	// Elvish is syntax for the elvish shell.
+	Elvish
+	// Csh is syntax for tcsh and csh.
+	Csh
+	// ProtocolV1 is the structured exec protocol: one action per line as
+	// tab-separated fields (e.g. "CD\t/path"), parsed by the shell wrapper
+	// instead of being eval'd.
+	ProtocolV1
+)
+
+// DialectFor returns the dialect to emit for the named shell.
+func DialectFor(shellName string) Dialect {
+	switch shellName {
+	case "elvish":
+		return Elvish
+	case "tcsh", "csh":
+		return Csh
+	default:
+		return POSIX
+	}
+}
+
 // quote escapes a string for safe use in shell scripts.
 func quote(s string) string {
 	// Use single quotes, escaping any embedded single quotes
 	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
 }
 
+// quote escapes a string for safe use in the dialect's scripts.
+func (d Dialect) quote(s string) string {
+	switch d {
+	case Elvish:
+		// Elvish single-quoted strings escape a quote by doubling it
+		return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+	case Csh:
+		// csh has no escapes inside single quotes, and history
+		// expansion still applies to '!' there
+		s = strings.ReplaceAll(s, "'", `'\''`)
+		return "'" + strings.ReplaceAll(s, "!", `'\!'`) + "'"
+	default:
+		return quote(s)
+	}
+}
+
 // Script represents a series of shell commands to execute.
 type Script struct {
 	commands []string
+	dialect  Dialect
 }
 
 // New creates a new empty script.
@@ -24,6 +72,11 @@ func New() *Script {
 	return &Script{}
 }
 
+// NewFor creates a new empty script rendered in the given dialect.
+func NewFor(d Dialect) *Script {
+	return &Script{dialect: d}
+}
+
 // Add appends a command to the script.
 func (s *Script) Add(cmd string) *Script {
 	s.commands = append(s.commands, cmd)
@@ -32,46 +85,133 @@ func (s *Script) Add(cmd string) *Script {
 
 // AddCD adds a cd command.
 func (s *Script) AddCD(path string) *Script {
-	return s.Add(fmt.Sprintf("cd %s", quote(path)))
+	return s.addCommand("CD", "cd", path)
 }
 
 // AddMkdir adds a mkdir command.
 func (s *Script) AddMkdir(path string) *Script {
-	return s.Add(fmt.Sprintf("mkdir -p %s", quote(path)))
+	return s.addCommand("MKDIR", "mkdir -p", path)
 }
 
 // AddTouch adds a touch command.
 func (s *Script) AddTouch(path string) *Script {
-	return s.Add(fmt.Sprintf("touch %s", quote(path)))
+	return s.addCommand("TOUCH", "touch", path)
 }
 
 // AddEcho adds an echo command.
 func (s *Script) AddEcho(msg string) *Script {
-	return s.Add(fmt.Sprintf("echo %s", quote(msg)))
+	return s.addCommand("ECHO", "echo", msg)
 }
 
 // AddGitClone adds a git clone command.
 func (s *Script) AddGitClone(url, destPath string) *Script {
-	return s.Add(fmt.Sprintf("git clone %s %s", quote(url), quote(destPath)))
+	return s.addCommand("CLONE", "git clone", url, destPath)
+}
+
+// AddExport adds a command that sets the environment variable name to
+// value.
+func (s *Script) AddExport(name, value string) *Script {
+	switch s.dialect {
+	case ProtocolV1:
+		return s.Add(protocolLine("EXPORT", name, value))
+	case Elvish:
+		return s.Add(fmt.Sprintf("set-env %s %s", name, s.dialect.quote(value)))
+	case Csh:
+		return s.Add(fmt.Sprintf("setenv %s %s", name, s.dialect.quote(value)))
+	}
+	// fish has an export function for sh compatibility
+	return s.Add(fmt.Sprintf("export %s=%s", name, s.dialect.quote(value)))
+}
+
+// exportVar names the variable cd scripts set to the chosen path, or is
+// empty to set none; see SetExportVar.
+var exportVar string
+
+// SetExportVar makes the scripts that cd to a chosen or new workspace also
+// export its path as the environment variable name, such as TRY_LAST, for
+// other tools to read. An empty name turns it off.
+func SetExportVar(name string) {
+	exportVar = name
+}
+
+// addExportPath adds an export of path if SetExportVar asked for one.
+func (s *Script) addExportPath(path string) *Script {
+	if exportVar == "" {
+		return s
+	}
+	return s.AddExport(exportVar, path)
 }
 
 // AddRm adds an rm -rf command with safety wrapper.
 func (s *Script) AddRm(path, basePath string) *Script {
+	switch s.dialect {
+	case ProtocolV1:
+		return s.Add(protocolLine("RM", path))
+	case Elvish:
+		// Elvish has no && chaining; rm -rf is a no-op for missing paths
+		return s.Add(fmt.Sprintf("rm -rf %s", s.dialect.quote(path)))
+	}
+
 	// Safety: validate path is inside basePath before deleting
-	cmd := fmt.Sprintf("test -d %s && rm -rf %s", quote(path), quote(path))
+	cmd := fmt.Sprintf("test -d %s && rm -rf %s", s.dialect.quote(path), s.dialect.quote(path))
 	return s.Add(cmd)
 }
 
+// addCommand appends program with its quoted args, or the equivalent
+// verb line when rendering the exec protocol.
+func (s *Script) addCommand(verb, program string, args ...string) *Script {
+	if s.dialect == ProtocolV1 {
+		return s.Add(protocolLine(verb, args...))
+	}
+
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = s.dialect.quote(a)
+	}
+	return s.Add(program + " " + strings.Join(quoted, " "))
+}
+
+// protocolLine renders a tab-separated protocol line. Fields can't carry
+// tabs or newlines, so those are replaced with spaces; a mangled path then
+// fails to resolve instead of being misparsed.
+func protocolLine(verb string, args ...string) string {
+	fields := []string{verb}
+	for _, a := range args {
+		fields = append(fields, strings.NewReplacer("\t", " ", "\n", " ", "\r", " ").Replace(a))
+	}
+	return strings.Join(fields, "\t")
+}
+
 // String renders the script as a shell-evaluable string.
 func (s *Script) String() string {
 	if len(s.commands) == 0 {
 		return ""
 	}
 
+	if s.dialect == ProtocolV1 {
+		return strings.Join(s.commands, "\n") + "\n"
+	}
+
+	if s.dialect == Csh {
+		// The csh alias captures the script with backticks and evals it
+		// as a single line, where a leading # is not a comment
+		return strings.Join(s.commands, " && ") + "\n"
+	}
+
 	var sb strings.Builder
 	sb.WriteString(scriptWarning)
 	sb.WriteString("\n")
 
+	if s.dialect == Elvish {
+		// Elvish aborts on the first failing command, so one command per
+		// line behaves like a && chain
+		for _, cmd := range s.commands {
+			sb.WriteString(cmd)
+			sb.WriteString("\n")
+		}
+		return sb.String()
+	}
+
 	for i, cmd := range s.commands {
 		if i == 0 {
 			sb.WriteString(cmd)
@@ -92,79 +232,311 @@ func (s *Script) String() string {
 
 // CD creates a script that touches and cd's to a directory.
 func CD(path string) string {
-	return New().
+	return POSIX.CD(path)
+}
+
+// MkdirCD creates a script that creates a directory and cd's to it.
+func MkdirCD(path string) string {
+	return POSIX.MkdirCD(path)
+}
+
+// Clone creates a script that clones a repo and cd's to it.
+func Clone(path, url string) string {
+	return POSIX.Clone(path, url)
+}
+
+// Delete creates a script that deletes directories.
+func Delete(paths []string, basePath string) string {
+	return POSIX.Delete(paths, basePath)
+}
+
+// CD creates a script in dialect d that touches and cd's to a directory.
+func (d Dialect) CD(path string) string {
+	return NewFor(d).
 		AddTouch(path).
 		AddEcho(path).
 		AddCD(path).
+		addExportPath(path).
 		String()
 }
 
-// MkdirCD creates a script that creates a directory and cd's to it.
-func MkdirCD(path string) string {
-	return New().
+// CDFile creates a script in dialect d that touches a file and cd's to the
+// directory it is in.
+func (d Dialect) CDFile(path string) string {
+	return NewFor(d).
+		AddTouch(path).
+		AddEcho(path).
+		AddCD(filepath.Dir(path)).
+		addExportPath(path).
+		String()
+}
+
+// MkdirCD creates a script in dialect d that creates a directory and cd's to it.
+func (d Dialect) MkdirCD(path string) string {
+	return NewFor(d).
 		AddMkdir(path).
 		AddTouch(path).
 		AddEcho(path).
 		AddCD(path).
+		addExportPath(path).
 		String()
 }
 
-// Clone creates a script that clones a repo and cd's to it.
-func Clone(path, url string) string {
-	return New().
+// Clone creates a script in dialect d that clones a repo and cd's to it.
+func (d Dialect) Clone(path, url string) string {
+	return NewFor(d).
 		AddMkdir(path).
 		AddEcho(fmt.Sprintf("Cloning %s...", url)).
 		AddGitClone(url, path).
 		AddTouch(path).
 		AddEcho(path).
 		AddCD(path).
+		addExportPath(path).
 		String()
 }
 
-// Delete creates a script that deletes directories.
-func Delete(paths []string, basePath string) string {
-	s := New().AddCD(basePath)
+// Delete creates a script in dialect d that deletes directories.
+func (d Dialect) Delete(paths []string, basePath string) string {
+	s := NewFor(d).AddCD(basePath)
 	for _, p := range paths {
 		s.AddRm(p, basePath)
 	}
-	// Try to stay in current dir, or go home if deleted
-	s.Add(`( cd "$PWD" 2>/dev/null || cd "$HOME" )`)
+	if d == POSIX {
+		// Try to stay in current dir, or go home if deleted
+		s.Add(`( cd "$PWD" 2>/dev/null || cd "$HOME" )`)
+	}
 	return s.String()
 }
 
-// InitBash returns the bash/zsh shell function definition.
-func InitBash(scriptPath, triesPath string) string {
+// Export creates a script that sets the environment variable name to
+// value.
+func Export(name, value string) string {
+	return POSIX.Export(name, value)
+}
+
+// Export creates a script in dialect d that sets the environment variable
+// name to value.
+func (d Dialect) Export(name, value string) string {
+	return NewFor(d).AddExport(name, value).String()
+}
+
+// runScript is what sh runs for Run, given the directory and then the
+// command as its arguments.
+const runScript = `cd "$1" && shift && exec "$@"`
+
+// Run creates a script in dialect d that runs argv in the directory path
+// and leaves the shell where it was. The command runs under sh, which
+// every dialect can start the same way, whatever its own syntax for
+// subshells; fish, for one, evals POSIX scripts without having ( ).
+// ProtocolV1 has no equivalent.
+func (d Dialect) Run(path string, argv []string) string {
+	args := append([]string{runScript, "sh", path}, argv...)
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = d.quote(a)
+	}
+	return NewFor(d).Add("sh -c " + strings.Join(quoted, " ")).String()
+}
+
+// InitBash returns the bash/zsh shell function definition. Like the other
+// Init functions, it records version in a comment and passes it to exec as
+// WrapperVersionEnv.
+func InitBash(scriptPath, triesPath, version string) string {
 	pathArg := ""
 	if triesPath != "" {
 		pathArg = fmt.Sprintf(" --path %s", quote(triesPath))
 	}
 
 	return fmt.Sprintf(`try() {
+  # try wrapper %s
   local out
-  out=$(/usr/bin/env %s exec%s "$@" 2>/dev/tty)
+  out=$(/usr/bin/env %s %s exec%s "$@" 2>/dev/tty)
   if [ $? -eq 0 ]; then
     eval "$out"
   else
     echo "$out"
   fi
 }
-`, quote(scriptPath), pathArg)
+`, version, versionEnv(version), quote(scriptPath), pathArg)
 }
 
 // InitFish returns the fish shell function definition.
-func InitFish(scriptPath, triesPath string) string {
+func InitFish(scriptPath, triesPath, version string) string {
 	pathArg := ""
 	if triesPath != "" {
 		pathArg = fmt.Sprintf(" --path %s", quote(triesPath))
 	}
 
 	return fmt.Sprintf(`function try
-  set -l out (/usr/bin/env %s exec%s $argv 2>/dev/tty | string collect)
+  # try wrapper %s
+  set -l out (/usr/bin/env %s %s exec%s $argv 2>/dev/tty | string collect)
   if test $status -eq 0
     eval $out
   else
     echo $out
   end
 end
-`, quote(scriptPath), pathArg)
+`, version, versionEnv(version), quote(scriptPath), pathArg)
+}
+
+// InitBashProtocol returns the bash/zsh shell function definition for
+// exec protocol v1. Instead of eval'ing exec's output, the function reads
+// one action per line and runs the matching command itself.
+func InitBashProtocol(scriptPath, triesPath, version string) string {
+	pathArg := ""
+	if triesPath != "" {
+		pathArg = fmt.Sprintf(" --path %s", quote(triesPath))
+	}
+
+	return fmt.Sprintf(`try() {
+  # try wrapper %s
+  local out verb arg1 arg2
+  out=$(/usr/bin/env %s %s exec --protocol v1%s "$@" 2>/dev/tty)
+  if [ $? -ne 0 ]; then
+    echo "$out"
+    return 1
+  fi
+  while IFS=$'\t' read -r verb arg1 arg2; do
+    case "$verb" in
+      CD) cd "$arg1" || return ;;
+      MKDIR) mkdir -p "$arg1" || return ;;
+      TOUCH) touch "$arg1" ;;
+      ECHO) echo "$arg1" ;;
+      CLONE) git clone "$arg1" "$arg2" || return ;;
+      RM) [ -d "$arg1" ] && rm -rf "$arg1" ;;
+      EXPORT) export "$arg1=$arg2" ;;
+    esac
+  done <<< "$out"
+}
+`, version, versionEnv(version), quote(scriptPath), pathArg)
+}
+
+// InitFishProtocol returns the fish shell function definition for exec
+// protocol v1.
+func InitFishProtocol(scriptPath, triesPath, version string) string {
+	pathArg := ""
+	if triesPath != "" {
+		pathArg = fmt.Sprintf(" --path %s", quote(triesPath))
+	}
+
+	return fmt.Sprintf(`function try
+  # try wrapper %s
+  set -l out (/usr/bin/env %s %s exec --protocol v1%s $argv 2>/dev/tty)
+  if test $status -ne 0
+    string join \n -- $out
+    return 1
+  end
+  for line in $out
+    set -l f (string split \t -- $line)
+    switch $f[1]
+      case CD
+        cd $f[2]; or return
+      case MKDIR
+        mkdir -p $f[2]; or return
+      case TOUCH
+        touch $f[2]
+      case ECHO
+        echo $f[2]
+      case CLONE
+        git clone $f[2] $f[3]; or return
+      case RM
+        test -d $f[2]; and rm -rf $f[2]
+      case EXPORT
+        set -gx $f[2] $f[3]
+    end
+  end
+end
+`, version, versionEnv(version), quote(scriptPath), pathArg)
+}
+
+// InitElvish returns the elvish function definition.
+//
+// Elvish reserves "try" for its exception-handling special form, so the
+// function is named tryit. The exec output is rendered in elvish syntax
+// (--shell elvish) and evaluated natively rather than as a POSIX script.
+func InitElvish(scriptPath, triesPath, version string) string {
+	pathArg := ""
+	if triesPath != "" {
+		pathArg = fmt.Sprintf(" --path %s", Elvish.quote(triesPath))
+	}
+
+	return fmt.Sprintf(`fn tryit {|@args|
+  # try wrapper %s
+  var out = ''
+  try {
+    set out = (/usr/bin/env %s %s exec --shell elvish%s $@args 2>/dev/tty | slurp)
+  } catch {
+    return
+  }
+  eval $out
+}
+`, version, Elvish.quote(WrapperVersionEnv+"="+version), Elvish.quote(scriptPath), pathArg)
+}
+
+// InitTcsh returns the tcsh/csh alias definition.
+//
+// csh can't define functions and its quoting is too weak to safely embed
+// arbitrary paths, so the binary and tries paths are inserted verbatim and
+// must not contain spaces, quotes or '!'. Workspace paths in the evaluated
+// script are quoted by exec itself (--shell tcsh).
+func InitTcsh(scriptPath, triesPath, version string) string {
+	pathArg := ""
+	if triesPath != "" {
+		pathArg = " --path " + triesPath
+	}
+
+	return fmt.Sprintf(`# try: tcsh/csh integration. Save to a file and source it from ~/.tcshrc.
+# try wrapper %s
+# Limitation: the try binary and tries paths must not contain spaces,
+# quotes or '!' characters, as csh cannot quote them reliably here.
+alias try 'set _try_out = "`+"`"+`/usr/bin/env %s=%s %s exec --shell tcsh%s \!*`+"`"+`"; eval "$_try_out"; unset _try_out'
+`, version, WrapperVersionEnv, version, scriptPath, pathArg)
+}
+
+// WrapperVersionEnv is set by the wrappers for every exec they run, to
+// the version of try that generated them.
+const WrapperVersionEnv = "TRY_WRAPPER_VERSION"
+
+// versionEnv returns the env(1) argument that sets WrapperVersionEnv.
+func versionEnv(version string) string {
+	return quote(WrapperVersionEnv + "=" + version)
+}
+
+// WrapperEnv is the environment variable a loaded wrapper exports with its
+// Fingerprint, so try can tell which wrapper the shell is running.
+const WrapperEnv = "TRY_WRAPPER"
+
+// Fingerprint returns a short hash identifying a generated wrapper.
+func Fingerprint(wrapper string) string {
+	sum := sha256.Sum256([]byte(wrapper))
+	return hex.EncodeToString(sum[:6])
+}
+
+// WithFingerprint appends a line to wrapper that exports its Fingerprint
+// as WrapperEnv, in the syntax of the named shell.
+func WithFingerprint(shellName, wrapper string) string {
+	fp := Fingerprint(wrapper)
+
+	var line string
+	switch shellName {
+	case "fish":
+		line = fmt.Sprintf("set -gx %s %s", WrapperEnv, fp)
+	case "elvish":
+		line = fmt.Sprintf("set-env %s %s", WrapperEnv, fp)
+	case "tcsh", "csh":
+		line = fmt.Sprintf("setenv %s %s", WrapperEnv, fp)
+	default:
+		line = fmt.Sprintf("export %s=%s", WrapperEnv, fp)
+	}
+	return wrapper + line + "\n"
+}
+
+// SourceLine returns the line that loads a wrapper saved at path, for the
+// named shell's config file.
+func SourceLine(shellName, path string) string {
+	p := DialectFor(shellName).quote(path)
+	if shellName == "elvish" {
+		return fmt.Sprintf("eval (slurp < %s)", p)
+	}
+	return "source " + p
 }
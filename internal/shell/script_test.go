@@ -1,6 +1,8 @@
 package shell
 
 import (
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -41,6 +43,17 @@ func TestScriptCD(t *testing.T) {
 	}
 }
 
+func TestScriptCDFile(t *testing.T) {
+	script := POSIX.CDFile("/path/to/scrape.py")
+
+	if !strings.Contains(script, "touch '/path/to/scrape.py'") {
+		t.Error("script should touch the file")
+	}
+	if !strings.Contains(script, "cd '/path/to'") || strings.Contains(script, "cd '/path/to/scrape.py'") {
+		t.Errorf("script should cd to the file's directory, got:\n%s", script)
+	}
+}
+
 func TestScriptMkdirCD(t *testing.T) {
 	script := MkdirCD("/path/to/new")
 
@@ -78,8 +91,43 @@ func TestScriptDelete(t *testing.T) {
 	}
 }
 
+func TestScriptRun(t *testing.T) {
+	argv := []string{"npm", "run", "it's; rm -rf ~"}
+
+	tests := []struct {
+		dialect Dialect
+		want    string
+	}{
+		{POSIX, `sh -c 'cd "$1" && shift && exec "$@"' 'sh' '/path/to/it'"'"'s' 'npm' 'run' 'it'"'"'s; rm -rf ~'`},
+		{Csh, `sh -c 'cd "$1" && shift && exec "$@"' 'sh' '/path/to/it'\''s' 'npm' 'run' 'it'\''s; rm -rf ~'`},
+		{Elvish, `sh -c 'cd "$1" && shift && exec "$@"' 'sh' '/path/to/it''s' 'npm' 'run' 'it''s; rm -rf ~'`},
+	}
+	for _, tt := range tests {
+		if script := tt.dialect.Run("/path/to/it's", argv); !strings.Contains(script, tt.want) {
+			t.Errorf("dialect %d: want %s, got:\n%s", tt.dialect, tt.want, script)
+		}
+	}
+}
+
+func TestScriptRunInShell(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not installed")
+	}
+	dir := t.TempDir()
+	script := POSIX.Run(dir, []string{"sh", "-c", `pwd; printf '%s\n' "$0"`, "it's here"})
+
+	out, err := exec.Command("sh", "-c", script).Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	real, _ := filepath.EvalSymlinks(dir)
+	if got := strings.Split(strings.TrimSpace(string(out)), "\n"); len(got) != 2 || (got[0] != dir && got[0] != real) || got[1] != "it's here" {
+		t.Errorf("expected the command to run in %s with its argument intact, got %q", dir, got)
+	}
+}
+
 func TestInitBash(t *testing.T) {
-	script := InitBash("/usr/local/bin/try", "/home/user/tries")
+	script := InitBash("/usr/local/bin/try", "/home/user/tries", "v1.2.3")
 
 	if !strings.Contains(script, "try()") {
 		t.Error("should define try function")
@@ -93,7 +141,7 @@ func TestInitBash(t *testing.T) {
 }
 
 func TestInitFish(t *testing.T) {
-	script := InitFish("/usr/local/bin/try", "")
+	script := InitFish("/usr/local/bin/try", "", "v1.2.3")
 
 	if !strings.Contains(script, "function try") {
 		t.Error("should define try function")
@@ -103,6 +151,169 @@ func TestInitFish(t *testing.T) {
 	}
 }
 
+func TestInitElvish(t *testing.T) {
+	script := InitElvish("/usr/local/bin/try", "/home/user/it's", "v1.2.3")
+
+	if !strings.Contains(script, "fn tryit") {
+		t.Error("should define tryit function")
+	}
+	if !strings.Contains(script, "--shell elvish") {
+		t.Error("should request elvish output from exec")
+	}
+	if !strings.Contains(script, "eval $out") {
+		t.Error("should eval the output")
+	}
+	if !strings.Contains(script, "'/home/user/it''s'") {
+		t.Error("should quote the path elvish-style")
+	}
+}
+
+func TestElvishScript(t *testing.T) {
+	script := Elvish.CD("/path/to/it's")
+
+	if !strings.Contains(script, "cd '/path/to/it''s'") {
+		t.Errorf("should quote cd path elvish-style, got:\n%s", script)
+	}
+	if strings.Contains(script, "&&") {
+		t.Error("elvish script should not chain with &&")
+	}
+
+	del := Elvish.Delete([]string{"/base/dir1"}, "/base")
+	if strings.Contains(del, "$PWD") || strings.Contains(del, "test -d") {
+		t.Errorf("elvish delete should not contain POSIX syntax, got:\n%s", del)
+	}
+}
+
+func TestInitTcsh(t *testing.T) {
+	script := InitTcsh("/usr/local/bin/try", "/home/user/tries", "v1.2.3")
+
+	if !strings.Contains(script, "alias try") {
+		t.Error("should define try alias")
+	}
+	if !strings.Contains(script, "--shell tcsh --path /home/user/tries \\!*") {
+		t.Errorf("should pass alias args through, got:\n%s", script)
+	}
+	if !strings.Contains(script, "eval") {
+		t.Error("should eval the output")
+	}
+}
+
+func TestCshScript(t *testing.T) {
+	script := Csh.CD("/path/it's here!")
+
+	if strings.Contains(script, "\n#") || strings.HasPrefix(script, "#") {
+		t.Error("csh script should not contain comments")
+	}
+	if strings.Count(script, "\n") != 1 {
+		t.Errorf("csh script should be a single line, got:\n%s", script)
+	}
+	if !strings.Contains(script, `cd '/path/it'\''s here'\!''`) {
+		t.Errorf("should quote cd path csh-style, got:\n%s", script)
+	}
+}
+
+func TestProtocolScript(t *testing.T) {
+	script := ProtocolV1.Clone("/path/to/it's dir", "git@github.com:user/repo.git")
+
+	want := "MKDIR\t/path/to/it's dir\n" +
+		"ECHO\tCloning git@github.com:user/repo.git...\n" +
+		"CLONE\tgit@github.com:user/repo.git\t/path/to/it's dir\n" +
+		"TOUCH\t/path/to/it's dir\n" +
+		"ECHO\t/path/to/it's dir\n" +
+		"CD\t/path/to/it's dir\n"
+	if script != want {
+		t.Errorf("got:\n%s\nwant:\n%s", script, want)
+	}
+
+	del := ProtocolV1.Delete([]string{"/base/dir1"}, "/base")
+	if del != "CD\t/base\nRM\t/base/dir1\n" {
+		t.Errorf("unexpected delete protocol:\n%s", del)
+	}
+}
+
+func TestScriptExport(t *testing.T) {
+	for d, want := range map[Dialect]string{
+		POSIX:      `export TRY_LAST='/path/it'"'"'s'`,
+		Elvish:     `set-env TRY_LAST '/path/it''s'`,
+		Csh:        `setenv TRY_LAST '/path/it'\''s'`,
+		ProtocolV1: "EXPORT\tTRY_LAST\t/path/it's",
+	} {
+		if got := d.Export("TRY_LAST", "/path/it's"); !strings.Contains(got, want) {
+			t.Errorf("dialect %d: got:\n%s\nwant %s", d, got, want)
+		}
+	}
+
+	// Off by default, then exported by every script that lands somewhere
+	if strings.Contains(CD("/path"), "export") {
+		t.Error("cd scripts shouldn't export unless asked to")
+	}
+	SetExportVar("TRY_LAST")
+	t.Cleanup(func() { SetExportVar("") })
+	for name, script := range map[string]string{
+		"cd":      CD("/path"),
+		"cd file": POSIX.CDFile("/path"),
+		"mkdir":   MkdirCD("/path"),
+		"clone":   Clone("/path", "git@github.com:user/repo.git"),
+	} {
+		if !strings.HasSuffix(script, "export TRY_LAST='/path'\n") {
+			t.Errorf("%s should export the path last, got:\n%s", name, script)
+		}
+	}
+	if strings.Contains(POSIX.Delete([]string{"/base/a"}, "/base"), "export") {
+		t.Error("delete scripts shouldn't export")
+	}
+}
+
+func TestProtocolLineStripsSeparators(t *testing.T) {
+	got := protocolLine("CD", "/evil\npath\twith tabs")
+	if got != "CD\t/evil path with tabs" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestInitProtocol(t *testing.T) {
+	for name, script := range map[string]string{
+		"bash": InitBashProtocol("/usr/local/bin/try", "/home/user/tries", "v1.2.3"),
+		"fish": InitFishProtocol("/usr/local/bin/try", "/home/user/tries", "v1.2.3"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if !strings.Contains(script, "exec --protocol v1") {
+				t.Error("should request protocol output from exec")
+			}
+			if strings.Contains(script, "eval") {
+				t.Error("should parse the output instead of eval'ing it")
+			}
+			for _, verb := range []string{"CD", "MKDIR", "TOUCH", "ECHO", "CLONE", "RM", "EXPORT"} {
+				if !strings.Contains(script, verb) {
+					t.Errorf("should handle %s", verb)
+				}
+			}
+		})
+	}
+}
+
+func TestInitVersionMarker(t *testing.T) {
+	const v = "v1.2.3"
+	scripts := map[string]string{
+		"bash":          InitBash("/usr/local/bin/try", "", v),
+		"fish":          InitFish("/usr/local/bin/try", "", v),
+		"bash protocol": InitBashProtocol("/usr/local/bin/try", "", v),
+		"fish protocol": InitFishProtocol("/usr/local/bin/try", "", v),
+		"elvish":        InitElvish("/usr/local/bin/try", "", v),
+		"tcsh":          InitTcsh("/usr/local/bin/try", "", v),
+	}
+	for name, script := range scripts {
+		t.Run(name, func(t *testing.T) {
+			if !strings.Contains(script, "# try wrapper v1.2.3\n") {
+				t.Errorf("should have a version comment, got:\n%s", script)
+			}
+			if !strings.Contains(script, "TRY_WRAPPER_VERSION=v1.2.3") {
+				t.Errorf("should pass the version to exec, got:\n%s", script)
+			}
+		})
+	}
+}
+
 func TestScriptBuilder(t *testing.T) {
 	s := New().
 		AddMkdir("/path").
@@ -115,3 +326,43 @@ func TestScriptBuilder(t *testing.T) {
 		t.Error("commands should be chained with && \\")
 	}
 }
+
+func TestWithFingerprint(t *testing.T) {
+	wrapper := InitBash("/usr/local/bin/try", "", "v1.2.3")
+	fp := Fingerprint(wrapper)
+	if len(fp) != 12 {
+		t.Errorf("fingerprint %q should be 12 hex digits", fp)
+	}
+	if fp == Fingerprint(InitBash("/usr/local/bin/try", "/tmp/tries", "v1.2.3")) {
+		t.Error("different wrappers should have different fingerprints")
+	}
+
+	tests := map[string]string{
+		"bash":   "export TRY_WRAPPER=" + fp + "\n",
+		"fish":   "set -gx TRY_WRAPPER " + fp + "\n",
+		"elvish": "set-env TRY_WRAPPER " + fp + "\n",
+		"tcsh":   "setenv TRY_WRAPPER " + fp + "\n",
+	}
+	for shellName, want := range tests {
+		got := WithFingerprint(shellName, wrapper)
+		if !strings.HasPrefix(got, wrapper) || !strings.HasSuffix(got, want) {
+			t.Errorf("%s: got %q, want wrapper followed by %q", shellName, got, want)
+		}
+	}
+}
+
+func TestSourceLine(t *testing.T) {
+	tests := []struct {
+		shell, path, want string
+	}{
+		{"bash", "/home/me/.try.sh", "source '/home/me/.try.sh'"},
+		{"fish", "/home/me/it's.fish", `source '/home/me/it'"'"'s.fish'`},
+		{"elvish", "/home/me/try.elv", "eval (slurp < '/home/me/try.elv')"},
+		{"tcsh", "/home/me/.try.csh", "source '/home/me/.try.csh'"},
+	}
+	for _, tt := range tests {
+		if got := SourceLine(tt.shell, tt.path); got != tt.want {
+			t.Errorf("SourceLine(%q, %q) = %q, want %q", tt.shell, tt.path, got, tt.want)
+		}
+	}
+}
@@ -2,6 +2,9 @@
 package theme
 
 import (
+	"math/rand/v2"
+	"sort"
+
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -109,12 +112,76 @@ var Monochrome = Theme{
 	Error:   lipgloss.Color("245"),
 }
 
+// Light theme for light terminal backgrounds
+var Light = Theme{
+	Primary:   lipgloss.Color("61"),  // Slate blue
+	Secondary: lipgloss.Color("31"),  // Teal
+	Accent:    lipgloss.Color("166"), // Dark orange
+
+	Text:       lipgloss.Color("235"), // Near black
+	TextDim:    lipgloss.Color("243"), // Gray
+	TextMuted:  lipgloss.Color("247"), // Light gray
+	Highlight:  lipgloss.Color("161"), // Magenta
+
+	Background:         lipgloss.Color(""),    // Terminal default
+	BackgroundSelected: lipgloss.Color("254"), // Pale gray
+	BackgroundDanger:   lipgloss.Color("217"), // Pale red
+
+	Success: lipgloss.Color("114"), // Green
+	Warning: lipgloss.Color("172"), // Orange
+	Error:   lipgloss.Color("160"), // Red
+}
+
+// CatppuccinLatte is the light Catppuccin flavor
+var CatppuccinLatte = Theme{
+	Primary:   lipgloss.Color("#8839ef"), // Mauve
+	Secondary: lipgloss.Color("#1e66f5"), // Blue
+	Accent:    lipgloss.Color("#fe640b"), // Peach
+
+	Text:       lipgloss.Color("#4c4f69"), // Text
+	TextDim:    lipgloss.Color("#6c6f85"), // Subtext 0
+	TextMuted:  lipgloss.Color("#9ca0b0"), // Overlay 0
+	Highlight:  lipgloss.Color("#ea76cb"), // Pink
+
+	Background:         lipgloss.Color(""),        // Terminal default
+	BackgroundSelected: lipgloss.Color("#ccd0da"), // Surface 0
+	BackgroundDanger:   lipgloss.Color("#f4c3cc"), // Red tint
+
+	Success: lipgloss.Color("#40a02b"), // Green
+	Warning: lipgloss.Color("#df8e1d"), // Yellow
+	Error:   lipgloss.Color("#d20f39"), // Red
+}
+
+// TokyoNight theme - deep navy with soft blue and purple accents
+var TokyoNight = Theme{
+	Primary:   lipgloss.Color("#7aa2f7"), // Blue
+	Secondary: lipgloss.Color("#7dcfff"), // Cyan
+	Accent:    lipgloss.Color("#bb9af7"), // Magenta
+
+	Text:       lipgloss.Color("#c0caf5"), // Foreground
+	TextDim:    lipgloss.Color("#a9b1d6"), // Dark foreground
+	TextMuted:  lipgloss.Color("#565f89"), // Comment
+	Highlight:  lipgloss.Color("#e0af68"), // Yellow
+
+	Background:         lipgloss.Color(""),        // Terminal default
+	BackgroundSelected: lipgloss.Color("#283457"), // Visual selection
+	BackgroundDanger:   lipgloss.Color("#5c2a36"), // Dark red
+
+	Success: lipgloss.Color("#9ece6a"), // Green
+	Warning: lipgloss.Color("#ff9e64"), // Orange
+	Error:   lipgloss.Color("#f7768e"), // Red
+}
+
 // Available themes by name
 var Themes = map[string]Theme{
 	"default":    Default,
 	"dracula":    Dracula,
 	"nord":       Nord,
 	"monochrome": Monochrome,
+	"light":      Light,
+
+	"catppuccin-latte": CatppuccinLatte,
+	"tokyo-night":      TokyoNight,
 }
 
 // Get returns a theme by name, falling back to Default if not found.
@@ -125,11 +192,19 @@ func Get(name string) Theme {
 	return Default
 }
 
-// Names returns all available theme names.
+// Names returns all available theme names, sorted.
 func Names() []string {
 	names := make([]string, 0, len(Themes))
 	for name := range Themes {
 		names = append(names, name)
 	}
+	sort.Strings(names)
 	return names
 }
+
+// Random returns a randomly chosen theme and its name.
+func Random() (string, Theme) {
+	names := Names()
+	name := names[rand.IntN(len(names))]
+	return name, Themes[name]
+}
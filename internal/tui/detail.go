@@ -0,0 +1,169 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/tobi/try/internal/workspace"
+)
+
+// detailHeight is how many lines the detail panel takes: one per field
+// plus its border.
+const detailHeight = 10
+
+// detailMsg carries what the detail panel loads in the background for the
+// workspace at path.
+type detailMsg struct {
+	path  string
+	usage *workspace.Usage // nil if it couldn't be counted
+	git   string
+}
+
+// toggleDetail shows or hides the detail panel.
+func (m *Model) toggleDetail() tea.Cmd {
+	m.showDetail = !m.showDetail
+	m.resizeList()
+	return m.syncDetail()
+}
+
+// resizeList fits the list to the window, leaving room for the detail
+// panel while it is shown.
+func (m *Model) resizeList() {
+	h, v := lipgloss.NewStyle().Padding(1, 2).GetFrameSize()
+	height := m.height - v
+	if m.showDetail {
+		height -= detailHeight
+	}
+	m.list.SetSize(m.width-h, max(height, 1))
+}
+
+// syncDetail starts loading the details of the selected workspace if the
+// panel is shown and they aren't loaded yet.
+func (m *Model) syncDetail() tea.Cmd {
+	if !m.showDetail {
+		return nil
+	}
+	i, ok := m.list.SelectedItem().(item)
+	if !ok || (m.detail != nil && m.detail.path == i.entry.Path) || m.detailLoading == i.entry.Path {
+		return nil
+	}
+	m.detailLoading = i.entry.Path
+	return loadDetail(i.entry, m.usage)
+}
+
+// loadDetail gathers the slower details of e: its size and git status.
+func loadDetail(e workspace.Entry, opts workspace.UsageOptions) tea.Cmd {
+	return func() tea.Msg {
+		msg := detailMsg{path: e.Path}
+		if usage, err := workspace.DirUsageWithOptions(e.Path, opts); err == nil {
+			msg.usage = &usage
+		}
+		switch st, err := workspace.GitStatus(e.Path); {
+		case errors.Is(err, workspace.ErrNotGitRepo):
+			msg.git = "not a git repo"
+		case err != nil:
+			msg.git = err.Error()
+		case st.Changed == 0:
+			msg.git = st.Branch + ", clean"
+		default:
+			msg.git = fmt.Sprintf("%s, %d changed", st.Branch, st.Changed)
+		}
+		return msg
+	}
+}
+
+// viewDetail renders the detail panel for the selected workspace.
+func (m *Model) viewDetail() string {
+	i, ok := m.list.SelectedItem().(item)
+	if !ok {
+		return ""
+	}
+	e := i.entry
+
+	size, git := "counting…", "…"
+	if m.detail != nil && m.detail.path == e.Path {
+		size, git = "unknown", m.detail.git
+		if m.detail.usage != nil {
+			size = describeUsage(*m.detail.usage)
+		}
+	}
+
+	path := e.Path
+	switch {
+	case e.Broken:
+		path += " (broken link)"
+	case e.Symlink:
+		path += " (symlink)"
+	case e.Hidden:
+		path += " (hidden)"
+	}
+
+	created := "unknown"
+	switch {
+	case !e.CreatedAt.IsZero():
+		created = e.CreatedAt.Local().Format("2006-01-02 15:04")
+	case !e.CreatedDate.IsZero():
+		created = e.CreatedDate.Format("2006-01-02")
+	}
+
+	kind := e.Kind.String()
+	if e.SourceURL != "" {
+		kind += " from " + e.SourceURL
+	}
+
+	tags := "none"
+	if len(i.tags) > 0 {
+		tags = "#" + strings.Join(i.tags, " #")
+	}
+	note := i.note
+	if note == "" {
+		note = "none"
+	}
+
+	label := lipgloss.NewStyle().Foreground(m.theme.TextDim).Width(9)
+	value := lipgloss.NewStyle().Foreground(m.theme.Text)
+	title := lipgloss.NewStyle().Foreground(m.theme.Accent).Bold(true)
+	if m.noColor {
+		label, value, title = lipgloss.NewStyle().Width(9), lipgloss.NewStyle(), lipgloss.NewStyle().Bold(true)
+	}
+
+	// Inside the border and padding, with room for the labels
+	width := m.width - 4
+	inner := width - 2
+
+	name := e.Name
+	if width > 0 {
+		name = truncateMiddle(name, inner)
+	}
+	lines := []string{title.Render(name)}
+	for _, f := range [][2]string{
+		{"Path", path},
+		{"Size", size},
+		{"Created", created},
+		{"Kind", kind},
+		{"Tags", tags},
+		{"Note", note},
+		{"Git", git},
+	} {
+		v := f[1]
+		if width > 0 {
+			v = truncateMiddle(v, inner-label.GetWidth())
+		}
+		lines = append(lines, label.Render(f[0])+value.Render(v))
+	}
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		Padding(0, 1).
+		MaxHeight(detailHeight)
+	if !m.noColor {
+		box = box.BorderForeground(m.theme.Primary)
+	}
+	if width > 0 {
+		box = box.Width(width)
+	}
+	return box.Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,97 @@
+package tui
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/tobi/try/internal/fuzzy"
+)
+
+// fieldSep separates the fields packed into an item's FilterValue. It
+// can't appear in a directory name or a one-line note.
+const fieldSep = "\x1f"
+
+// filterValue packs what an item can be found by for labelFilter.
+func filterValue(name, note string, tags []string) string {
+	return name + fieldSep + note + fieldSep + strings.Join(tags, " ")
+}
+
+// unpackTarget reverses filterValue.
+func unpackTarget(v string) fuzzy.Target {
+	name, rest, _ := strings.Cut(v, fieldSep)
+	note, tags, _ := strings.Cut(rest, fieldSep)
+	return fuzzy.Target{Name: name, Note: note, Tags: strings.Fields(tags)}
+}
+
+// labelFilter is a list.FilterFunc that matches the filter term against
+// each name's label (the part after the YYYY-MM-DD- date prefix) as well
+// as the full name, preferring label matches, and then against tags and
+// notes; see fuzzy.MatchTargets. Names are still displayed in full,
+// and only name matches have MatchedIndexes.
+func labelFilter(term string, values []string) []list.Rank {
+	var fields *matchFields
+	return fields.filter(term, values)
+}
+
+// matchFields remembers, for the last term filtered, which field each
+// workspace matched by, so rows can highlight a matching tag or note
+// without matching again. The list filters in the background, hence the
+// lock.
+type matchFields struct {
+	mu     sync.Mutex
+	term   string
+	fields map[string]fuzzy.Field // by entry name
+}
+
+// filter is labelFilter, recording the fields in f. A nil f records
+// nothing.
+func (f *matchFields) filter(term string, values []string) []list.Rank {
+	targets := make([]fuzzy.Target, len(values))
+	for i, v := range values {
+		targets[i] = unpackTarget(v)
+	}
+
+	matches := fuzzy.MatchTargets(term, targets)
+
+	ranks := make([]list.Rank, 0, len(matches))
+	fields := make(map[string]fuzzy.Field, len(matches))
+	for _, m := range matches {
+		ranks = append(ranks, list.Rank{Index: m.Index, MatchedIndexes: m.Matched})
+		fields[targets[m.Index].Name] = m.Field
+	}
+
+	if f != nil {
+		f.mu.Lock()
+		f.term, f.fields = term, fields
+		f.mu.Unlock()
+	}
+	return ranks
+}
+
+// field returns what name matched term by, or "" if term isn't the one
+// last filtered, or name didn't match it.
+func (f *matchFields) field(term, name string) fuzzy.Field {
+	if f == nil {
+		return ""
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.term != term {
+		return ""
+	}
+	return f.fields[name]
+}
+
+// limitFilter keeps the n best matches of filter, or all of them if n is
+// 0, so a result limit applies to what matched rather than to what is
+// searched.
+func limitFilter(filter list.FilterFunc, n int) list.FilterFunc {
+	return func(term string, values []string) []list.Rank {
+		ranks := filter(term, values)
+		if n > 0 && len(ranks) > n {
+			ranks = ranks[:n]
+		}
+		return ranks
+	}
+}
@@ -0,0 +1,80 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/tobi/try/internal/fuzzy"
+	"github.com/tobi/try/internal/workspace"
+)
+
+// The picker's filter and the CLI (fuzzy.Rank, behind --select, the
+// plain selector and list --filter) must rank the same entries the same way.
+func TestFilterRankParity(t *testing.T) {
+	entries := []workspace.Entry{
+		{Name: "2025-01-19-redis"},
+		{Name: "2025-01-18-redis-cluster"},
+		{Name: "2025-01-17-postgres"},
+		{Name: "2025-01-16-red-team"},
+		{Name: "dotfiles"},
+		{Name: "2025-01-15-cache"},
+	}
+	notes := map[string]string{"2025-01-15-cache": "try redis as an LRU", "dotfiles": "shell setup"}
+	tags := map[string][]string{"2025-01-17-postgres": {"work"}, "dotfiles": {"redis-conf"}}
+
+	values := make([]string, len(entries))
+	for i, e := range entries {
+		values[i] = item{entry: e, note: notes[e.Name], tags: tags[e.Name]}.FilterValue()
+	}
+
+	for _, query := range []string{"redis", "red", "rds", "#work", "shell", "2025", "zzz"} {
+		var picker []string
+		for _, r := range labelFilter(query, values) {
+			picker = append(picker, entries[r.Index].Name)
+		}
+		var cli []string
+		for _, e := range fuzzy.Rank(entries, query, notes, tags) {
+			cli = append(cli, e.Name)
+		}
+
+		if len(picker) != len(cli) {
+			t.Errorf("%q: picker ranks %q, CLI ranks %q", query, picker, cli)
+			continue
+		}
+		for i := range picker {
+			if picker[i] != cli[i] {
+				t.Errorf("%q: picker ranks %q, CLI ranks %q", query, picker, cli)
+				break
+			}
+		}
+	}
+}
+
+func TestMatchFields(t *testing.T) {
+	values := []string{
+		filterValue("2025-01-19-api", "port 8080", nil),
+		filterValue("2025-01-18-web", "", []string{"work"}),
+		filterValue("2025-01-17-work", "", nil),
+	}
+
+	// The filter records what each row matched by, for Render to show
+	f := &matchFields{}
+	f.filter("work", values)
+	for name, want := range map[string]fuzzy.Field{
+		"2025-01-17-work": fuzzy.FieldName,
+		"2025-01-18-web":  fuzzy.FieldTag,
+		"2025-01-19-api":  "",
+	} {
+		if got := f.field("work", name); got != want {
+			t.Errorf("field(%s) = %q, want %q", name, got, want)
+		}
+	}
+
+	// A term that hasn't been filtered yet has nothing recorded
+	if got := f.field("8080", "2025-01-19-api"); got != "" {
+		t.Errorf("field for an unfiltered term = %q", got)
+	}
+	f.filter("8080", values)
+	if got := f.field("8080", "2025-01-19-api"); got != fuzzy.FieldNote {
+		t.Errorf("field after filtering = %q, want note", got)
+	}
+}
@@ -0,0 +1,90 @@
+package tui
+
+import (
+	"sort"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/tobi/try/internal/workspace"
+)
+
+// Date buckets used by the grouped list, newest first.
+var dateBuckets = []string{"Today", "Yesterday", "This week", "Older"}
+
+// headerItem is a non-selectable section header in the grouped list.
+type headerItem struct {
+	title string
+}
+
+// FilterValue is empty so headers never match a filter and drop out of
+// the list while filtering.
+func (h headerItem) FilterValue() string { return "" }
+
+// entryDate is the date an entry is grouped by: the date in its name if it
+// has one, otherwise its modification time.
+func entryDate(e workspace.Entry) time.Time {
+	if !e.CreatedDate.IsZero() {
+		return e.CreatedDate
+	}
+	return e.ModTime
+}
+
+// dateBucket returns the index into dateBuckets for t.
+func dateBucket(t, now time.Time) int {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch {
+	case !t.Before(today):
+		return 0
+	case !t.Before(today.AddDate(0, 0, -1)):
+		return 1
+	case !t.Before(today.AddDate(0, 0, -7)):
+		return 2
+	default:
+		return 3
+	}
+}
+
+// groupedItems returns entries as list items with a header before each
+// date bucket, built by newItem. Entries keep their recency order within
+// a bucket.
+func groupedItems(entries []workspace.Entry, newItem func(workspace.Entry) item) []list.Item {
+	now := time.Now()
+
+	sorted := make([]workspace.Entry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return dateBucket(entryDate(sorted[i]), now) < dateBucket(entryDate(sorted[j]), now)
+	})
+
+	items := make([]list.Item, 0, len(sorted)+len(dateBuckets))
+	current := -1
+	for _, e := range sorted {
+		if b := dateBucket(entryDate(e), now); b != current {
+			items = append(items, headerItem{title: dateBuckets[b]})
+			current = b
+		}
+		items = append(items, newItem(e))
+	}
+	return items
+}
+
+// skipHeaders moves the cursor off a header row, continuing in the
+// direction it just moved from prev, or reversing at the ends of the list.
+func (m *Model) skipHeaders(prev int) {
+	down := m.list.Index() >= prev
+	for range m.list.VisibleItems() {
+		if _, ok := m.list.SelectedItem().(headerItem); !ok {
+			return
+		}
+
+		before := m.list.Index()
+		if down {
+			m.list.CursorDown()
+		} else {
+			m.list.CursorUp()
+		}
+		if m.list.Index() == before {
+			down = !down
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// KeyMap holds the configurable key bindings.
+type KeyMap struct {
+	Delete     key.Binding
+	New        key.Binding
+	NewUndated key.Binding
+	Group      key.Binding
+	Kind       key.Binding
+	Repos      key.Binding
+	Hidden     key.Binding
+	Search     key.Binding
+	Clone      key.Binding
+	Note       key.Binding
+	Tags       key.Binding
+	Refresh    key.Binding
+	More       key.Binding
+	Base       key.Binding
+	Remote     key.Binding
+	Info       key.Binding
+	Quit       key.Binding
+}
+
+// reservedKeys are handled before any configurable binding.
+var reservedKeys = map[string]string{
+	"enter":  "select",
+	"ctrl+c": "cancel",
+	"1":      "jump",
+	"2":      "jump",
+	"3":      "jump",
+	"4":      "jump",
+	"5":      "jump",
+	"6":      "jump",
+	"7":      "jump",
+	"8":      "jump",
+	"9":      "jump",
+}
+
+// cancelKey documents the reserved ctrl+c in the full help.
+var cancelKey = key.NewBinding(key.WithKeys("ctrl+c"), key.WithHelp("ctrl+c", "cancel"))
+
+// jumpKey chooses the nth entry of the unfiltered list by its digit.
+var jumpKey = key.NewBinding(
+	key.WithKeys("1", "2", "3", "4", "5", "6", "7", "8", "9"),
+	key.WithHelp("1-9", "open nth"),
+)
+
+// DefaultKeyMap returns the built-in key bindings.
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Delete:     key.NewBinding(key.WithKeys("ctrl+d"), key.WithHelp("ctrl+d", "delete")),
+		New:        key.NewBinding(key.WithKeys("ctrl+n"), key.WithHelp("ctrl+n", "new")),
+		NewUndated: key.NewBinding(key.WithKeys("alt+enter"), key.WithHelp("alt+enter", "new (no date)")),
+		Group:      key.NewBinding(key.WithKeys("alt+g"), key.WithHelp("alt+g", "group by date")),
+		Kind:       key.NewBinding(key.WithKeys("alt+k"), key.WithHelp("alt+k", "filter by kind")),
+		Repos:      key.NewBinding(key.WithKeys("alt+r"), key.WithHelp("alt+r", "repos only")),
+		Hidden:     key.NewBinding(key.WithKeys("alt+h"), key.WithHelp("alt+h", "show hidden")),
+		Search:     key.NewBinding(key.WithKeys("ctrl+f"), key.WithHelp("ctrl+f", "search files")),
+		Clone:      key.NewBinding(key.WithKeys("ctrl+g"), key.WithHelp("ctrl+g", "clone")),
+		Note:       key.NewBinding(key.WithKeys("ctrl+e"), key.WithHelp("ctrl+e", "edit note")),
+		Tags:       key.NewBinding(key.WithKeys("ctrl+t"), key.WithHelp("ctrl+t", "edit tags")),
+		Refresh:    key.NewBinding(key.WithKeys("ctrl+r"), key.WithHelp("ctrl+r", "refresh")),
+		More:       key.NewBinding(key.WithKeys("ctrl+l"), key.WithHelp("ctrl+l", "load more")),
+		Base:       key.NewBinding(key.WithKeys("ctrl+b"), key.WithHelp("ctrl+b", "go to tries dir")),
+		Remote:     key.NewBinding(key.WithKeys("ctrl+y"), key.WithHelp("ctrl+y", "copy git remote")),
+		Info:       key.NewBinding(key.WithKeys("i"), key.WithHelp("i", "details")),
+		Quit:       key.NewBinding(key.WithKeys("esc", "q"), key.WithHelp("esc/q", "quit")),
+	}
+}
+
+// actions maps config action names to their bindings.
+func (k *KeyMap) actions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"delete":      &k.Delete,
+		"new":         &k.New,
+		"new_undated": &k.NewUndated,
+		"group":       &k.Group,
+		"kind":        &k.Kind,
+		"repos":       &k.Repos,
+		"hidden":      &k.Hidden,
+		"search":      &k.Search,
+		"clone":       &k.Clone,
+		"note":        &k.Note,
+		"tags":        &k.Tags,
+		"refresh":     &k.Refresh,
+		"more":        &k.More,
+		"base":        &k.Base,
+		"remote":      &k.Remote,
+		"info":        &k.Info,
+		"quit":        &k.Quit,
+	}
+}
+
+// ActionKeys returns the keys bound to the named config action.
+func (k KeyMap) ActionKeys(action string) ([]string, bool) {
+	b, ok := k.actions()[action]
+	if !ok {
+		return nil, false
+	}
+	return b.Keys(), true
+}
+
+// ParseKeyMap returns the default key map with overrides applied.
+// overrides maps action names to key strings such as "ctrl+x"; several
+// keys can be given separated by commas. Actions without an override keep
+// their default keys. It is an error to name an unknown action or to bind
+// one key to two actions.
+func ParseKeyMap(overrides map[string]string) (KeyMap, error) {
+	km := DefaultKeyMap()
+	actions := km.actions()
+
+	for name, keys := range overrides {
+		b, ok := actions[name]
+		if !ok {
+			return KeyMap{}, fmt.Errorf("unknown key action %q (valid: %s)", name, strings.Join(actionNames(actions), ", "))
+		}
+
+		var list []string
+		for _, k := range strings.Split(keys, ",") {
+			if k = strings.TrimSpace(k); k != "" {
+				list = append(list, k)
+			}
+		}
+		if len(list) == 0 {
+			return KeyMap{}, fmt.Errorf("no key given for action %q", name)
+		}
+
+		*b = key.NewBinding(key.WithKeys(list...), key.WithHelp(strings.Join(list, "/"), b.Help().Desc))
+	}
+
+	// Each key may only trigger one action
+	owner := map[string]string{}
+	for k, action := range reservedKeys {
+		owner[k] = action
+	}
+	for _, name := range actionNames(actions) {
+		for _, k := range actions[name].Keys() {
+			if other, taken := owner[k]; taken {
+				return KeyMap{}, fmt.Errorf("key %q is bound to both %q and %q", k, other, name)
+			}
+			owner[k] = name
+		}
+	}
+
+	return km, nil
+}
+
+// actionNames returns the action names in a stable order.
+func actionNames(actions map[string]*key.Binding) []string {
+	names := make([]string, 0, len(actions))
+	for name := range actions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
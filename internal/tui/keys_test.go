@@ -0,0 +1,70 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseKeyMapDefaults(t *testing.T) {
+	km, err := ParseKeyMap(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := km.Delete.Keys(); len(got) != 1 || got[0] != "ctrl+d" {
+		t.Errorf("delete: got %v", got)
+	}
+	if got := km.New.Keys(); len(got) != 1 || got[0] != "ctrl+n" {
+		t.Errorf("new: got %v", got)
+	}
+}
+
+func TestParseKeyMapOverrides(t *testing.T) {
+	km, err := ParseKeyMap(map[string]string{
+		"delete": "ctrl+x",
+		"quit":   "q, esc",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := km.Delete.Keys(); len(got) != 1 || got[0] != "ctrl+x" {
+		t.Errorf("delete: got %v", got)
+	}
+	if km.Delete.Help().Key != "ctrl+x" || km.Delete.Help().Desc != "delete" {
+		t.Errorf("delete help should follow the override, got %+v", km.Delete.Help())
+	}
+	if got := km.Quit.Keys(); len(got) != 2 || got[0] != "q" || got[1] != "esc" {
+		t.Errorf("quit: got %v", got)
+	}
+
+	// Unmapped actions keep their defaults
+	if got := km.New.Keys(); len(got) != 1 || got[0] != "ctrl+n" {
+		t.Errorf("new: got %v", got)
+	}
+}
+
+func TestParseKeyMapErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string]string
+		want      string
+	}{
+		{"unknown action", map[string]string{"explode": "ctrl+e"}, "unknown key action"},
+		{"empty key", map[string]string{"delete": " , "}, "no key given"},
+		{"conflict", map[string]string{"delete": "ctrl+n"}, "bound to both"},
+		{"reserved", map[string]string{"quit": "enter"}, "bound to both"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseKeyMap(tt.overrides)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %q should contain %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -1,16 +1,22 @@
 package tui
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/tobi/try/internal/fuzzy"
 	"github.com/tobi/try/internal/theme"
 	"github.com/tobi/try/internal/workspace"
 )
@@ -21,6 +27,10 @@ type State int
 const (
 	StateSelector State = iota
 	StateDeleteConfirm
+	StateClonePrompt
+	StateNoteEdit
+	StateTagEdit
+	StateScanError
 )
 
 // Action represents the result of a TUI session.
@@ -30,6 +40,7 @@ type Action struct {
 	URL     string   // For Clone
 	Paths   []string // For Delete
 	BaseDir string   // Base directory for operations
+	NoDate  bool     // For Create: skip the date prefix
 }
 
 // ActionType represents the type of action selected.
@@ -42,16 +53,22 @@ const (
 	ActionClone
 	ActionDelete
 	ActionCancel
+	ActionCDBase // cd into the tries directory itself
 )
 
 // item implements list.Item for directory entries.
 type item struct {
 	entry workspace.Entry
+	match string // file that matched a deep search, if any
+	note  string
+	tags  []string
 }
 
-func (i item) FilterValue() string { return i.entry.Name }
+func (i item) FilterValue() string { return filterValue(i.entry.Name, i.note, i.tags) }
 func (i item) Title() string       { return i.entry.Name }
-func (i item) Description() string { return formatRelativeTime(i.entry.ModTime) }
+func (i item) Description() string {
+	return workspace.FormatTime(i.entry.Recency(), time.Now(), workspace.TimeRelative)
+}
 
 // Model is the main TUI model.
 type Model struct {
@@ -59,17 +76,78 @@ type Model struct {
 	basePath     string
 	initialQuery string
 	theme        theme.Theme
+	useCache     bool
+	scanDepth    int
+	grouped      bool
+	sticky       bool // delete in place and stay open; only cd, create and quit exit
+	dryRun       bool // deleting only shows what it would free
+	flatClones   bool // clones are named without the date prefix
+	timeFormat   workspace.TimeFormat
+	includeFiles bool            // list regular files too
+	showHidden   bool            // list entries whose names start with "."
+	kindFilter   bool            // only list entries of kind
+	kind         workspace.Kind  // kind shown while kindFilter is set
+	reposOnly    bool            // only list git repositories
+	repos        map[string]bool // IsGitRepo by path, filled in as reposOnly needs it
+	noColor      bool
+	gitRecency   bool
+	pageSize     int                    // entries added to the list at a time; 0 for all
+	usage        workspace.UsageOptions // bounds on the walks that size up a workspace
+	keys         KeyMap
 
 	// State
 	state   State
 	list    list.Model
 	entries []workspace.Entry
-	width   int
-	height  int
+	notes   map[string]string
+	tags    map[string][]string
+	fields  *matchFields               // what the filter last matched each entry by
+	scan    <-chan workspace.ScanBatch // non-nil while entries are streaming in
+	loading bool
+	limit   int // how many entries are in the list; 0 for all
+
+	// Detail panel: shown under the list for the selected entry, with
+	// detail holding what was loaded in the background for it
+	showDetail    bool
+	detail        *detailMsg
+	detailLoading string // path whose details are being loaded
+
+	// The running scan gives up after scanTimeout; scanCtx ends then
+	scanTimeout time.Duration
+	scanCtx     context.Context
+	stopScan    context.CancelFunc
+	scanErr     error // why the scan failed, shown in StateScanError
+	scanGen     int   // counts scans, so results of a replaced one are dropped
+
+	// Refresh: a rescan collects into fresh and swaps it in when done, then
+	// reselects the entry at reselect
+	refreshing bool
+	fresh      []workspace.Entry
+	reselect   string
+	spinner    spinner.Model
+	width      int
+	height     int
+
+	// Deep search: while deepQuery is set, matches replace the list
+	deepQuery string
+	matches   []workspace.FileMatch
 
 	// Delete confirmation
-	deleteTarget  string // path of item to delete
-	deleteConfirm string // user's typed confirmation
+	deleteTarget  string           // path of item to delete
+	deleteConfirm string           // user's typed confirmation
+	deleteUsage   *workspace.Usage // what the target holds; nil until counted
+
+	// Clone prompt
+	cloneURL string // URL typed or pasted so far
+	cloneErr string // why the last submitted URL was rejected
+
+	// Note editing
+	noteTarget string // name of the entry whose note is being edited
+	noteInput  string
+
+	// Tag editing
+	tagTarget string // name of the entry whose tags are being edited
+	tagInput  string
 
 	// Result
 	action *Action
@@ -78,7 +156,9 @@ type Model struct {
 
 // itemDelegate handles rendering of list items.
 type itemDelegate struct {
-	styles *delegateStyles
+	styles     *delegateStyles
+	timeFormat workspace.TimeFormat
+	fields     *matchFields // what the list's filter matched each row by
 }
 
 type delegateStyles struct {
@@ -86,9 +166,30 @@ type delegateStyles struct {
 	selected lipgloss.Style
 	dimmed   lipgloss.Style
 	desc     lipgloss.Style
+	header   lipgloss.Style
+	matched  lipgloss.Style // a tag or note the filter matched
+	tag      lipgloss.Style
+
+	// cursor marks the selected row when there is no background color
+	// to highlight it with
+	cursor string
 }
 
-func newDelegateStyles(t theme.Theme) *delegateStyles {
+func newDelegateStyles(t theme.Theme, noColor bool) *delegateStyles {
+	if noColor {
+		plain := lipgloss.NewStyle()
+		return &delegateStyles{
+			normal:   plain.Padding(0, 0, 0, 2),
+			selected: plain.Bold(true),
+			dimmed:   plain,
+			desc:     plain,
+			header:   plain.Bold(true),
+			matched:  plain.Underline(true),
+			tag:      plain,
+			cursor:   "> ",
+		}
+	}
+
 	return &delegateStyles{
 		normal: lipgloss.NewStyle().
 			Padding(0, 0, 0, 2),
@@ -100,6 +201,13 @@ func newDelegateStyles(t theme.Theme) *delegateStyles {
 			Foreground(t.TextDim),
 		desc: lipgloss.NewStyle().
 			Foreground(t.TextMuted),
+		header: lipgloss.NewStyle().
+			Foreground(t.Secondary).
+			Bold(true),
+		matched: lipgloss.NewStyle().
+			Foreground(t.Highlight),
+		tag: lipgloss.NewStyle().
+			Foreground(t.Secondary),
 	}
 }
 
@@ -108,6 +216,11 @@ func (d itemDelegate) Spacing() int                            { return 0 }
 func (d itemDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
 
 func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	if h, ok := listItem.(headerItem); ok {
+		fmt.Fprint(w, d.styles.header.Render(h.title))
+		return
+	}
+
 	i, ok := listItem.(item)
 	if !ok {
 		return
@@ -115,35 +228,80 @@ func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 
 	isSelected := index == m.Index()
 
+	// A filter hit with nothing highlighted in the name matched a tag or
+	// the note, as the filter recorded
+	var field fuzzy.Field
+	if m.FilterState() != list.Unfiltered && m.FilterValue() != "" && len(m.MatchesForItem(index)) == 0 {
+		field = d.fields.field(m.FilterValue(), i.entry.Name)
+	}
+
+	timeAgo := workspace.FormatTime(i.entry.Recency(), time.Now(), d.timeFormat)
+	if i.entry.Broken {
+		timeAgo = "broken link"
+	}
+	if i.match != "" {
+		timeAgo = IconFile + " " + i.match
+	}
+
+	// Keep the time-ago column visible: drop the note, then the tags, and
+	// finally shorten the name in the middle until the row fits
+	availableWidth := m.Width() - 4 // account for padding
+	budget := availableWidth - lipgloss.Width(timeAgo) - 2
+	entryName, tags, note := i.entry.Name, i.tags, i.note
+	if rowTextWidth(entryName, tags, note) > budget {
+		note = ""
+	}
+	if rowTextWidth(entryName, tags, note) > budget {
+		tags = nil
+	}
+	entryName = truncateMiddle(entryName, budget)
+
 	// For selected rows, don't use inner styles - just plain text
 	// The row style will handle the background uniformly
 	var name, meta string
-	timeAgo := formatRelativeTime(i.entry.ModTime)
-
 	if isSelected {
 		// Plain text - row style handles background
-		name = i.entry.Name
+		name = entryName
 		meta = timeAgo
+		for _, t := range tags {
+			name += " #" + t
+		}
+		if note != "" {
+			name += "  " + note
+		}
 	} else {
-		// Normal row - apply dim styling to date prefix and meta
-		name = d.renderNameWithDim(i.entry.Name)
+		// Normal row - apply dim styling to date prefix, note and meta,
+		// and color tags as chips
+		name = d.renderNameWithDim(entryName)
+		if i.entry.Hidden {
+			name = d.styles.dimmed.Render(entryName)
+		}
 		meta = d.styles.desc.Render(timeAgo)
+		tagStyle, noteStyle := d.styles.tag, d.styles.dimmed
+		switch field {
+		case fuzzy.FieldTag:
+			tagStyle = d.styles.matched
+		case fuzzy.FieldNote:
+			noteStyle = d.styles.matched
+		}
+		for _, t := range tags {
+			name += " " + tagStyle.Render("#"+t)
+		}
+		if note != "" {
+			name += "  " + noteStyle.Render(note)
+		}
 	}
 
 	// Calculate spacing - fill entire row width
 	nameWidth := lipgloss.Width(name)
 	metaWidth := lipgloss.Width(meta)
-	availableWidth := m.Width() - 4 // account for padding
 
 	var line string
 	if nameWidth+metaWidth+2 <= availableWidth {
 		spacing := availableWidth - nameWidth - metaWidth
-		if spacing < 0 {
-			spacing = 0
-		}
 		line = fmt.Sprintf("%s%s%s", name, strings.Repeat(" ", spacing), meta)
 	} else {
-		// Fill remaining space to ensure full-width highlight
+		// Too narrow for the time-ago column at all
 		spacing := availableWidth - nameWidth
 		if spacing < 0 {
 			spacing = 0
@@ -155,6 +313,7 @@ func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 	var rowStyle lipgloss.Style
 	if isSelected {
 		rowStyle = d.styles.selected
+		line = d.styles.cursor + line
 	} else {
 		rowStyle = d.styles.normal
 	}
@@ -162,12 +321,24 @@ func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 	fmt.Fprint(w, rowStyle.Width(m.Width()).Render(line))
 }
 
+// rowTextWidth returns the width of a row's name, tags and note as Render
+// lays them out.
+func rowTextWidth(name string, tags []string, note string) int {
+	w := lipgloss.Width(name)
+	for _, t := range tags {
+		w += lipgloss.Width(" #" + t)
+	}
+	if note != "" {
+		w += lipgloss.Width("  " + note)
+	}
+	return w
+}
+
 func (d itemDelegate) renderNameWithDim(name string) string {
-	// Check if name has date prefix (YYYY-MM-DD-)
-	if len(name) > 11 && name[4] == '-' && name[7] == '-' && name[10] == '-' {
-		dateStr := name[:11] // includes trailing dash
-		rest := name[11:]
-		return d.styles.dimmed.Render(dateStr) + rest
+	// Dim the date prefix (YYYY-MM-DD-) if there is one
+	if _, label, ok := workspace.ParseName(name); ok && label != "" {
+		dateStr := strings.TrimSuffix(name, label) // includes trailing dash
+		return d.styles.dimmed.Render(dateStr) + label
 	}
 	return name
 }
@@ -178,22 +349,40 @@ func New(basePath string, opts ...Option) *Model {
 		basePath: basePath,
 		theme:    theme.Default,
 		state:    StateSelector,
+		keys:     DefaultKeyMap(),
+		fields:   &matchFields{},
+
+		scanTimeout: defaultScanTimeout,
+		usage: workspace.UsageOptions{
+			MaxFiles: usageMaxFiles,
+			Skip:     workspace.DefaultUsageSkip,
+		},
 	}
 
 	for _, opt := range opts {
 		opt(m)
 	}
+	m.limit = m.pageSize
 
 	// Create delegate with theme
 	delegate := itemDelegate{
-		styles: newDelegateStyles(m.theme),
+		styles:     newDelegateStyles(m.theme, m.noColor),
+		timeFormat: m.timeFormat,
+		fields:     m.fields,
 	}
 
+	m.spinner = spinner.New(
+		spinner.WithSpinner(spinner.Dot),
+		spinner.WithStyle(lipgloss.NewStyle().Foreground(m.theme.Accent)),
+	)
+
 	// Create list with empty items (will be populated in Init)
 	m.list = list.New([]list.Item{}, delegate, 0, 0)
-	m.list.Title = IconHome + " Try"
+	m.list.Title = m.baseTitle()
 	m.list.SetShowStatusBar(true)
+	m.list.StatusMessageLifetime = 3 * time.Second // long enough to read a remote URL
 	m.list.SetFilteringEnabled(true)
+	m.list.Filter = m.fields.filter
 	m.list.SetShowHelp(true)
 	m.list.DisableQuitKeybindings()
 
@@ -212,20 +401,33 @@ func New(basePath string, opts ...Option) *Model {
 	// Disable default quit key
 	m.list.KeyMap.Quit = key.NewBinding(key.WithDisabled())
 
-	// Add custom key bindings to help
+	// Add custom key bindings to help. Quit comes first so the short
+	// help still shows how to get out when it is cut to the window width.
 	m.list.AdditionalShortHelpKeys = func() []key.Binding {
 		return []key.Binding{
-			key.NewBinding(
-				key.WithKeys("ctrl+d"),
-				key.WithHelp("ctrl+d", "delete"),
-			),
-			key.NewBinding(
-				key.WithKeys("ctrl+n"),
-				key.WithHelp("ctrl+n", "new"),
-			),
+			m.keys.Quit,
+			jumpKey,
+			m.keys.Delete,
+			m.keys.New,
+			m.keys.NewUndated,
+			m.keys.Group,
+			m.keys.Kind,
+			m.keys.Repos,
+			m.keys.Hidden,
+			m.keys.Search,
+			m.keys.Clone,
+			m.keys.Note,
+			m.keys.Tags,
+			m.keys.Refresh,
+			m.keys.More,
+			m.keys.Base,
+			m.keys.Remote,
+			m.keys.Info,
 		}
 	}
-	m.list.AdditionalFullHelpKeys = m.list.AdditionalShortHelpKeys
+	m.list.AdditionalFullHelpKeys = func() []key.Binding {
+		return append(m.list.AdditionalShortHelpKeys(), cancelKey)
+	}
 
 	return m
 }
@@ -240,30 +442,270 @@ func WithTheme(t theme.Theme) Option {
 	}
 }
 
-// WithInitialQuery sets the initial search query.
+// WithCache enables the on-disk scan cache.
+func WithCache(enabled bool) Option {
+	return func(m *Model) {
+		m.useCache = enabled
+	}
+}
+
+// WithScanDepth sets how many directory levels to scan for workspaces.
+func WithScanDepth(depth int) Option {
+	return func(m *Model) {
+		m.scanDepth = depth
+	}
+}
+
+// WithKeyMap sets the key bindings, usually from ParseKeyMap.
+func WithKeyMap(km KeyMap) Option {
+	return func(m *Model) {
+		m.keys = km
+	}
+}
+
+// WithGrouping starts the list grouped by date with section headers.
+func WithGrouping(enabled bool) Option {
+	return func(m *Model) {
+		m.grouped = enabled
+	}
+}
+
+// WithNoColor renders without colors, marking the selection and the delete
+// bar with text instead.
+func WithNoColor(enabled bool) Option {
+	return func(m *Model) {
+		m.noColor = enabled
+	}
+}
+
+// WithGitRecency orders git repositories by their latest commit rather
+// than their mtime. Commit times are looked up in the background after the
+// scan, so the list reorders once they arrive.
+func WithGitRecency(enabled bool) Option {
+	return func(m *Model) {
+		m.gitRecency = enabled
+	}
+}
+
+// WithMaxResults caps how many entries are listed at once, most recent
+// first, and how many matches a filter shows, though it searches every
+// entry. More are added a page of n at a time on demand. Zero or less
+// shows all.
+func WithMaxResults(n int) Option {
+	return func(m *Model) {
+		m.pageSize = n
+	}
+}
+
+// WithScanTimeout sets how long to wait for the tries directory to be
+// scanned before listing what was found so far (default 10s).
+func WithScanTimeout(d time.Duration) Option {
+	return func(m *Model) {
+		m.scanTimeout = d
+	}
+}
+
+// WithIncludeFiles lists regular files in the tries directory as well as
+// directories.
+func WithIncludeFiles(include bool) Option {
+	return func(m *Model) {
+		m.includeFiles = include
+	}
+}
+
+// WithUsageBounds sets how deep the walks that size up a workspace go, in
+// directory levels (0 for no limit), and which directory names they skip.
+func WithUsageBounds(maxDepth int, skip []string) Option {
+	return func(m *Model) {
+		m.usage.MaxDepth = maxDepth
+		m.usage.Skip = skip
+	}
+}
+
+// WithTimeFormat sets how each row's last activity is shown.
+func WithTimeFormat(f workspace.TimeFormat) Option {
+	return func(m *Model) {
+		m.timeFormat = f
+	}
+}
+
+// WithSticky keeps the picker open after deleting: the workspace is moved
+// to the trash right away and the list updated, so several can be deleted
+// in one session. Only choosing, creating, cloning or quitting exits.
+func WithSticky(enabled bool) Option {
+	return func(m *Model) {
+		m.sticky = enabled
+	}
+}
+
+// WithDryRun makes deleting harmless: the delete bar says how much space
+// the workspace would free, and enter or esc goes back to the list.
+func WithDryRun(enabled bool) Option {
+	return func(m *Model) {
+		m.dryRun = enabled
+	}
+}
+
+// WithFlatClones names repos cloned from the picker without the date
+// prefix, as clone --flat does.
+func WithFlatClones(enabled bool) Option {
+	return func(m *Model) {
+		m.flatClones = enabled
+	}
+}
+
+// WithInitialQuery opens the picker filtering on q, with spaces turned
+// into hyphens as in workspace names.
 func WithInitialQuery(q string) Option {
 	return func(m *Model) {
 		m.initialQuery = strings.ReplaceAll(q, " ", "-")
 	}
 }
 
+// Bounds for a deep search, which reads every workspace directory.
+const (
+	searchMaxFiles = 20000
+	searchTimeout  = 2 * time.Second
+)
+
+// scanBatchSize is how many entries are rendered per incremental update
+// while a large tries directory is being scanned.
+const scanBatchSize = 200
+
+// defaultScanTimeout is how long the picker waits for a scan, such as of a
+// slow network mount, before showing what it has.
+const defaultScanTimeout = 10 * time.Second
+
 // Init implements tea.Model.
 func (m *Model) Init() tea.Cmd {
-	return m.loadEntries
+	return tea.Batch(m.loadEntries(), m.spinner.Tick)
+}
+
+// loadEntries starts streaming the tries directory and returns a command
+// that waits for the first batch.
+func (m *Model) loadEntries() tea.Cmd {
+	m.entries = nil
+	// Notes and tags are small optional files; without them rows just
+	// show none
+	m.notes, _ = workspace.LoadNotes(m.basePath)
+	m.tags, _ = workspace.LoadTags(m.basePath)
+	if m.cacheable() {
+		if entries, ok := workspace.LoadCache(m.basePath); ok {
+			m.entries = entries
+			return func() tea.Msg { return scanDoneMsg{} }
+		}
+	}
+	return m.startScan()
+}
+
+// startScan starts walking the tries directory and returns a command that
+// waits for the first batch.
+func (m *Model) startScan() tea.Cmd {
+	m.loading = true
+	m.scanGen++
+	m.scanCtx, m.stopScan = context.WithTimeout(context.Background(), m.scanTimeout)
+	m.scan = workspace.ScanStreamContext(m.scanCtx, m.basePath, workspace.ScanOptions{
+		MaxDepth:      m.scanDepth,
+		BatchSize:     scanBatchSize,
+		IncludeFiles:  m.includeFiles,
+		IncludeHidden: m.showHidden,
+	})
+	return waitForBatch(m.scanCtx, m.scan)
+}
+
+// refresh rescans the tries directory, bypassing the cache, while the
+// current list stays up. The filter is kept and the selected entry is
+// reselected once the new entries are in.
+func (m *Model) refresh() tea.Cmd {
+	if m.loading {
+		return nil
+	}
+	m.refreshing = true
+	m.fresh = nil
+	m.reselect = ""
+	if i, ok := m.list.SelectedItem().(item); ok {
+		m.reselect = i.entry.Path
+	}
+	m.notes, _ = workspace.LoadNotes(m.basePath)
+	m.tags, _ = workspace.LoadTags(m.basePath)
+	return tea.Batch(m.startScan(), m.spinner.Tick)
 }
 
-func (m *Model) loadEntries() tea.Msg {
-	entries, err := workspace.Scan(m.basePath)
-	if err != nil {
-		return errMsg{err}
+// restoreSelection moves the cursor back to the entry that was selected
+// before a refresh, if it is still visible.
+func (m *Model) restoreSelection() {
+	if m.reselect == "" {
+		return
+	}
+	for idx, li := range m.list.VisibleItems() {
+		if i, ok := li.(item); ok && i.entry.Path == m.reselect {
+			m.list.Select(idx)
+			break
+		}
 	}
-	return entriesLoadedMsg{entries}
+	m.reselect = ""
 }
 
-type entriesLoadedMsg struct {
+// cacheable reports whether the scan cache can be used. Changes inside
+// topic folders don't bump the base directory's mtime, so nested scans
+// always walk, and the cache holds no hidden entries or plain files.
+func (m *Model) cacheable() bool {
+	return m.useCache && m.scanDepth <= 1 && !m.showHidden && !m.includeFiles
+}
+
+// waitForBatch returns a command that receives the next scan batch, or
+// reports the scan stopped once ctx ends.
+func waitForBatch(ctx context.Context, scan <-chan workspace.ScanBatch) tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case batch, ok := <-scan:
+			if !ok {
+				if ctx.Err() != nil {
+					return scanStoppedMsg{}
+				}
+				return scanDoneMsg{}
+			}
+			if batch.Err != nil {
+				return errMsg{batch.Err}
+			}
+			return entriesBatchMsg{batch.Entries}
+		case <-ctx.Done():
+			// The walk may be stuck in a read it can't abandon
+			return scanStoppedMsg{}
+		}
+	}
+}
+
+type entriesBatchMsg struct {
 	entries []workspace.Entry
 }
 
+type scanDoneMsg struct{}
+
+// scanStoppedMsg ends a scan that ran out of time, listing what it found.
+type scanStoppedMsg struct{}
+
+type commitTimesMsg struct {
+	gen     int // the scan whose entries these are
+	entries []workspace.Entry
+}
+
+type searchResultMsg struct {
+	query   string
+	matches []workspace.FileMatch
+}
+
+type remoteMsg struct {
+	url    string
+	copied bool
+	err    error
+}
+
+type usageMsg struct {
+	path  string
+	usage workspace.Usage
+}
+
 type errMsg struct {
 	err error
 }
@@ -277,22 +719,131 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
-		h, v := lipgloss.NewStyle().Padding(1, 2).GetFrameSize()
-		m.list.SetSize(msg.Width-h, msg.Height-v)
+		m.resizeList()
 		return m, nil
 
-	case entriesLoadedMsg:
+	case spinner.TickMsg:
+		// Stop ticking once loading is done
+		if !m.loading {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		m.list.Title = m.baseTitle() + " " + m.spinner.View()
+		return m, cmd
+
+	case entriesBatchMsg:
+		// A refresh keeps the old list up until the new one is complete
+		if m.refreshing {
+			m.fresh = append(m.fresh, msg.entries...)
+			return m, waitForBatch(m.scanCtx, m.scan)
+		}
+		// Render what we have so far; sorting waits until the scan is done
+		m.entries = append(m.entries, msg.entries...)
+		return m, tea.Batch(m.setItems(), m.applyInitialQuery(), waitForBatch(m.scanCtx, m.scan))
+
+	case scanStoppedMsg:
+		// Finish with what arrived, but don't cache a partial list
+		m.scan = nil
+		_, done := m.Update(scanDoneMsg{})
+		status := m.list.NewStatusMessage(fmt.Sprintf("scan stopped after %s; list may be incomplete (%s to retry)",
+			m.scanTimeout, m.keys.Refresh.Help().Key))
+		return m, tea.Batch(done, status)
+
+	case scanDoneMsg:
+		var status tea.Cmd
+		if m.refreshing {
+			m.entries = m.fresh
+			m.fresh = nil
+			m.refreshing = false
+			status = m.list.NewStatusMessage("refreshed")
+		}
+		if m.scan != nil && m.cacheable() {
+			// Fresh walk finished; remember it for the next invocation
+			_ = workspace.SaveCache(m.basePath, m.entries)
+		}
+		m.scan = nil
+		if m.stopScan != nil {
+			m.stopScan()
+		}
+		m.loading = false
+		workspace.SortEntries(m.entries)
+		cmds := []tea.Cmd{m.setItems(), m.applyInitialQuery(), status}
+		if m.gitRecency {
+			cmds = append(cmds, loadCommitTimes(m.scanGen, m.entries))
+		}
+		// With a filter applied the list refilters asynchronously and the
+		// selection is restored when the matches arrive
+		if m.list.FilterState() == list.Unfiltered {
+			m.restoreSelection()
+		}
+		return m, tea.Batch(cmds...)
+
+	case list.FilterMatchesMsg:
+		var cmd tea.Cmd
+		m.list, cmd = m.list.Update(msg)
+		m.restoreSelection()
+		return m, tea.Batch(cmd, m.syncDetail())
+
+	case commitTimesMsg:
+		// Entries of a scan that has since been replaced would overwrite
+		// the current ones
+		if msg.gen != m.scanGen {
+			return m, nil
+		}
 		m.entries = msg.entries
-		items := make([]list.Item, len(msg.entries))
-		for i, e := range msg.entries {
-			items[i] = item{entry: e}
+		return m, m.setItems()
+
+	case searchResultMsg:
+		// Ignore results for a search that has since been left
+		if msg.query != m.deepQuery {
+			return m, nil
+		}
+		m.matches = msg.matches
+		m.list.Title = fmt.Sprintf("%s Try · %d with files matching %q", IconHome, len(msg.matches), msg.query)
+		return m, m.setItems()
+
+	case remoteMsg:
+		switch {
+		case errors.Is(msg.err, workspace.ErrNotGitRepo):
+			return m, m.list.NewStatusMessage("not a git repo")
+		case msg.err != nil:
+			return m, m.list.NewStatusMessage(msg.err.Error())
+		case msg.copied:
+			return m, m.list.NewStatusMessage("copied " + msg.url)
+		}
+		return m, m.list.NewStatusMessage(msg.url)
+
+	case detailMsg:
+		if msg.path == m.detailLoading {
+			m.detailLoading = ""
+		}
+		m.detail = &msg
+		return m, nil
+
+	case usageMsg:
+		// Ignore counts for a delete that has since been left
+		if msg.path == m.deleteTarget {
+			m.deleteUsage = &msg.usage
 		}
-		m.list.SetItems(items)
 		return m, nil
 
 	case errMsg:
-		m.err = msg.err
-		return m, tea.Quit
+		m.loading = false
+		m.scan = nil
+		if m.stopScan != nil {
+			m.stopScan()
+		}
+		// A failed refresh keeps the list it was replacing
+		if m.refreshing {
+			m.refreshing = false
+			m.fresh = nil
+			m.list.Title = m.baseTitle()
+			return m, m.list.NewStatusMessage("refresh failed: " + msg.err.Error())
+		}
+		m.scanErr = msg.err
+		m.state = StateScanError
+		return m, nil
 	}
 
 	var cmd tea.Cmd
@@ -300,42 +851,343 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// loadCommitTimes returns a command that looks up the latest commit of
+// every repository among entries, from scan gen, and delivers them
+// re-sorted.
+func loadCommitTimes(gen int, entries []workspace.Entry) tea.Cmd {
+	entries = append([]workspace.Entry(nil), entries...)
+	return func() tea.Msg {
+		workspace.ApplyCommitTimes(entries)
+		workspace.SortByRecency(entries)
+		return commitTimesMsg{gen, entries}
+	}
+}
+
+// baseTitle is the list title, noting when entries are held back by the
+// result limit.
+func (m *Model) baseTitle() string {
+	title := IconHome + " Try"
+	if m.kindFilter {
+		title += " · " + m.kind.String()
+	}
+	if m.reposOnly {
+		title += " · repos"
+	}
+	if m.showHidden {
+		title += " · with hidden"
+	}
+	if entries := m.kindEntries(); m.limit > 0 && len(entries) > m.limit {
+		title += fmt.Sprintf(" · %d of %d (%s for more)", m.limit, len(entries), m.keys.More.Help().Key)
+	}
+	return title
+}
+
+// kindEntries returns the entries of the kind being shown, and only git
+// repositories if asked, or all of them.
+func (m *Model) kindEntries() []workspace.Entry {
+	if !m.kindFilter && !m.reposOnly {
+		return m.entries
+	}
+	var entries []workspace.Entry
+	for _, e := range m.entries {
+		if m.kindFilter && e.Kind != m.kind {
+			continue
+		}
+		if m.reposOnly && !m.isRepo(e.Path) {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// isRepo reports whether path is a git repository, checking each path
+// once and only when the repo filter is first used.
+func (m *Model) isRepo(path string) bool {
+	repo, ok := m.repos[path]
+	if !ok {
+		if m.repos == nil {
+			m.repos = map[string]bool{}
+		}
+		repo = workspace.IsGitRepo(path)
+		m.repos[path] = repo
+	}
+	return repo
+}
+
+// cycleKind steps the kind filter through every kind, unknown last, and
+// back to showing everything.
+func (m *Model) cycleKind() tea.Cmd {
+	cycle := append(append([]workspace.Kind(nil), workspace.Kinds...), workspace.KindUnknown)
+	switch {
+	case !m.kindFilter:
+		m.kindFilter, m.kind = true, cycle[0]
+	case m.kind == cycle[len(cycle)-1]:
+		m.kindFilter, m.kind = false, workspace.KindUnknown
+	default:
+		for i, k := range cycle {
+			if k == m.kind {
+				m.kind = cycle[i+1]
+				break
+			}
+		}
+	}
+	return m.setItems()
+}
+
+// loadMore raises the result limit by another page of entries.
+func (m *Model) loadMore() tea.Cmd {
+	if m.limit <= 0 || m.deepQuery != "" || len(m.kindEntries()) <= m.limit {
+		return nil
+	}
+	m.limit += m.pageSize
+	return m.setItems()
+}
+
+// newItem returns the list item for e, with its note and tags.
+func (m *Model) newItem(e workspace.Entry) item {
+	return item{entry: e, note: m.notes[e.Name], tags: m.tags[e.Name]}
+}
+
+// setItems replaces the list contents with the current entries. The result
+// limit cuts the entries themselves only while nothing is filtered; a
+// filter searches them all and the limit keeps its best matches.
+func (m *Model) setItems() tea.Cmd {
+	var items []list.Item
+	if m.deepQuery != "" {
+		items = make([]list.Item, len(m.matches))
+		for i, fm := range m.matches {
+			it := m.newItem(fm.Entry)
+			it.match = fm.File
+			items[i] = it
+		}
+	} else {
+		entries := m.kindEntries()
+		m.list.Filter = limitFilter(m.fields.filter, m.limit)
+		if m.limit > 0 && len(entries) > m.limit && m.list.FilterState() == list.Unfiltered {
+			entries = entries[:m.limit]
+		}
+		if m.grouped {
+			items = groupedItems(entries, m.newItem)
+		} else {
+			items = make([]list.Item, len(entries))
+			for i, e := range entries {
+				items[i] = m.newItem(e)
+			}
+		}
+		if !m.loading {
+			m.list.Title = m.baseTitle()
+		}
+	}
+	cmd := m.list.SetItems(items)
+	m.skipHeaders(0)
+	return cmd
+}
+
 func (m *Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	// Handle delete confirmation state
 	if m.state == StateDeleteConfirm {
 		return m.handleDeleteConfirmKey(msg)
 	}
+	if m.state == StateClonePrompt {
+		return m.handleClonePromptKey(msg)
+	}
+	if m.state == StateNoteEdit {
+		return m.handleNoteEditKey(msg)
+	}
+	if m.state == StateTagEdit {
+		return m.handleTagEditKey(msg)
+	}
+	if m.state == StateScanError {
+		return m.handleScanErrorKey(msg)
+	}
 
 	switch msg.String() {
 	case "ctrl+c":
 		m.action = &Action{Type: ActionCancel}
 		return m, tea.Quit
 
-	case "esc":
-		// Let list handle esc (exits filter mode)
-		if m.list.FilterState() == list.Filtering {
-			var cmd tea.Cmd
-			m.list, cmd = m.list.Update(msg)
-			return m, cmd
-		}
-		m.action = &Action{Type: ActionCancel}
-		return m, tea.Quit
-
 	case "enter":
 		return m.handleSelect()
+	}
+
+	// Digits open the nth entry while the list is unfiltered
+	if key.Matches(msg, jumpKey) && m.list.FilterState() == list.Unfiltered && m.deepQuery == "" && !m.isEmpty() {
+		return m.jumpTo(int(msg.Runes[0] - '0'))
+	}
+
+	// Printable keys belong to the filter while typing, even if bound
+	filtering := m.list.FilterState() == list.Filtering
+	if !filtering || msg.Type != tea.KeyRunes {
+		switch {
+		case key.Matches(msg, m.keys.Quit) && !(m.isEmpty() && msg.Type == tea.KeyRunes):
+			// A printable quit key like q still starts naming a new
+			// workspace when there is nothing to browse (see below).
+			// Let list handle quit keys like esc while filtering (exits filter mode)
+			if filtering {
+				return m, m.updateList(msg)
+			}
+			if m.deepQuery != "" {
+				return m, m.exitSearch()
+			}
+			m.action = &Action{Type: ActionCancel}
+			return m, tea.Quit
+
+		case key.Matches(msg, m.keys.Delete):
+			return m.handleDelete()
+
+		case key.Matches(msg, m.keys.New):
+			// Create new with current filter text
+			return m.handleCreateNew(false)
+
+		case key.Matches(msg, m.keys.NewUndated):
+			// Create new without the date prefix
+			return m.handleCreateNew(true)
+
+		case key.Matches(msg, m.keys.Group):
+			if !filtering {
+				m.grouped = !m.grouped
+				return m, m.setItems()
+			}
+
+		case key.Matches(msg, m.keys.Kind):
+			if !filtering && m.deepQuery == "" {
+				return m, m.cycleKind()
+			}
+
+		case key.Matches(msg, m.keys.Repos):
+			if !filtering && m.deepQuery == "" {
+				m.reposOnly = !m.reposOnly
+				return m, m.setItems()
+			}
 
-	case "ctrl+d":
-		return m.handleDelete()
+		case key.Matches(msg, m.keys.Hidden):
+			if !filtering && m.deepQuery == "" && !m.loading {
+				m.showHidden = !m.showHidden
+				return m, m.refresh()
+			}
+
+		case key.Matches(msg, m.keys.Clone):
+			m.cloneURL = ""
+			m.cloneErr = ""
+			m.state = StateClonePrompt
+			return m, nil
+
+		case key.Matches(msg, m.keys.More):
+			return m, m.loadMore()
+
+		case key.Matches(msg, m.keys.Refresh):
+			return m, m.refresh()
+
+		case key.Matches(msg, m.keys.Remote):
+			if i, ok := m.list.SelectedItem().(item); ok {
+				return m, yankRemote(i.entry.Path)
+			}
+
+		case key.Matches(msg, m.keys.Base):
+			m.action = &Action{Type: ActionCDBase, Path: m.basePath, BaseDir: m.basePath}
+			return m, tea.Quit
+
+		case key.Matches(msg, m.keys.Info):
+			return m, m.toggleDetail()
+
+		case key.Matches(msg, m.keys.Note):
+			return m.handleEditNote()
+
+		case key.Matches(msg, m.keys.Tags):
+			return m.handleEditTags()
 
-	case "ctrl+n":
-		// Create new with current filter text
-		return m.handleCreateNew()
+		case key.Matches(msg, m.keys.Search):
+			if m.deepQuery != "" {
+				return m, m.exitSearch()
+			}
+			return m, m.startSearch()
+		}
+	}
+
+	// With nothing to browse, typing starts naming a new workspace
+	if m.isEmpty() && msg.Type == tea.KeyRunes {
+		cmd := m.startFiltering()
+		var inputCmd tea.Cmd
+		m.list, inputCmd = m.list.Update(msg)
+		return m, tea.Batch(cmd, inputCmd)
 	}
 
 	// Pass to list for filtering/navigation
+	prev := m.list.Index()
+	cmd := m.updateList(msg)
+	m.skipHeaders(prev)
+	return m, tea.Batch(cmd, m.syncDetail())
+}
+
+// updateList passes msg to the list, and resets the items when that
+// starts or clears a filter, since a filter searches every entry rather
+// than those within the result limit.
+func (m *Model) updateList(msg tea.Msg) tea.Cmd {
+	wasUnfiltered := m.list.FilterState() == list.Unfiltered
 	var cmd tea.Cmd
 	m.list, cmd = m.list.Update(msg)
-	return m, cmd
+	if wasUnfiltered != (m.list.FilterState() == list.Unfiltered) {
+		return tea.Batch(cmd, m.setItems())
+	}
+	return cmd
+}
+
+// applyInitialQuery opens the filter on the query the picker was started
+// with, once the first entries are in, and then forgets it so later loads
+// leave the filter as the user left it.
+func (m *Model) applyInitialQuery() tea.Cmd {
+	if m.initialQuery == "" {
+		return nil
+	}
+	// With text already in the input, the list filters on it right away
+	// rather than first listing everything
+	m.list.FilterInput.SetValue(m.initialQuery)
+	m.initialQuery = ""
+	m.list.KeyMap.Filter.SetEnabled(true)
+	return m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+}
+
+// startFiltering switches the list into filter mode. The list disables its
+// filter key when it has no items, so it is re-enabled first.
+func (m *Model) startFiltering() tea.Cmd {
+	m.list.KeyMap.Filter.SetEnabled(true)
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	return cmd
+}
+
+// startSearch looks for the filter text among the file names inside each
+// workspace. The search runs in the background and its matches replace the
+// list until exitSearch.
+func (m *Model) startSearch() tea.Cmd {
+	query := strings.TrimSpace(m.list.FilterValue())
+	if query == "" {
+		return nil
+	}
+
+	m.deepQuery = query
+	m.matches = nil
+	m.list.ResetFilter()
+	m.list.Title = fmt.Sprintf("%s Try · searching files for %q", IconHome, query)
+
+	entries := append([]workspace.Entry(nil), m.entries...)
+	return tea.Batch(m.setItems(), func() tea.Msg {
+		return searchResultMsg{
+			query: query,
+			matches: workspace.SearchFiles(entries, query, workspace.SearchOptions{
+				MaxFiles: searchMaxFiles,
+				Timeout:  searchTimeout,
+			}),
+		}
+	})
+}
+
+// exitSearch leaves deep search and restores the full list.
+func (m *Model) exitSearch() tea.Cmd {
+	m.deepQuery = ""
+	m.matches = nil
+	return m.setItems()
 }
 
 func (m *Model) handleSelect() (tea.Model, tea.Cmd) {
@@ -354,7 +1206,13 @@ func (m *Model) handleSelect() (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
-	i := selected.(item)
+	i, ok := selected.(item)
+	if !ok {
+		return m, nil
+	}
+	if i.entry.Broken {
+		return m, m.list.NewStatusMessage(i.entry.Name + " is a broken link; delete it with " + m.keys.Delete.Help().Key)
+	}
 	m.action = &Action{
 		Type:    ActionCD,
 		Path:    i.entry.Path,
@@ -364,7 +1222,22 @@ func (m *Model) handleSelect() (tea.Model, tea.Cmd) {
 	return m, tea.Quit
 }
 
-func (m *Model) handleCreateNew() (tea.Model, tea.Cmd) {
+// jumpTo chooses the nth entry of the list, counting from 1 and skipping
+// group headers. It does nothing if the list is shorter.
+func (m *Model) jumpTo(n int) (tea.Model, tea.Cmd) {
+	for idx, li := range m.list.Items() {
+		if _, ok := li.(item); !ok {
+			continue
+		}
+		if n--; n == 0 {
+			m.list.Select(idx)
+			return m.handleSelect()
+		}
+	}
+	return m, nil
+}
+
+func (m *Model) handleCreateNew(noDate bool) (tea.Model, tea.Cmd) {
 	filterValue := m.list.FilterValue()
 	if filterValue == "" {
 		return m, nil
@@ -374,6 +1247,7 @@ func (m *Model) handleCreateNew() (tea.Model, tea.Cmd) {
 		Type:    ActionCreate,
 		Path:    filterValue,
 		BaseDir: m.basePath,
+		NoDate:  noDate,
 	}
 	return m, tea.Quit
 }
@@ -384,24 +1258,80 @@ func (m *Model) handleDelete() (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
-	i := selected.(item)
+	i, ok := selected.(item)
+	if !ok {
+		return m, nil
+	}
 	m.deleteTarget = i.entry.Path
 	m.deleteConfirm = ""
+	m.deleteUsage = nil
 	m.state = StateDeleteConfirm
 
-	return m, nil
+	return m, countUsage(i.entry.Path, m.usage)
+}
+
+// deleteConfirmWord must be typed to confirm a delete.
+const deleteConfirmWord = "YES"
+
+// usageMaxFiles caps the walk that sizes up a delete, so huge workspaces
+// don't keep the disk busy; beyond it the bar shows "1000+ files".
+const usageMaxFiles = 1000
+
+// yankRemote looks up the origin remote of the workspace at path and
+// copies it to the clipboard if there is one to copy to.
+func yankRemote(path string) tea.Cmd {
+	return func() tea.Msg {
+		url, err := workspace.GitRemote(path)
+		if err != nil {
+			return remoteMsg{err: err}
+		}
+		return remoteMsg{url: url, copied: clipboard.WriteAll(url) == nil}
+	}
+}
+
+// countUsage sizes up path in the background for the delete bar.
+func countUsage(path string, opts workspace.UsageOptions) tea.Cmd {
+	return func() tea.Msg {
+		usage, err := workspace.DirUsageWithOptions(path, opts)
+		if err != nil {
+			return nil
+		}
+		return usageMsg{path: path, usage: usage}
+	}
+}
+
+// describeUsage summarizes u for the delete bar, e.g. "12 files, 3.4 MB".
+// A walk that stopped early or skipped directories is marked with "+".
+func describeUsage(u workspace.Usage) string {
+	more := ""
+	if u.Truncated || u.Approximate {
+		more = "+"
+	}
+	files := "files"
+	if u.Files == 1 && more == "" {
+		files = "file"
+	}
+	return fmt.Sprintf("%d%s %s, %s", u.Files, more, files, u.Size())
 }
 
 func (m *Model) handleDeleteConfirmKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.Type {
+	case tea.KeyCtrlC:
+		m.action = &Action{Type: ActionCancel}
+		return m, tea.Quit
+
 	case tea.KeyEscape:
 		m.state = StateSelector
 		m.deleteTarget = ""
 		m.deleteConfirm = ""
+		m.deleteUsage = nil
 		return m, nil
 
 	case tea.KeyEnter:
-		if m.deleteConfirm == "YES" {
+		if m.deleteConfirm == deleteConfirmWord && m.sticky && !m.dryRun {
+			return m, m.trashInPlace()
+		}
+		if m.deleteConfirm == deleteConfirmWord && !m.dryRun {
 			m.action = &Action{
 				Type:    ActionDelete,
 				Paths:   []string{m.deleteTarget},
@@ -409,20 +1339,210 @@ func (m *Model) handleDeleteConfirmKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 			return m, tea.Quit
 		}
-		// Wrong confirmation, go back
+		// Wrong confirmation, or a dry run, go back
 		m.state = StateSelector
 		m.deleteTarget = ""
 		m.deleteConfirm = ""
+		m.deleteUsage = nil
+		return m, nil
+
+	case tea.KeyBackspace:
+		m.deleteConfirm = dropLastRune(m.deleteConfirm)
+		return m, nil
+
+	case tea.KeyRunes:
+		if !m.dryRun {
+			m.deleteConfirm += string(msg.Runes)
+		}
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// trashInPlace moves the delete target to the trash without leaving the
+// picker, and drops it from the list.
+func (m *Model) trashInPlace() tea.Cmd {
+	target := m.deleteTarget
+	m.state = StateSelector
+	m.deleteTarget = ""
+	m.deleteConfirm = ""
+	m.deleteUsage = nil
+
+	name := filepath.Base(target)
+	if _, err := workspace.Trash(m.basePath, target); err != nil {
+		return m.list.NewStatusMessage(fmt.Sprintf("failed to delete %s: %v", name, err))
+	}
+
+	for i, e := range m.entries {
+		if e.Path == target {
+			delete(m.notes, e.Name)
+			delete(m.tags, e.Name)
+			m.entries = append(m.entries[:i], m.entries[i+1:]...)
+			break
+		}
+	}
+	return tea.Batch(m.setItems(), m.list.NewStatusMessage("moved "+name+" to trash"))
+}
+
+func (m *Model) handleEditNote() (tea.Model, tea.Cmd) {
+	i, ok := m.list.SelectedItem().(item)
+	if !ok {
+		return m, nil
+	}
+	m.noteTarget = i.entry.Name
+	m.noteInput = m.notes[i.entry.Name]
+	m.state = StateNoteEdit
+	return m, nil
+}
+
+// handleScanErrorKey retries a failed scan on r and quits on q.
+func (m *Model) handleScanErrorKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "r":
+		m.state = StateSelector
+		m.scanErr = nil
+		return m, tea.Batch(m.loadEntries(), m.spinner.Tick)
+	case "q", "esc", "ctrl+c":
+		m.action = &Action{Type: ActionCancel}
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m *Model) handleNoteEditKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		m.action = &Action{Type: ActionCancel}
+		return m, tea.Quit
+
+	case tea.KeyEscape:
+		m.state = StateSelector
+		m.noteTarget = ""
+		m.noteInput = ""
+		return m, nil
+
+	case tea.KeyEnter:
+		if err := workspace.SetNote(m.basePath, m.noteTarget, m.noteInput); err != nil {
+			m.err = fmt.Errorf("failed to save note: %w", err)
+			return m, tea.Quit
+		}
+		if m.notes == nil {
+			m.notes = map[string]string{}
+		}
+		if note := strings.TrimSpace(m.noteInput); note != "" {
+			m.notes[m.noteTarget] = note
+		} else {
+			delete(m.notes, m.noteTarget)
+		}
+		m.state = StateSelector
+		m.noteTarget = ""
+		m.noteInput = ""
+
+		// Rebuilding the items keeps the cursor where it is
+		return m, m.setItems()
+
+	case tea.KeyBackspace:
+		m.noteInput = dropLastRune(m.noteInput)
+		return m, nil
+
+	case tea.KeyRunes, tea.KeySpace:
+		m.noteInput += string(msg.Runes)
+		return m, nil
+	}
+
+	return m, nil
+}
+
+func (m *Model) handleEditTags() (tea.Model, tea.Cmd) {
+	i, ok := m.list.SelectedItem().(item)
+	if !ok {
+		return m, nil
+	}
+	m.tagTarget = i.entry.Name
+	m.tagInput = strings.Join(i.tags, " ")
+	m.state = StateTagEdit
+	return m, nil
+}
+
+func (m *Model) handleTagEditKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		m.action = &Action{Type: ActionCancel}
+		return m, tea.Quit
+
+	case tea.KeyEscape:
+		m.state = StateSelector
+		m.tagTarget = ""
+		m.tagInput = ""
 		return m, nil
 
+	case tea.KeyEnter:
+		if err := workspace.SetTags(m.basePath, m.tagTarget, workspace.ParseTags(m.tagInput)); err != nil {
+			m.err = fmt.Errorf("failed to save tags: %w", err)
+			return m, tea.Quit
+		}
+		// Reread rather than duplicate SetTags' normalizing
+		m.tags, _ = workspace.LoadTags(m.basePath)
+		m.state = StateSelector
+		m.tagTarget = ""
+		m.tagInput = ""
+
+		// Rebuilding the items keeps the cursor where it is
+		return m, m.setItems()
+
 	case tea.KeyBackspace:
-		if len(m.deleteConfirm) > 0 {
-			m.deleteConfirm = m.deleteConfirm[:len(m.deleteConfirm)-1]
+		m.tagInput = dropLastRune(m.tagInput)
+		return m, nil
+
+	case tea.KeyRunes, tea.KeySpace:
+		m.tagInput += string(msg.Runes)
+		return m, nil
+	}
+
+	return m, nil
+}
+
+func (m *Model) handleClonePromptKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		m.action = &Action{Type: ActionCancel}
+		return m, tea.Quit
+
+	case tea.KeyEscape:
+		m.state = StateSelector
+		m.cloneURL = ""
+		m.cloneErr = ""
+		return m, nil
+
+	case tea.KeyEnter:
+		url := strings.TrimSpace(m.cloneURL)
+		if _, err := workspace.ParseGitURL(url); err != nil {
+			m.cloneErr = err.Error()
+			return m, nil
+		}
+		path, cloneURL, err := workspace.CloneScript(m.basePath, url, m.flatClones)
+		if err != nil {
+			m.cloneErr = err.Error()
+			return m, nil
 		}
+		m.action = &Action{
+			Type:    ActionClone,
+			Path:    path,
+			URL:     cloneURL,
+			BaseDir: m.basePath,
+		}
+		return m, tea.Quit
+
+	case tea.KeyBackspace:
+		m.cloneURL = dropLastRune(m.cloneURL)
+		m.cloneErr = ""
 		return m, nil
 
 	case tea.KeyRunes:
-		m.deleteConfirm += string(msg.Runes)
+		// Pastes arrive as a single runes message
+		m.cloneURL += string(msg.Runes)
+		m.cloneErr = ""
 		return m, nil
 	}
 
@@ -432,7 +1552,19 @@ func (m *Model) handleDeleteConfirmKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 // View implements tea.Model.
 func (m *Model) View() string {
 	if m.width == 0 || m.height == 0 {
-		return "Loading..."
+		return m.spinner.View() + " Loading..."
+	}
+
+	if m.state == StateScanError {
+		return m.viewScanError()
+	}
+
+	// Nothing to show yet; batches render as soon as they arrive
+	if m.loading && len(m.entries) == 0 {
+		content := m.spinner.View() + lipgloss.NewStyle().
+			Foreground(m.theme.TextDim).
+			Render(" Loading workspaces...")
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, content)
 	}
 
 	// Delete confirmation bar at top
@@ -440,19 +1572,121 @@ func (m *Model) View() string {
 		bar := m.viewDeleteBar()
 		return bar + "\n" + m.list.View()
 	}
+	if m.state == StateClonePrompt {
+		return m.viewClonePrompt() + "\n" + m.list.View()
+	}
+	if m.state == StateNoteEdit {
+		return m.viewNoteEdit() + "\n" + m.list.View()
+	}
+	if m.state == StateTagEdit {
+		return m.viewTagEdit() + "\n" + m.list.View()
+	}
+
+	if m.isEmpty() {
+		return m.viewEmpty()
+	}
 
+	if m.showDetail {
+		return m.list.View() + "\n" + m.viewDetail()
+	}
 	return m.list.View()
 }
 
+// isEmpty reports whether loading finished with no workspaces and the user
+// hasn't started typing a name yet.
+func (m *Model) isEmpty() bool {
+	return !m.loading && len(m.entries) == 0 && m.list.FilterState() == list.Unfiltered
+}
+
+// viewScanError explains why the tries directory couldn't be read.
+func (m *Model) viewScanError() string {
+	title := lipgloss.NewStyle().
+		Foreground(m.theme.Error).
+		Bold(true).
+		Render(IconHome + " Couldn't read " + m.basePath)
+
+	reason := lipgloss.NewStyle().
+		Foreground(m.theme.Text).
+		Width(max(m.width-4, 20)).
+		Align(lipgloss.Center).
+		Render(m.scanErr.Error())
+
+	advice := "Check that the directory is reachable, such as a mounted drive."
+	switch {
+	case errors.Is(m.scanErr, fs.ErrPermission):
+		advice = "Check the directory's permissions, or point --path or TRY_PATH elsewhere."
+	case errors.Is(m.scanErr, fs.ErrNotExist):
+		advice = "Create the directory, or point --path or TRY_PATH elsewhere."
+	}
+	hint := lipgloss.NewStyle().
+		Foreground(m.theme.TextDim).
+		Render(advice)
+
+	keys := lipgloss.NewStyle().
+		Foreground(m.theme.TextMuted).
+		Render("r to retry  ·  q to quit")
+
+	content := lipgloss.JoinVertical(lipgloss.Center, title, "", reason, hint, "", keys)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, content)
+}
+
+func (m *Model) viewEmpty() string {
+	title := lipgloss.NewStyle().
+		Foreground(m.theme.Accent).
+		Bold(true).
+		Render(IconHome + " No workspaces yet")
+
+	hint := lipgloss.NewStyle().
+		Foreground(m.theme.TextDim).
+		Render("Type a name and press enter (or ctrl+n) to create your first one.")
+
+	path := lipgloss.NewStyle().
+		Foreground(m.theme.TextMuted).
+		Render(m.basePath + "  ·  esc to quit")
+
+	content := lipgloss.JoinVertical(lipgloss.Center, title, "", hint, path)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, content)
+}
+
 func (m *Model) viewDeleteBar() string {
+	if m.dryRun {
+		return m.viewDryRunBar()
+	}
+
 	name := filepath.Base(m.deleteTarget)
+	if m.deleteUsage != nil {
+		name += " (" + describeUsage(*m.deleteUsage) + ")"
+	}
 
 	// Build plain text content - bar style handles all formatting
-	content := fmt.Sprintf("%s DELETE %s  Type YES: %s█  (esc to cancel)", IconTrash, name, m.deleteConfirm)
+	content := fmt.Sprintf("%s DELETE %s  Type %s: %s█  (esc to cancel)", IconTrash, name, deleteConfirmWord, m.deleteConfirm)
+
+	// Once the word is typed, show that enter will go through
+	confirmed := m.deleteConfirm == deleteConfirmWord
+	if confirmed {
+		content = fmt.Sprintf("%s DELETE %s  Press enter to confirm  (esc to cancel)", IconTrash, name)
+	}
+
+	// Without a danger background, text markers have to carry the warning
+	if m.noColor {
+		marker := "!!"
+		if confirmed {
+			marker = ">>"
+		}
+		return lipgloss.NewStyle().
+			Bold(true).
+			Width(m.width).
+			Render(marker + " " + content + " " + marker)
+	}
+
+	background := m.theme.BackgroundDanger
+	if confirmed {
+		background = m.theme.Success
+	}
 
 	// Full-width bar with danger background
 	bar := lipgloss.NewStyle().
-		Background(m.theme.BackgroundDanger).
+		Background(background).
 		Foreground(m.theme.Text).
 		Bold(true).
 		Width(m.width).
@@ -462,6 +1696,67 @@ func (m *Model) viewDeleteBar() string {
 	return bar
 }
 
+// viewDryRunBar is the delete bar of a dry run, which only says what
+// deleting would free.
+func (m *Model) viewDryRunBar() string {
+	freeing := "counting…"
+	if m.deleteUsage != nil {
+		freeing = "freeing " + m.deleteUsage.Size()
+	}
+	content := fmt.Sprintf("%s DRY RUN: would delete %s, %s  (enter or esc to go back)",
+		IconTrash, filepath.Base(m.deleteTarget), freeing)
+
+	if m.noColor {
+		return lipgloss.NewStyle().Bold(true).Width(m.width).Render(content)
+	}
+	return lipgloss.NewStyle().
+		Foreground(m.theme.Warning).
+		Bold(true).
+		Width(m.width).
+		Padding(0, 1).
+		Render(content)
+}
+
+func (m *Model) viewClonePrompt() string {
+	content := fmt.Sprintf("Clone git URL: %s█  (enter to clone, esc to cancel)", m.cloneURL)
+
+	bar := lipgloss.NewStyle().
+		Foreground(m.theme.Primary).
+		Bold(true).
+		Padding(0, 1).
+		Render(content)
+
+	if m.cloneErr != "" {
+		bar += lipgloss.NewStyle().
+			Foreground(m.theme.Error).
+			Render("✗ " + m.cloneErr)
+	}
+
+	return bar
+}
+
+func (m *Model) viewNoteEdit() string {
+	content := fmt.Sprintf("Note for %s: %s█  (enter to save, empty to clear, esc to cancel)",
+		filepath.Base(m.noteTarget), m.noteInput)
+
+	return lipgloss.NewStyle().
+		Foreground(m.theme.Primary).
+		Bold(true).
+		Padding(0, 1).
+		Render(content)
+}
+
+func (m *Model) viewTagEdit() string {
+	content := fmt.Sprintf("Tags for %s: %s█  (space-separated, enter to save, esc to cancel)",
+		filepath.Base(m.tagTarget), m.tagInput)
+
+	return lipgloss.NewStyle().
+		Foreground(m.theme.Primary).
+		Bold(true).
+		Padding(0, 1).
+		Render(content)
+}
+
 // GetAction returns the selected action after the TUI exits.
 func (m *Model) GetAction() *Action {
 	return m.action
@@ -471,21 +1766,3 @@ func (m *Model) GetAction() *Action {
 func (m *Model) GetError() error {
 	return m.err
 }
-
-func formatRelativeTime(t time.Time) string {
-	d := time.Since(t)
-
-	if d < time.Minute {
-		return "just now"
-	}
-	if d < time.Hour {
-		return fmt.Sprintf("%dm ago", int(d.Minutes()))
-	}
-	if d < 24*time.Hour {
-		return fmt.Sprintf("%dh ago", int(d.Hours()))
-	}
-	if d < 7*24*time.Hour {
-		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
-	}
-	return fmt.Sprintf("%dw ago", int(d.Hours()/24/7))
-}
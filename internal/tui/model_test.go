@@ -0,0 +1,863 @@
+package tui
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/tobi/try/internal/workspace"
+)
+
+func TestNoColorDeleteBar(t *testing.T) {
+	m := New(t.TempDir(), WithNoColor(true))
+	m.width = 80
+	m.deleteTarget = "/tmp/tries/2025-01-19-redis"
+	m.deleteConfirm = "YE"
+
+	bar := m.viewDeleteBar()
+	if strings.Contains(bar, "\x1b[") && strings.Contains(bar, "48;") {
+		t.Errorf("delete bar has a background color: %q", bar)
+	}
+	if !strings.HasPrefix(bar, "!! ") || !strings.Contains(bar, "DELETE 2025-01-19-redis") {
+		t.Errorf("delete bar = %q, want text markers", bar)
+	}
+}
+
+func TestNoColorSelectionMarker(t *testing.T) {
+	entries := []list.Item{
+		item{entry: workspace.Entry{Name: "first"}},
+		item{entry: workspace.Entry{Name: "second"}},
+	}
+	d := itemDelegate{styles: newDelegateStyles(New("").theme, true)}
+	l := list.New(entries, d, 40, 10)
+
+	var selected, other bytes.Buffer
+	d.Render(&selected, l, 0, entries[0])
+	d.Render(&other, l, 1, entries[1])
+
+	if !strings.Contains(selected.String(), "> first") {
+		t.Errorf("selected row = %q, want cursor marker", selected.String())
+	}
+	if strings.Contains(other.String(), ">") {
+		t.Errorf("unselected row = %q, should have no marker", other.String())
+	}
+}
+
+func TestClonePrompt(t *testing.T) {
+	base := t.TempDir()
+	m := New(base)
+
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlG})
+	if m.state != StateClonePrompt {
+		t.Fatalf("ctrl+g should open the clone prompt, state = %v", m.state)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("not a url")})
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.cloneErr == "" || m.action != nil {
+		t.Fatalf("invalid URL should show an error, got err=%q action=%+v", m.cloneErr, m.action)
+	}
+
+	for range "not a url" {
+		m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+
+	// Looks like a git URL, but has no user and repo to name it by
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("ssh://github.com/repo")})
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.action != nil || !strings.Contains(m.viewClonePrompt(), "unable to parse git URL") {
+		t.Fatalf("unparseable URL should show the parse error, got action=%+v err=%q", m.action, m.cloneErr)
+	}
+	for range "ssh://github.com/repo" {
+		m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("git@github.com:user/repo.git")})
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	a := m.GetAction()
+	if a == nil || a.Type != ActionClone {
+		t.Fatalf("expected clone action, got %+v", a)
+	}
+	if a.URL != "git@github.com:user/repo.git" || !strings.HasPrefix(a.Path, base) || !strings.HasSuffix(a.Path, "-user-repo") {
+		t.Errorf("unexpected clone action %+v", a)
+	}
+
+	// As with clone --flat, the name can leave the date out
+	m = New(base, WithFlatClones(true))
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlG})
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("git@github.com:user/repo.git")})
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if a := m.GetAction(); a == nil || a.Path != filepath.Join(base, "user-repo") {
+		t.Errorf("flat clone action %+v, want %s", a, filepath.Join(base, "user-repo"))
+	}
+}
+
+func TestDeleteConfirm(t *testing.T) {
+	m := New(t.TempDir(), WithNoColor(true))
+	m.width = 80
+	m.state = StateDeleteConfirm
+	m.deleteTarget = "/tmp/tries/redis"
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("YE")})
+	if strings.Contains(m.viewDeleteBar(), "Press enter") {
+		t.Error("bar should not offer enter before the word is complete")
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("S")})
+	if bar := m.viewDeleteBar(); !strings.Contains(bar, "Press enter") || !strings.HasPrefix(bar, ">> ") {
+		t.Errorf("bar should signal confirmation once YES is typed, got %q", bar)
+	}
+
+	// A wrong word still goes back to the selector
+	m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.state != StateSelector || m.action != nil {
+		t.Errorf("wrong word should return to the selector, state=%v action=%+v", m.state, m.action)
+	}
+
+	m.state = StateDeleteConfirm
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	if a := m.GetAction(); a == nil || a.Type != ActionCancel {
+		t.Errorf("ctrl+c should cancel the program, got %+v", a)
+	}
+}
+
+func TestDeleteUsage(t *testing.T) {
+	base := t.TempDir()
+	path := base + "/2025-01-19-redis"
+	os.MkdirAll(path, 0755)
+	os.WriteFile(path+"/dump.rdb", make([]byte, 2500), 0644)
+
+	m := New(base, WithNoColor(true))
+	m.width = 120
+	m.entries = []workspace.Entry{{Name: "2025-01-19-redis", Path: path}}
+	m.Update(scanDoneMsg{})
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlD})
+	if m.state != StateDeleteConfirm || cmd == nil {
+		t.Fatalf("ctrl+d should ask for confirmation and start counting, state = %v", m.state)
+	}
+	if strings.Contains(m.viewDeleteBar(), "file") {
+		t.Error("bar should not show a count before it is known")
+	}
+
+	m.Update(cmd())
+	if bar := m.viewDeleteBar(); !strings.Contains(bar, "(1 file, 2.5 kB)") {
+		t.Errorf("bar should summarize the contents, got %q", bar)
+	}
+
+	// A count arriving after the delete was left is ignored
+	m.Update(tea.KeyMsg{Type: tea.KeyEscape})
+	m.Update(usageMsg{path: path})
+	if m.deleteUsage != nil {
+		t.Error("count should be dropped once the delete is left")
+	}
+}
+
+func TestDryRunDelete(t *testing.T) {
+	base := t.TempDir()
+	path := base + "/2025-01-19-redis"
+	os.MkdirAll(path, 0755)
+	os.WriteFile(path+"/dump.rdb", make([]byte, 2500), 0644)
+
+	m := New(base, WithNoColor(true), WithDryRun(true), WithSticky(true))
+	m.width = 120
+	m.entries = []workspace.Entry{{Name: "2025-01-19-redis", Path: path}}
+	m.Update(scanDoneMsg{})
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlD})
+	if bar := m.viewDeleteBar(); !strings.Contains(bar, "DRY RUN") || !strings.Contains(bar, "counting") {
+		t.Errorf("bar should say it's a dry run while counting, got %q", bar)
+	}
+	m.Update(cmd())
+	if bar := m.viewDeleteBar(); !strings.Contains(bar, "would delete 2025-01-19-redis, freeing 2.5 kB") {
+		t.Errorf("bar should say what would be freed, got %q", bar)
+	}
+
+	// Even the confirmation word deletes nothing
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("YES")})
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.state != StateSelector || m.action != nil {
+		t.Errorf("enter should go back to the list, state=%v action=%+v", m.state, m.action)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("a dry run must not delete: %v", err)
+	}
+}
+
+func TestStickyDelete(t *testing.T) {
+	base := t.TempDir()
+	var entries []workspace.Entry
+	for _, name := range []string{"a", "b", "c"} {
+		os.Mkdir(base+"/"+name, 0755)
+		entries = append(entries, workspace.Entry{Name: name, Path: base + "/" + name})
+	}
+
+	m := New(base, WithSticky(true))
+	m.entries = entries
+	m.Update(scanDoneMsg{})
+
+	for _, want := range []int{2, 1} {
+		m.Update(tea.KeyMsg{Type: tea.KeyCtrlD})
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("YES")})
+		m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+		if m.action != nil || m.state != StateSelector {
+			t.Fatalf("sticky delete should stay in the picker, action=%+v state=%v", m.action, m.state)
+		}
+		if n := len(m.list.Items()); n != want {
+			t.Errorf("expected %d items left, got %d", want, n)
+		}
+	}
+	if _, err := os.Stat(base + "/a"); !os.IsNotExist(err) {
+		t.Error("deleted workspace should be in the trash")
+	}
+
+	// Choosing still exits
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if a := m.GetAction(); a == nil || a.Type != ActionCD || a.Path != base+"/c" {
+		t.Errorf("enter should still select, got %+v", a)
+	}
+}
+
+func TestRemoteKey(t *testing.T) {
+	base := t.TempDir()
+	os.Mkdir(base+"/plain", 0755)
+
+	m := New(base, WithNoColor(true))
+	m.Update(tea.WindowSizeMsg{Width: 100, Height: 20})
+	m.entries = []workspace.Entry{{Name: "plain", Path: base + "/plain"}}
+	m.Update(scanDoneMsg{})
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlY})
+	if cmd == nil {
+		t.Fatal("ctrl+y should look up the remote")
+	}
+	m.Update(cmd())
+	if view := m.View(); !strings.Contains(view, "not a git repo") {
+		t.Errorf("expected a gentle message for a non-repo, got:\n%s", view)
+	}
+
+	m.Update(remoteMsg{url: "git@github.com:user/repo.git", copied: true})
+	if view := m.View(); !strings.Contains(view, "copied git@github.com:user/repo.git") {
+		t.Errorf("expected the copied URL in the status bar, got:\n%s", view)
+	}
+}
+
+func TestDescribeUsage(t *testing.T) {
+	tests := []struct {
+		usage workspace.Usage
+		want  string
+	}{
+		{workspace.Usage{}, "0 files, 0 B"},
+		{workspace.Usage{Files: 1, Bytes: 999}, "1 file, 999 B"},
+		{workspace.Usage{Files: 12, Bytes: 3_400_000}, "12 files, 3.4 MB"},
+		{workspace.Usage{Files: 1000, Bytes: 2_100_000_000, Truncated: true}, "1000+ files, 2.1 GB+"},
+		{workspace.Usage{Files: 1, Bytes: 40, Approximate: true}, "1+ files, 40 B+"},
+	}
+	for _, tt := range tests {
+		if got := describeUsage(tt.usage); got != tt.want {
+			t.Errorf("describeUsage(%+v) = %q, want %q", tt.usage, got, tt.want)
+		}
+	}
+}
+
+func TestMaxResults(t *testing.T) {
+	m := New(t.TempDir(), WithMaxResults(2))
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		m.entries = append(m.entries, workspace.Entry{Name: name})
+	}
+	m.Update(scanDoneMsg{})
+
+	if n := len(m.list.Items()); n != 2 {
+		t.Fatalf("expected 2 items in the list, got %d", n)
+	}
+	if !strings.Contains(m.list.Title, "2 of 5") {
+		t.Errorf("title %q should say more entries exist", m.list.Title)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlL})
+	if n := len(m.list.Items()); n != 4 {
+		t.Errorf("expected 4 items after loading more, got %d", n)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlL})
+	if n := len(m.list.Items()); n != 5 {
+		t.Errorf("expected all 5 items, got %d", n)
+	}
+	if strings.Contains(m.list.Title, "of") {
+		t.Errorf("title %q should drop the indicator once everything is shown", m.list.Title)
+	}
+}
+
+func TestMaxResultsFilter(t *testing.T) {
+	m := New(t.TempDir(), WithMaxResults(2))
+	for _, name := range []string{"a", "b", "cache", "d", "ecache"} {
+		m.entries = append(m.entries, workspace.Entry{Name: name})
+	}
+	m.Update(scanDoneMsg{})
+
+	// Filtering searches past the limit, which then caps the matches
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	items := m.list.Items()
+	if len(items) != 5 {
+		t.Fatalf("expected every entry to be searched, got %d", len(items))
+	}
+	values := make([]string, len(items))
+	for i, it := range items {
+		values[i] = it.FilterValue()
+	}
+	if ranks := m.list.Filter("cache", values); len(ranks) != 2 {
+		t.Errorf("expected both matches, got %v", ranks)
+	}
+	if ranks := m.list.Filter("a", values); len(ranks) != 2 {
+		t.Errorf("expected matches capped at 2, got %v", ranks)
+	}
+
+	// Leaving the filter cuts the list back to the limit
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if n := len(m.list.Items()); n != 2 {
+		t.Errorf("expected 2 items after clearing the filter, got %d", n)
+	}
+}
+
+func TestInitialQuery(t *testing.T) {
+	m := New(t.TempDir(), WithInitialQuery("foo bar"))
+	m.entries = []workspace.Entry{{Name: "2025-01-19-foo-bar"}, {Name: "redis"}, {Name: "2025-01-17-queue"}}
+	_, cmd := m.Update(scanDoneMsg{})
+	runFilter(m, cmd)
+
+	if got := m.list.FilterValue(); got != "foo-bar" {
+		t.Errorf("filter = %q, want %q", got, "foo-bar")
+	}
+	if m.list.FilterState() != list.Filtering {
+		t.Errorf("filter state = %v, want filtering so the query can be edited", m.list.FilterState())
+	}
+	visible := m.list.VisibleItems()
+	if len(visible) != 1 || visible[0].(item).entry.Name != "2025-01-19-foo-bar" {
+		t.Errorf("expected the list narrowed to foo-bar, got %v", visible)
+	}
+
+	// Later loads leave the filter alone
+	m.list.FilterInput.SetValue("redis")
+	m.Update(scanDoneMsg{})
+	if got := m.list.FilterValue(); got != "redis" {
+		t.Errorf("filter after reload = %q", got)
+	}
+}
+
+// runFilter runs cmd, which must not block, and hands the filter matches
+// it produces back to m, as the program would.
+func runFilter(m *Model, cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	switch msg := cmd().(type) {
+	case tea.BatchMsg:
+		for _, c := range msg {
+			runFilter(m, c)
+		}
+	case list.FilterMatchesMsg:
+		m.Update(msg)
+	}
+}
+
+func TestQuitKey(t *testing.T) {
+	m := New(t.TempDir())
+	m.width, m.height = 200, 20
+	m.entries = []workspace.Entry{{Name: "redis"}, {Name: "queue"}}
+	m.Update(scanDoneMsg{})
+
+	if help := m.list.Help.ShortHelpView(m.list.ShortHelp()); !strings.Contains(help, "esc/q quit") {
+		t.Errorf("short help %q should say how to quit", help)
+	}
+
+	// While filtering, q is text
+	m.startFiltering()
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	if m.action != nil {
+		t.Fatalf("q while filtering should not quit, got %+v", m.action)
+	}
+	if got := m.list.FilterValue(); got != "q" {
+		t.Errorf("filter = %q, want %q", got, "q")
+	}
+
+	m.list.ResetFilter()
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	if m.action == nil || m.action.Type != ActionCancel || cmd == nil {
+		t.Errorf("q should quit outside filter mode, got %+v", m.action)
+	}
+}
+
+func TestQuitKeyEmpty(t *testing.T) {
+	m := New(t.TempDir())
+	m.Update(scanDoneMsg{})
+
+	// With nothing to browse, q starts naming a workspace
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	if m.action != nil {
+		t.Fatalf("q with no workspaces should not quit, got %+v", m.action)
+	}
+	if got := m.list.FilterValue(); got != "q" {
+		t.Errorf("filter = %q, want %q", got, "q")
+	}
+}
+
+func TestFilterMatchesNotes(t *testing.T) {
+	m := New(t.TempDir())
+	m.width, m.height = 80, 20
+	m.notes = map[string]string{"2025-01-10-api": "port 8080 demo"}
+	m.entries = []workspace.Entry{{Name: "2025-01-10-api"}, {Name: "2025-01-11-web"}}
+	m.Update(scanDoneMsg{})
+
+	ranks := labelFilter("8080", []string{m.list.Items()[0].FilterValue(), m.list.Items()[1].FilterValue()})
+	if len(ranks) != 1 || ranks[0].Index != 0 {
+		t.Errorf("8080 should find the workspace by its note, got %+v", ranks)
+	}
+	if len(ranks) == 1 && len(ranks[0].MatchedIndexes) != 0 {
+		t.Errorf("note match should not highlight the name, got %v", ranks[0].MatchedIndexes)
+	}
+}
+
+func TestEditTags(t *testing.T) {
+	base := t.TempDir()
+	m := New(base)
+	m.entries = []workspace.Entry{{Name: "redis"}, {Name: "web"}}
+	m.Update(scanDoneMsg{})
+
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	if m.state != StateTagEdit || m.tagTarget != "redis" {
+		t.Fatalf("ctrl+t should edit redis's tags, state = %v target = %q", m.state, m.tagTarget)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("#Work spike")})
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	tags, err := workspace.GetTags(base, "redis")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(tags, " ") != "spike work" {
+		t.Errorf("stored tags = %v", tags)
+	}
+	if it := m.list.Items()[0].(item); strings.Join(it.tags, " ") != "spike work" {
+		t.Errorf("row tags = %v", it.tags)
+	}
+
+	// The filter finds workspaces by tag
+	ranks := labelFilter("#work", []string{m.list.Items()[0].FilterValue(), m.list.Items()[1].FilterValue()})
+	if len(ranks) != 1 || ranks[0].Index != 0 {
+		t.Errorf("#work should find redis by its tag, got %+v", ranks)
+	}
+}
+
+func TestRenderAbsoluteTime(t *testing.T) {
+	mod := time.Date(2025, 1, 19, 14, 5, 0, 0, time.Local)
+	entries := []list.Item{item{entry: workspace.Entry{Name: "2025-01-19-redis", ModTime: mod}}}
+	m := New("", WithTimeFormat(workspace.TimeAbsolute))
+	d := itemDelegate{styles: newDelegateStyles(m.theme, true), timeFormat: m.timeFormat}
+	l := list.New(entries, d, 50, 10)
+
+	var buf bytes.Buffer
+	d.Render(&buf, l, 0, entries[0])
+	row := buf.String()
+
+	if w := lipgloss.Width(row); w != 50 {
+		t.Errorf("row is %d cells wide, want 50: %q", w, row)
+	}
+	if !strings.HasSuffix(strings.TrimRight(row, " "), "2025-01-19 14:05") {
+		t.Errorf("row %q should end with the absolute time", row)
+	}
+}
+
+func TestRenderLongName(t *testing.T) {
+	long := "2024-01-15-someorg-really-long-repository-name-that-goes-on"
+	entries := []list.Item{
+		item{entry: workspace.Entry{Name: "first"}},
+		item{entry: workspace.Entry{Name: long}, tags: []string{"work"}, note: "a note"},
+	}
+	d := itemDelegate{styles: newDelegateStyles(New("").theme, true)}
+	l := list.New(entries, d, 40, 10)
+
+	var buf bytes.Buffer
+	d.Render(&buf, l, 1, entries[1])
+	row := buf.String()
+
+	if w := lipgloss.Width(row); w != 40 {
+		t.Errorf("row is %d cells wide, want 40: %q", w, row)
+	}
+	if !strings.Contains(row, "2024-01-15-") || !strings.Contains(row, "…") || !strings.Contains(row, "goes-on") {
+		t.Errorf("row %q should keep both ends of the name", row)
+	}
+	if !strings.HasSuffix(strings.TrimRight(row, " "), "ago") {
+		t.Errorf("row %q should end with the time column", row)
+	}
+	if strings.Contains(row, "#work") || strings.Contains(row, "a note") {
+		t.Errorf("row %q should drop tags and note before the name", row)
+	}
+}
+
+func TestTruncateMiddle(t *testing.T) {
+	tests := []struct {
+		in    string
+		width int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"abcdefghij", 5, "ab…ij"},
+		{"abcdefghij", 6, "abc…ij"},
+		{"abcdefghij", 1, "…"},
+		{"abcdefghij", 0, ""},
+		{"日本語のなまえ", 7, "日本…え"},
+	}
+	for _, tt := range tests {
+		got := truncateMiddle(tt.in, tt.width)
+		if got != tt.want {
+			t.Errorf("truncateMiddle(%q, %d) = %q, want %q", tt.in, tt.width, got, tt.want)
+		}
+		if w := lipgloss.Width(got); w > tt.width {
+			t.Errorf("truncateMiddle(%q, %d) is %d cells wide", tt.in, tt.width, w)
+		}
+	}
+}
+
+func TestRenderWideName(t *testing.T) {
+	entries := []list.Item{
+		item{entry: workspace.Entry{Name: "2024-01-15-日本語-🚀-launch"}, tags: []string{"仕事"}},
+		item{entry: workspace.Entry{Name: "2024-01-15-plain"}},
+	}
+	d := itemDelegate{styles: newDelegateStyles(New("").theme, true)}
+
+	for _, width := range []int{60, 30} {
+		l := list.New(entries, d, width, 10)
+		var wide, plain bytes.Buffer
+		d.Render(&wide, l, 0, entries[0])
+		d.Render(&plain, l, 1, entries[1])
+
+		if w := lipgloss.Width(wide.String()); w != width {
+			t.Errorf("width %d: wide row is %d cells: %q", width, w, wide.String())
+		}
+		// The time column lines up with plain rows
+		if lipgloss.Width(strings.TrimRight(wide.String(), " ")) != lipgloss.Width(strings.TrimRight(plain.String(), " ")) {
+			t.Errorf("width %d: time columns don't line up:\n%q\n%q", width, wide.String(), plain.String())
+		}
+	}
+}
+
+func TestDropLastRune(t *testing.T) {
+	if got := dropLastRune("メモ"); got != "メ" {
+		t.Errorf("got %q", got)
+	}
+	if got := dropLastRune(""); got != "" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestCycleKind(t *testing.T) {
+	m := New(t.TempDir())
+	m.entries = []workspace.Entry{
+		{Name: "made", Kind: workspace.KindCreated},
+		{Name: "repo", Kind: workspace.KindCloned},
+		{Name: "old"},
+	}
+	m.Update(scanDoneMsg{})
+
+	alt := func() { m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("k"), Alt: true}) }
+
+	want := []struct {
+		title string
+		names []string
+	}{
+		{"created", []string{"made"}},
+		{"cloned", []string{"repo"}},
+		{"imported", nil},
+		{"unknown", []string{"old"}},
+		{"", []string{"made", "old", "repo"}},
+	}
+	for _, w := range want {
+		alt()
+		var names []string
+		for _, it := range m.list.Items() {
+			names = append(names, it.(item).entry.Name)
+		}
+		if strings.Join(names, ",") != strings.Join(w.names, ",") {
+			t.Errorf("%q: got %v, want %v", w.title, names, w.names)
+		}
+		if w.title != "" && !strings.Contains(m.list.Title, w.title) {
+			t.Errorf("title %q should name the kind %q", m.list.Title, w.title)
+		}
+	}
+}
+
+func TestBaseKey(t *testing.T) {
+	base := t.TempDir()
+	m := New(base)
+	m.entries = []workspace.Entry{{Name: "redis", Path: base + "/redis"}}
+	m.Update(scanDoneMsg{})
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlB})
+	if m.action == nil || m.action.Type != ActionCDBase || m.action.Path != base || cmd == nil {
+		t.Errorf("ctrl+b should pick the tries dir, got %+v", m.action)
+	}
+}
+
+func TestSelectBrokenLink(t *testing.T) {
+	base := t.TempDir()
+	m := New(base, WithNoColor(true))
+	m.Update(tea.WindowSizeMsg{Width: 100, Height: 20})
+	m.entries = []workspace.Entry{{Name: "dangling", Path: base + "/dangling", Symlink: true, Broken: true}}
+	m.Update(scanDoneMsg{})
+
+	if view := m.View(); !strings.Contains(view, "broken link") {
+		t.Errorf("broken link should be flagged in the list, got:\n%s", view)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.action != nil {
+		t.Errorf("selecting a broken link should not cd, got %+v", m.action)
+	}
+	if view := m.View(); !strings.Contains(view, "delete it with ctrl+d") {
+		t.Errorf("expected a hint to delete the link, got:\n%s", view)
+	}
+}
+
+func TestScanStopped(t *testing.T) {
+	base := t.TempDir()
+	m := New(base, WithNoColor(true), WithCache(true), WithScanTimeout(time.Second))
+	m.Update(tea.WindowSizeMsg{Width: 100, Height: 20})
+	m.scan = make(chan workspace.ScanBatch)
+	m.loading = true
+	m.entries = []workspace.Entry{{Name: "redis", Path: base + "/redis"}}
+	m.Update(scanStoppedMsg{})
+
+	view := m.View()
+	if !strings.Contains(view, "redis") || !strings.Contains(view, "scan stopped after 1s") {
+		t.Errorf("expected the partial list and a notice, got:\n%s", view)
+	}
+	if m.loading {
+		t.Error("loading should end when the scan stops")
+	}
+	if _, err := os.Stat(base + "/" + workspace.CacheFile); !os.IsNotExist(err) {
+		t.Error("a partial scan should not be cached")
+	}
+}
+
+func TestCacheable(t *testing.T) {
+	base := t.TempDir()
+	if !New(base, WithCache(true)).cacheable() {
+		t.Error("a flat scan should use the cache")
+	}
+	for name, opt := range map[string]Option{
+		"nested":        WithScanDepth(2),
+		"include files": WithIncludeFiles(true),
+	} {
+		if New(base, WithCache(true), opt).cacheable() {
+			t.Errorf("%s: the cache doesn't hold these entries, so shouldn't be used", name)
+		}
+	}
+}
+
+func TestDetailPanel(t *testing.T) {
+	base := t.TempDir()
+	os.Mkdir(base+"/redis", 0755)
+	os.WriteFile(base+"/redis/dump.rdb", []byte("data"), 0644)
+	os.Mkdir(base+"/pg", 0755)
+
+	m := New(base, WithNoColor(true))
+	m.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	m.entries = []workspace.Entry{
+		{Name: "redis", Path: base + "/redis", ModTime: time.Now(), Kind: workspace.KindCloned, SourceURL: "git@github.com:redis/redis.git"},
+		{Name: "pg", Path: base + "/pg", ModTime: time.Now().Add(-time.Hour)},
+	}
+	m.notes = map[string]string{"redis": "port 6379"}
+	m.Update(scanDoneMsg{})
+	listHeight := m.list.Height()
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("i")})
+	if !m.showDetail || cmd == nil {
+		t.Fatal("i should open the panel and load the details")
+	}
+	if m.list.Height() != listHeight-detailHeight {
+		t.Errorf("list height = %d, want %d to make room", m.list.Height(), listHeight-detailHeight)
+	}
+	m.Update(cmd())
+
+	view := m.View()
+	for _, want := range []string{base + "/redis", "1 file, 4 B", "cloned from git@github.com:redis/redis.git", "port 6379", "not a git repo"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("panel should show %q, got:\n%s", want, view)
+		}
+	}
+
+	// Moving on loads the next entry's details
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if cmd == nil {
+		t.Fatal("moving should load the new selection's details")
+	}
+	if view := m.View(); !strings.Contains(view, base+"/pg") || !strings.Contains(view, "counting…") {
+		t.Errorf("panel should follow the selection, got:\n%s", view)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("i")})
+	if m.showDetail || m.list.Height() != listHeight {
+		t.Error("i again should close the panel and give the room back")
+	}
+}
+
+func TestReposOnly(t *testing.T) {
+	base := t.TempDir()
+	os.MkdirAll(base+"/repo/.git", 0755)
+	os.Mkdir(base+"/plain", 0755)
+
+	m := New(base)
+	m.entries = []workspace.Entry{{Name: "repo", Path: base + "/repo"}, {Name: "plain", Path: base + "/plain"}}
+	m.Update(scanDoneMsg{})
+	if m.repos != nil {
+		t.Error("repos should not be checked until the filter is used")
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r"), Alt: true})
+	items := m.list.Items()
+	if len(items) != 1 || items[0].(item).entry.Name != "repo" {
+		t.Errorf("alt+r should list only repo, got %v", items)
+	}
+	if !strings.Contains(m.list.Title, "repos") {
+		t.Errorf("title %q should say the filter is on", m.list.Title)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r"), Alt: true})
+	if len(m.list.Items()) != 2 {
+		t.Error("alt+r again should list everything")
+	}
+}
+
+func TestScanError(t *testing.T) {
+	m := New(t.TempDir())
+	m.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	m.Init()
+
+	_, cmd := m.Update(errMsg{&fs.PathError{Op: "open", Path: m.basePath, Err: fs.ErrPermission}})
+	if cmd != nil || m.state != StateScanError {
+		t.Fatalf("a failed scan should show the error, not quit (state %v)", m.state)
+	}
+	view := m.View()
+	for _, want := range []string{"permission denied", "permissions", "r to retry"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("error view should contain %q:\n%s", want, view)
+		}
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	if m.state != StateSelector || !m.loading {
+		t.Errorf("r should retry the scan (state %v, loading %v)", m.state, m.loading)
+	}
+
+	m.Update(errMsg{errors.New("stale NFS file handle")})
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")}); cmd == nil || m.action == nil || m.action.Type != ActionCancel {
+		t.Error("q should quit from the error view")
+	}
+	if m.GetError() != nil {
+		t.Errorf("quitting after seeing the error is a cancel, got %v", m.GetError())
+	}
+}
+
+func TestRefreshError(t *testing.T) {
+	m := New(t.TempDir())
+	m.entries = []workspace.Entry{{Name: "redis"}}
+	m.Update(scanDoneMsg{})
+	m.refresh()
+
+	m.Update(errMsg{errors.New("input/output error")})
+	if m.state != StateSelector || m.refreshing || len(m.list.Items()) != 1 {
+		t.Error("a failed refresh should keep the current list up")
+	}
+}
+
+func TestJumpKeys(t *testing.T) {
+	entries := []workspace.Entry{
+		{Name: "2025-01-19-redis", Path: "/tmp/tries/2025-01-19-redis"},
+		{Name: "2025-01-18-postgres", Path: "/tmp/tries/2025-01-18-postgres"},
+		{Name: "2025-01-10-mysql", Path: "/tmp/tries/2025-01-10-mysql"},
+	}
+	digit := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2")}
+
+	for _, grouped := range []bool{false, true} {
+		m := New("/tmp/tries", WithGrouping(grouped))
+		m.entries = entries
+		m.Update(scanDoneMsg{})
+
+		if _, cmd := m.Update(digit); cmd == nil || m.action == nil || m.action.Path != entries[1].Path {
+			t.Errorf("grouped %v: 2 should open %s, got %+v", grouped, entries[1].Name, m.action)
+		}
+	}
+
+	// Past the end of the list nothing happens
+	m := New("/tmp/tries")
+	m.entries = entries
+	m.Update(scanDoneMsg{})
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("9")})
+	if m.action != nil {
+		t.Errorf("9 with three entries should do nothing, got %+v", m.action)
+	}
+
+	// While filtering, digits are typed
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	m.Update(digit)
+	if m.action != nil || m.list.FilterValue() != "2" {
+		t.Errorf("2 should type into the filter, got %q and %+v", m.list.FilterValue(), m.action)
+	}
+}
+
+func TestShowHidden(t *testing.T) {
+	base := t.TempDir()
+	os.Mkdir(base+"/redis", 0755)
+	os.Mkdir(base+"/.dotfiles", 0755)
+
+	// scan runs the scan m has started through to the end
+	m := New(base)
+	scan := func() {
+		for msg := waitForBatch(m.scanCtx, m.scan)(); ; msg = waitForBatch(m.scanCtx, m.scan)() {
+			m.Update(msg)
+			if _, done := msg.(scanDoneMsg); done {
+				return
+			}
+		}
+	}
+	m.loadEntries()
+	scan()
+	if len(m.list.Items()) != 1 {
+		t.Fatalf("hidden entries should be left out, got %d items", len(m.list.Items()))
+	}
+
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("h"), Alt: true}); !m.showHidden || cmd == nil {
+		t.Fatal("alt+h should rescan with hidden entries")
+	}
+	scan()
+	if len(m.list.Items()) != 2 || !strings.Contains(m.list.Title, "hidden") {
+		t.Errorf("want both entries and the title saying so, got %d items, title %q", len(m.list.Items()), m.list.Title)
+	}
+}
+
+func TestStaleCommitTimes(t *testing.T) {
+	m := New(t.TempDir(), WithGitRecency(true))
+	m.entries = []workspace.Entry{{Name: "current"}}
+	m.scanGen = 2
+
+	m.Update(commitTimesMsg{gen: 1, entries: []workspace.Entry{{Name: "replaced"}}})
+	if len(m.entries) != 1 || m.entries[0].Name != "current" {
+		t.Errorf("commit times of a replaced scan should be dropped, got %+v", m.entries)
+	}
+
+	m.Update(commitTimesMsg{gen: 2, entries: []workspace.Entry{{Name: "current"}, {Name: "sorted"}}})
+	if len(m.entries) != 2 {
+		t.Errorf("commit times of the current scan should apply, got %+v", m.entries)
+	}
+}
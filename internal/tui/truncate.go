@@ -0,0 +1,59 @@
+package tui
+
+import (
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rivo/uniseg"
+)
+
+// ellipsis marks where truncateMiddle cut text out.
+const ellipsis = "…"
+
+// truncateMiddle shortens s to at most width terminal cells by replacing
+// its middle with an ellipsis, keeping both the start (a date prefix) and
+// the end (usually the most specific part of a name) readable. Text is cut
+// between grapheme clusters and measured like lipgloss.Width, so wide
+// characters and emoji count as the cells they take up.
+func truncateMiddle(s string, width int) string {
+	if lipgloss.Width(s) <= width {
+		return s
+	}
+	if width <= 0 {
+		return ""
+	}
+	if width == 1 {
+		return ellipsis
+	}
+
+	var clusters []string
+	var widths []int
+	for g := uniseg.NewGraphemes(s); g.Next(); {
+		clusters = append(clusters, g.Str())
+		widths = append(widths, g.Width())
+	}
+
+	// The tail gets up to half; the head takes whatever the tail left over
+	keep := width - lipgloss.Width(ellipsis)
+
+	tail, tailWidth := "", 0
+	for i := len(clusters) - 1; i >= 0 && tailWidth+widths[i] <= keep/2; i-- {
+		tail = clusters[i] + tail
+		tailWidth += widths[i]
+	}
+
+	head, headWidth := "", 0
+	for i := 0; i < len(clusters) && headWidth+widths[i] <= keep-tailWidth; i++ {
+		head += clusters[i]
+		headWidth += widths[i]
+	}
+
+	return head + ellipsis + tail
+}
+
+// dropLastRune removes the last character typed into s, for backspace in
+// the prompts. Slicing off the last byte would leave half a character.
+func dropLastRune(s string) string {
+	_, size := utf8.DecodeLastRuneInString(s)
+	return s[:len(s)-size]
+}
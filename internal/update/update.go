@@ -0,0 +1,97 @@
+// Package update checks GitHub for newer releases of try.
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Repo is the GitHub repository releases are published to.
+const Repo = "tobi/try"
+
+// APIBase is the GitHub API root. Tests point it at a local server.
+var APIBase = "https://api.github.com"
+
+// Release is the part of a GitHub release that the check needs.
+type Release struct {
+	Tag string `json:"tag_name"`
+	URL string `json:"html_url"`
+}
+
+// Latest fetches the latest published release of repo.
+func Latest(ctx context.Context, client *http.Client, repo string) (Release, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		fmt.Sprintf("%s/repos/%s/releases/latest", APIBase, repo), nil)
+	if err != nil {
+		return Release{}, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return Release{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Release{}, fmt.Errorf("GitHub API returned %s", resp.Status)
+	}
+
+	var r Release
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return Release{}, fmt.Errorf("failed to decode release: %w", err)
+	}
+	if r.Tag == "" {
+		return Release{}, fmt.Errorf("release has no tag")
+	}
+	return r, nil
+}
+
+// Newer reports whether latest is a newer version than current. Versions
+// are compared as vMAJOR.MINOR.PATCH; anything after the patch number,
+// such as git describe's -3-gabc123 suffix, is ignored. ok is false if
+// either version can't be parsed, e.g. for "dev" builds.
+func Newer(latest, current string) (newer, ok bool) {
+	l, lok := parse(latest)
+	c, cok := parse(current)
+	if !lok || !cok {
+		return false, false
+	}
+	for i := range l {
+		if l[i] != c[i] {
+			return l[i] > c[i], true
+		}
+	}
+	return false, true
+}
+
+// parse splits a version like v1.2.3 into its numbers. Missing minor and
+// patch numbers count as zero.
+func parse(v string) ([3]int, bool) {
+	var out [3]int
+
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	if v == "" {
+		return out, false
+	}
+
+	parts := strings.Split(v, ".")
+	if len(parts) > 3 {
+		return out, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return out, false
+		}
+		out[i] = n
+	}
+	return out, true
+}
@@ -0,0 +1,58 @@
+package update
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewer(t *testing.T) {
+	tests := []struct {
+		latest, current string
+		newer, ok       bool
+	}{
+		{"v1.2.0", "v1.1.9", true, true},
+		{"v1.2.0", "v1.2.0", false, true},
+		{"v1.2.0", "v1.10.0", false, true},
+		{"v2", "v1.9.9", true, true},
+		{"v1.2.1", "v1.2.0-3-gabc123", true, true},
+		{"v1.2.0", "v1.2.0-dirty", false, true},
+		{"v1.2.0", "dev", false, false},
+		{"nightly", "v1.0.0", false, false},
+	}
+
+	for _, tt := range tests {
+		newer, ok := Newer(tt.latest, tt.current)
+		if newer != tt.newer || ok != tt.ok {
+			t.Errorf("Newer(%q, %q) = %v, %v; want %v, %v", tt.latest, tt.current, newer, ok, tt.newer, tt.ok)
+		}
+	}
+}
+
+func TestLatest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/tobi/try/releases/latest" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"tag_name": "v1.4.0", "html_url": "https://github.com/tobi/try/releases/tag/v1.4.0"}`))
+	}))
+	defer srv.Close()
+
+	old := APIBase
+	APIBase = srv.URL
+	defer func() { APIBase = old }()
+
+	r, err := Latest(context.Background(), srv.Client(), "tobi/try")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Tag != "v1.4.0" || r.URL != "https://github.com/tobi/try/releases/tag/v1.4.0" {
+		t.Errorf("unexpected release %+v", r)
+	}
+
+	if _, err := Latest(context.Background(), srv.Client(), "someone/else"); err == nil {
+		t.Error("expected an error for a missing repo")
+	}
+}
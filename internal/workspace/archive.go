@@ -0,0 +1,41 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// ArchiveDir is the directory inside the tries folder that holds archived
+// workspaces: out of the list, but kept as they were, unlike the trash.
+const ArchiveDir = ".archive"
+
+// Archive moves the workspace at path into <basePath>/.archive, keeping its
+// name, with a suffix if an archived workspace already has it. Its note,
+// tags and meta go along. Like Trash, it refuses paths outside basePath.
+// Returns the workspace's new location.
+func Archive(basePath, path string) (string, error) {
+	realBase, realTarget, err := resolveInside(basePath, path)
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(realBase, realTarget)
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(realBase, ArchiveDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	name := uniqueName(dir, filepath.Base(realTarget))
+	dest := filepath.Join(dir, name)
+	if err := Move(realTarget, dest); err != nil {
+		return "", err
+	}
+
+	renameSidecars(realBase, rel, filepath.Join(ArchiveDir, name))
+
+	return dest, nil
+}
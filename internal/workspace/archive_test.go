@@ -0,0 +1,61 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestArchive(t *testing.T) {
+	base := t.TempDir()
+	path := filepath.Join(base, "2024-01-02-spike")
+	os.Mkdir(path, 0755)
+	os.WriteFile(filepath.Join(path, "main.go"), []byte("package main"), 0644)
+	SetNote(base, "2024-01-02-spike", "tried websockets")
+	SetTags(base, "2024-01-02-spike", []string{"work"})
+
+	dest, err := Archive(base, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dest != filepath.Join(base, ArchiveDir, "2024-01-02-spike") {
+		t.Errorf("archived to %s", dest)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "main.go")); err != nil {
+		t.Errorf("contents not archived: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("workspace should be moved out of the list")
+	}
+
+	archived := filepath.Join(ArchiveDir, "2024-01-02-spike")
+	if note, _ := GetNote(base, archived); note != "tried websockets" {
+		t.Errorf("note = %q, want it kept under %s", note, archived)
+	}
+	if tags, _ := GetTags(base, archived); len(tags) != 1 || tags[0] != "work" {
+		t.Errorf("tags = %v, want them kept under %s", tags, archived)
+	}
+
+	// Archived workspaces are out of the list
+	entries, err := ScanWithOptions(base, ScanOptions{IncludeHidden: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("scan should skip the archive even with hidden entries, got %+v", entries)
+	}
+
+	// A second one of the same name gets a suffix
+	os.Mkdir(path, 0755)
+	dest2, err := Archive(base, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(dest2) != "2024-01-02-spike-2" {
+		t.Errorf("expected uniquified name, got %s", dest2)
+	}
+
+	if _, err := Archive(base, filepath.Join(t.TempDir(), "outside")); err == nil {
+		t.Error("paths outside the tries folder should be refused")
+	}
+}
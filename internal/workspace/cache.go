@@ -0,0 +1,147 @@
+package workspace
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CacheFile is the name of the scan cache kept inside the tries directory.
+const CacheFile = ".try-cache.json"
+
+// scanCache is the on-disk form of the last scan.
+type scanCache struct {
+	DirModTime time.Time     `json:"dir_mod_time"`
+	ScannedAt  time.Time     `json:"scanned_at"`
+	Entries    []cachedEntry `json:"entries"`
+}
+
+type cachedEntry struct {
+	Name      string    `json:"name"`
+	ModTime   time.Time `json:"mod_time"`
+	Symlink   bool      `json:"symlink,omitempty"`
+	IsFile    bool      `json:"is_file,omitempty"`
+	Broken    bool      `json:"broken,omitempty"`
+	Kind      Kind      `json:"kind,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	SourceURL string    `json:"source_url,omitempty"`
+}
+
+// ScanCached is like Scan but reuses the cached result of a previous scan
+// when the tries directory itself hasn't been modified since.
+func ScanCached(basePath string) ([]Entry, error) {
+	if entries, ok := LoadCache(basePath); ok {
+		return entries, nil
+	}
+
+	entries, err := Scan(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// The cache is best-effort; a failed write just means a rescan next time
+	_ = SaveCache(basePath, entries)
+	return entries, nil
+}
+
+// LoadCache returns the cached entries, sorted by recency, if the cache
+// exists and the tries directory's mtime matches the one it was saved with.
+// Scores are recomputed so recency reflects the current time.
+func LoadCache(basePath string) ([]Entry, bool) {
+	info, err := os.Stat(basePath)
+	if err != nil {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(filepath.Join(basePath, CacheFile))
+	if err != nil {
+		return nil, false
+	}
+
+	var cache scanCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, false
+	}
+
+	// Any create, delete or rename in the tries dir bumps its mtime
+	if !info.ModTime().Equal(cache.DirModTime) {
+		return nil, false
+	}
+
+	now := time.Now()
+	entries := make([]Entry, len(cache.Entries))
+	for i, e := range cache.Entries {
+		entries[i] = newEntry(basePath, e.Name, e.ModTime, now)
+		entries[i].Symlink = e.Symlink
+		entries[i].IsFile = e.IsFile
+		entries[i].Broken = e.Broken
+		entries[i].Kind = e.Kind
+		entries[i].CreatedAt = e.CreatedAt
+		entries[i].SourceURL = e.SourceURL
+	}
+
+	SortEntries(entries)
+	return entries, true
+}
+
+// SaveCache records entries as the latest scan of basePath.
+func SaveCache(basePath string, entries []Entry) error {
+	cachePath := filepath.Join(basePath, CacheFile)
+	_, statErr := os.Stat(cachePath)
+	created := os.IsNotExist(statErr)
+
+	if err := writeCache(basePath, entries); err != nil {
+		return err
+	}
+
+	// Creating the cache file bumps the directory mtime, so record the new
+	// value. Rewriting an existing file in place leaves the mtime alone.
+	if created {
+		return writeCache(basePath, entries)
+	}
+	return nil
+}
+
+func writeCache(basePath string, entries []Entry) error {
+	info, err := os.Stat(basePath)
+	if err != nil {
+		return err
+	}
+
+	cache := scanCache{
+		DirModTime: info.ModTime(),
+		ScannedAt:  time.Now(),
+		Entries:    make([]cachedEntry, len(entries)),
+	}
+	for i, e := range entries {
+		cache.Entries[i] = cachedEntry{
+			Name:      e.Name,
+			ModTime:   e.ModTime,
+			Symlink:   e.Symlink,
+			IsFile:    e.IsFile,
+			Broken:    e.Broken,
+			Kind:      e.Kind,
+			CreatedAt: e.CreatedAt,
+			SourceURL: e.SourceURL,
+		}
+	}
+
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(basePath, CacheFile), data, 0644)
+}
+
+// InvalidateCache removes the scan cache, forcing the next scan to walk
+// the directory. Use it when an entry's own mtime is about to change,
+// which doesn't touch the tries directory's mtime.
+func InvalidateCache(basePath string) error {
+	err := os.Remove(filepath.Join(basePath, CacheFile))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
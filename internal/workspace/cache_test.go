@@ -0,0 +1,83 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestScanCached(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(tmpDir, "project-a"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := ScanCached(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+
+	// Cache file must not show up as an entry
+	if _, err := os.Stat(filepath.Join(tmpDir, CacheFile)); err != nil {
+		t.Fatalf("cache file should exist: %v", err)
+	}
+
+	cached, ok := LoadCache(tmpDir)
+	if !ok {
+		t.Fatal("cache should be valid right after a scan")
+	}
+	if len(cached) != 1 || cached[0].Name != "project-a" {
+		t.Errorf("unexpected cached entries: %+v", cached)
+	}
+}
+
+func TestLoadCacheInvalidatedByDirChange(t *testing.T) {
+	tmpDir := t.TempDir()
+	if _, err := ScanCached(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+
+	// Make sure the directory mtime visibly moves forward
+	if err := os.Mkdir(filepath.Join(tmpDir, "project-b"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(tmpDir, future, future); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := LoadCache(tmpDir); ok {
+		t.Error("cache should be invalid after the directory changed")
+	}
+
+	entries, err := ScanCached(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected rescan to find 1 entry, got %d", len(entries))
+	}
+}
+
+func TestInvalidateCache(t *testing.T) {
+	tmpDir := t.TempDir()
+	if _, err := ScanCached(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := InvalidateCache(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := LoadCache(tmpDir); ok {
+		t.Error("cache should be gone after InvalidateCache")
+	}
+
+	// Invalidating twice is not an error
+	if err := InvalidateCache(tmpDir); err != nil {
+		t.Error(err)
+	}
+}
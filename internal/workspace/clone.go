@@ -1,10 +1,15 @@
 package workspace
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strings"
+	"text/template"
 	"time"
 )
 
@@ -48,39 +53,155 @@ func ParseGitURL(url string) (*ParsedURL, error) {
 	return nil, fmt.Errorf("unable to parse git URL: %s", url)
 }
 
+// gitURLPatterns match whole git URLs: scp-style SSH, URLs with a scheme,
+// and bare host/user/repo paths. Each needs a host and at least two path
+// segments, so names that merely mention a host or end in .git don't match.
+var gitURLPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^git@[^\s:/]+:[^\s/]+/[^\s]+$`),
+	regexp.MustCompile(`^(https?|ssh|git)://[^\s/]+/[^\s/]+/[^\s]+$`),
+	regexp.MustCompile(`^[a-zA-Z0-9-]+(\.[a-zA-Z0-9-]+)+/[^\s/]+/[^\s/]+$`),
+}
+
 // IsGitURL returns true if the string looks like a git URL.
 func IsGitURL(s string) bool {
-	if strings.HasPrefix(s, "git@") {
-		return true
+	for _, re := range gitURLPatterns {
+		if re.MatchString(s) {
+			return true
+		}
 	}
-	if strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "http://") {
-		return true
+	return false
+}
+
+// IsCloneURL reports whether s is a complete git URL that can be cloned:
+// one IsGitURL accepts and ParseGitURL can split into host, user and
+// repo. Use it where a URL competes with other input, such as a query.
+func IsCloneURL(s string) bool {
+	if !IsGitURL(s) {
+		return false
 	}
-	if strings.HasSuffix(s, ".git") {
-		return true
+	_, err := ParseGitURL(s)
+	return err == nil
+}
+
+// DefaultCloneNameTemplate names clones YYYY-MM-DD-user-repo.
+const DefaultCloneNameTemplate = "{{.Date}}-{{.User}}-{{.Repo}}"
+
+// cloneNameTemplate renders clone directory names; see SetCloneNameTemplate.
+var cloneNameTemplate = template.Must(parseCloneNameTemplate(DefaultCloneNameTemplate))
+
+// cloneNameData is what a clone name template can use.
+type cloneNameData struct {
+	Host string // e.g. github.com
+	User string
+	Repo string
+	Date string // today's date prefix, see SetDateFormat
+}
+
+// SetCloneNameTemplate sets the text/template that CloneDirName renders,
+// such as `{{if eq .Host "github.com"}}gh{{else}}{{.Host}}{{end}}-{{.Repo}}`.
+// It can use .Host, .User, .Repo and .Date.
+func SetCloneNameTemplate(text string) error {
+	tmpl, err := parseCloneNameTemplate(text)
+	if err != nil {
+		return err
+	}
+	cloneNameTemplate = tmpl
+	return nil
+}
+
+// ValidateCloneNameTemplate checks that text parses and renders a name.
+func ValidateCloneNameTemplate(text string) error {
+	_, err := parseCloneNameTemplate(text)
+	return err
+}
+
+// parseCloneNameTemplate parses text and tries it on a sample URL, so
+// mistakes such as unknown fields show up in the config rather than on
+// the next clone.
+func parseCloneNameTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("clone_name_template").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid clone name template: %w", err)
 	}
-	if strings.Contains(s, "github.com") || strings.Contains(s, "gitlab.com") {
-		return true
+	sample := cloneNameData{Host: "github.com", User: "user", Repo: "repo", Date: DefaultDateFormat}
+	if _, err := renderCloneName(tmpl, sample); err != nil {
+		return nil, err
 	}
-	return false
+	return tmpl, nil
 }
 
-// CloneDirName generates a directory name for a cloned repo.
-// Format: YYYY-MM-DD-user-repo
+// renderCloneName renders tmpl and makes the result safe as a file name.
+func renderCloneName(tmpl *template.Template, data cloneNameData) (string, error) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("invalid clone name template: %w", err)
+	}
+	name := sanitizeDirName(b.String())
+	if name == "" {
+		return "", fmt.Errorf("clone name template gave an empty name for %s/%s", data.User, data.Repo)
+	}
+	return name, nil
+}
+
+// CloneDirName generates a directory name for a cloned repo from the clone
+// name template, YYYY-MM-DD-user-repo by default.
 func CloneDirName(url string) (string, error) {
+	return cloneDirName(url, false)
+}
+
+// FlatCloneDirName is like CloneDirName but without the date, for clones
+// meant to stay: user-repo by default. The template is rendered with an
+// empty .Date, wherever it uses it, and the separators left around it are
+// dropped.
+func FlatCloneDirName(url string) (string, error) {
+	return cloneDirName(url, true)
+}
+
+// cloneDirName returns FlatCloneDirName if flat is set, else CloneDirName.
+func cloneDirName(url string, flat bool) (string, error) {
 	parsed, err := ParseGitURL(url)
 	if err != nil {
 		return "", err
 	}
 
-	datePrefix := time.Now().Format("2006-01-02")
-	return fmt.Sprintf("%s-%s-%s", datePrefix, parsed.User, parsed.Repo), nil
+	data := cloneNameData{Host: parsed.Host, User: parsed.User, Repo: parsed.Repo, Date: DatePrefix()}
+	if flat {
+		data.Date = ""
+	}
+	return renderCloneName(cloneNameTemplate, data)
 }
 
-// Clone clones a git repository into basePath.
-// Returns the full path to the cloned directory.
+// CloneOptions controls how Clone runs git.
+type CloneOptions struct {
+	// Env is added to git's environment as KEY=value pairs, e.g. to set
+	// GIT_SSH_COMMAND or proxy variables for clones only.
+	Env []string
+
+	// Progress receives git's output while it runs. Nil discards it.
+	Progress io.Writer
+
+	// Flat leaves the date prefix out of the directory name.
+	Flat bool
+
+	// Into clones inside this existing workspace rather than as a new
+	// one, into a directory named after the repo alone. It must be inside
+	// the tries directory.
+	Into string
+}
+
+// Clone clones a git repository into basePath, showing git's progress on
+// stderr. Returns the full path to the cloned directory.
 func Clone(basePath, url string) (string, error) {
-	dirName, err := CloneDirName(url)
+	return CloneWithOptions(basePath, url, CloneOptions{Progress: os.Stderr})
+}
+
+// CloneWithOptions is like Clone but honors opts.
+func CloneWithOptions(basePath, url string, opts CloneOptions) (string, error) {
+	if opts.Into != "" {
+		return cloneInto(basePath, opts.Into, url, opts)
+	}
+
+	dirName, err := cloneDirName(url, opts.Flat)
 	if err != nil {
 		return "", err
 	}
@@ -88,26 +209,126 @@ func Clone(basePath, url string) (string, error) {
 	// Ensure unique name
 	dirName = uniqueName(basePath, dirName)
 	fullPath := basePath + "/" + dirName
-
-	// Run git clone
-	cmd := exec.Command("git", "clone", url, fullPath)
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return "", fmt.Errorf("git clone failed: %s\n%s", err, output)
+	if err := runClone(url, fullPath, opts); err != nil {
+		return "", err
 	}
 
+	// The clone is what matters; missing meta only loses its kind
+	_ = SetMeta(basePath, dirName, Meta{Kind: KindCloned, CreatedAt: time.Now(), SourceURL: url})
+
 	return fullPath, nil
 }
 
+// cloneInto clones url inside the workspace at parent. The clone is part
+// of that workspace, not one of its own, so it gets no meta.
+func cloneInto(basePath, parent, url string, opts CloneOptions) (string, error) {
+	fullPath, err := nestedClonePath(basePath, parent, url)
+	if err != nil {
+		return "", err
+	}
+	if err := runClone(url, fullPath, opts); err != nil {
+		return "", err
+	}
+	return fullPath, nil
+}
+
+// nestedClonePath returns where url is cloned inside the workspace at
+// parent: a directory named after the repo, made unique.
+func nestedClonePath(basePath, parent, url string) (string, error) {
+	parsed, err := ParseGitURL(url)
+	if err != nil {
+		return "", err
+	}
+	name := sanitizeDirName(parsed.Repo)
+	if name == "" {
+		return "", fmt.Errorf("no directory name in git URL: %s", url)
+	}
+
+	_, realParent, err := resolveInside(basePath, parent)
+	if errors.Is(err, ErrBaseDir) {
+		return "", fmt.Errorf("can't clone into the tries directory itself; leave out the workspace to clone as a new one")
+	}
+	if err != nil {
+		return "", err
+	}
+	if info, err := os.Stat(realParent); err != nil {
+		return "", fmt.Errorf("no workspace to clone into: %w", err)
+	} else if !info.IsDir() {
+		return "", fmt.Errorf("can't clone into %s: not a directory", parent)
+	}
+
+	return filepath.Join(realParent, uniqueName(realParent, name)), nil
+}
+
+// runClone runs git clone of url into fullPath.
+func runClone(url, fullPath string, opts CloneOptions) error {
+	args := []string{"clone"}
+	if opts.Progress != nil {
+		// git only reports progress to a terminal unless asked
+		args = append(args, "--progress")
+	}
+	args = append(args, url, fullPath)
+
+	// Stream output as it comes while keeping the end of it for the
+	// error message; a large clone's progress output is not worth holding
+	output := &tailBuffer{max: cloneErrorTail}
+	out := io.Writer(output)
+	if opts.Progress != nil {
+		out = io.MultiWriter(opts.Progress, output)
+	}
+
+	cmd := exec.Command("git", args...)
+	cmd.Env = append(os.Environ(), opts.Env...)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git clone failed: %s\n%s", err, output.String())
+	}
+	return nil
+}
+
+// cloneErrorTail is how much of git's output a failed clone reports.
+const cloneErrorTail = 4096
+
+// tailBuffer is a writer that keeps only the last max bytes written.
+type tailBuffer struct {
+	max int
+	buf []byte
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.buf = append(t.buf, p...)
+	if len(t.buf) > t.max {
+		t.buf = append(t.buf[:0], t.buf[len(t.buf)-t.max:]...)
+	}
+	return len(p), nil
+}
+
+func (t *tailBuffer) String() string {
+	return string(t.buf)
+}
+
 // CloneScript returns the shell commands to clone a repo (for exec mode).
-// This is used when we want the shell to perform the clone.
-func CloneScript(basePath, url string) (string, string, error) {
-	dirName, err := CloneDirName(url)
+// This is used when we want the shell to perform the clone; see PendClone
+// for recording its meta. With flat, the name has no date prefix.
+func CloneScript(basePath, url string, flat bool) (string, string, error) {
+	dirName, err := cloneDirName(url, flat)
 	if err != nil {
 		return "", "", err
 	}
 
 	dirName = uniqueName(basePath, dirName)
 	fullPath := basePath + "/" + dirName
+	return fullPath, url, nil
+}
 
+// CloneIntoScript is CloneScript for a clone inside the existing workspace
+// at parent, into a directory named after the repo alone. parent must be
+// inside basePath.
+func CloneIntoScript(basePath, parent, url string) (string, string, error) {
+	fullPath, err := nestedClonePath(basePath, parent, url)
+	if err != nil {
+		return "", "", err
+	}
 	return fullPath, url, nil
 }
@@ -1,6 +1,10 @@
 package workspace
 
 import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -110,10 +114,17 @@ func TestIsGitURL(t *testing.T) {
 		{"https://github.com/user/repo.git", true},
 		{"http://github.com/user/repo", true},
 		{"git@gitlab.com:user/repo", true},
-		{"something.git", true},
+		{"ssh://git@host.com/team/repo.git", true},
 		{"github.com/user/repo", true},
 		{"gitlab.com/user/repo", true},
 		{"my-project", false},
+		{"my-notes.git", false},
+		{"something.git", false},
+		{"read-github.com-article", false},
+		{"github.com-notes", false},
+		{"git@github.com", false},
+		{"https://github.com", false},
+		{"notes/github.com/x", false},
 		{"test", false},
 		{"/path/to/dir", false},
 		{"", false},
@@ -129,6 +140,30 @@ func TestIsGitURL(t *testing.T) {
 	}
 }
 
+func TestIsCloneURL(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"git@github.com:user/repo.git", true},
+		{"https://github.com/user/repo", true},
+		{"https://gitlab.com/user/project.git", true},
+		{"github.com-notes", false},
+		{"github.com/user/repo", false},
+		{"https://github.com/user", false},
+		{"my-project", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := IsCloneURL(tt.input); got != tt.want {
+				t.Errorf("IsCloneURL(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestCloneDirName(t *testing.T) {
 	tests := []struct {
 		url      string
@@ -155,3 +190,288 @@ func TestCloneDirName(t *testing.T) {
 		})
 	}
 }
+
+func TestCloneWithOptions(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	src := t.TempDir()
+	git := exec.Command("git", "-C", src, "init", "-q")
+	if out, err := git.CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+
+	// The env redirects the URL to the local repo, so it only clones if
+	// opts.Env reaches git
+	base := t.TempDir()
+	var progress bytes.Buffer
+	path, err := CloneWithOptions(base, "https://example.com/user/repo.git", CloneOptions{
+		Env: []string{
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=url." + src + ".insteadOf",
+			"GIT_CONFIG_VALUE_0=https://example.com/user/repo.git",
+		},
+		Progress: &progress,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasSuffix(path, "-user-repo") {
+		t.Errorf("unexpected clone path %s", path)
+	}
+	if _, err := os.Stat(filepath.Join(path, ".git")); err != nil {
+		t.Errorf("expected a repository at %s: %v", path, err)
+	}
+	if !strings.Contains(progress.String(), "Cloning into") {
+		t.Errorf("expected git output to be streamed, got %q", progress.String())
+	}
+
+	// The clone's meta is recorded outside it, so git sees a clean tree
+	meta, _ := GetMeta(base, filepath.Base(path))
+	if meta.Kind != KindCloned || meta.SourceURL != "https://example.com/user/repo.git" {
+		t.Errorf("meta = %+v, want a clone of the URL", meta)
+	}
+	if time.Since(meta.CreatedAt) > time.Minute {
+		t.Errorf("createdAt = %v, want about now", meta.CreatedAt)
+	}
+	status, err := exec.Command("git", "-C", path, "status", "--porcelain").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status) != 0 {
+		t.Errorf("git status should be clean:\n%s", status)
+	}
+}
+
+func TestCloneScriptMeta(t *testing.T) {
+	base := t.TempDir()
+	const url = "git@github.com:user/repo.git"
+
+	path, _, err := CloneScript(base, url, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing may be in the way of the shell's git clone
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("CloneScript should not create %s", path)
+	}
+	if _, err := os.Stat(filepath.Join(base, PendingMetaDir)); !os.IsNotExist(err) {
+		t.Fatal("CloneScript should not pend meta itself")
+	}
+	if err := PendClone(base, path, url); err != nil {
+		t.Fatal(err)
+	}
+
+	// An unfinished clone has no meta yet, and claiming leaves it pending
+	name := filepath.Base(path)
+	os.Mkdir(path, 0755)
+	if err := ClaimPendingMeta(base); err != nil {
+		t.Fatal(err)
+	}
+	if meta, _ := GetMeta(base, name); meta.Kind != KindUnknown {
+		t.Errorf("unfinished clone has meta %+v", meta)
+	}
+
+	// Scans only read, even once the clone is there
+	os.Mkdir(filepath.Join(path, ".git"), 0755)
+	if entries, _ := Scan(base); len(entries) != 1 || entries[0].Kind != KindUnknown {
+		t.Errorf("a scan should not claim pending meta: %+v", entries)
+	}
+	if _, err := os.Stat(filepath.Join(base, MetaFile)); !os.IsNotExist(err) {
+		t.Error("a scan should not write meta")
+	}
+
+	if err := ClaimPendingMeta(base); err != nil {
+		t.Fatal(err)
+	}
+	meta, _ := GetMeta(base, name)
+	if meta.Kind != KindCloned || meta.SourceURL != url || meta.CreatedAt.IsZero() {
+		t.Errorf("meta = %+v, want a clone of %s", meta, url)
+	}
+	if _, err := os.Stat(filepath.Join(base, PendingMetaDir)); !os.IsNotExist(err) {
+		t.Error("pending meta should be removed once claimed")
+	}
+}
+
+func TestClaimPendingMetaDropsFailedClones(t *testing.T) {
+	base := t.TempDir()
+	for _, name := range []string{"failed", "starting"} {
+		if err := PendClone(base, filepath.Join(base, name), "git@github.com:user/"+name+".git"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The failed clone was pended a while ago and never got a .git
+	old := time.Now().Add(-2 * pendingMetaGrace)
+	os.Chtimes(pendingMetaPath(base, "failed"), old, old)
+
+	if err := ClaimPendingMeta(base); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(pendingMetaPath(base, "failed")); !os.IsNotExist(err) {
+		t.Error("a failed clone's pending meta should be dropped")
+	}
+	if _, err := os.Stat(pendingMetaPath(base, "starting")); err != nil {
+		t.Errorf("a clone that may still start should stay pending: %v", err)
+	}
+	if metas, _ := LoadMeta(base); len(metas) != 0 {
+		t.Errorf("no meta should be recorded, got %v", metas)
+	}
+}
+
+func TestCloneWithOptionsError(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	_, err := CloneWithOptions(t.TempDir(), "https://example.invalid/user/repo.git", CloneOptions{
+		Env: []string{"GIT_TERMINAL_PROMPT=0"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "git clone failed") {
+		t.Errorf("expected clone failure with git's output, got %v", err)
+	}
+}
+
+func TestTailBuffer(t *testing.T) {
+	tb := &tailBuffer{max: 5}
+	tb.Write([]byte("abc"))
+	if tb.String() != "abc" {
+		t.Errorf("got %q", tb.String())
+	}
+	tb.Write([]byte("defg"))
+	if tb.String() != "cdefg" {
+		t.Errorf("got %q, want the last 5 bytes", tb.String())
+	}
+}
+
+func TestCloneFlat(t *testing.T) {
+	base := t.TempDir()
+
+	name, err := FlatCloneDirName("git@github.com:user/repo.git")
+	if err != nil || name != "user-repo" {
+		t.Errorf("FlatCloneDirName = %q, %v; want user-repo", name, err)
+	}
+
+	path, _, err := CloneScript(base, "https://github.com/user/repo.git", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(path) != "user-repo" {
+		t.Errorf("flat clone path %s should have no date prefix", path)
+	}
+	if _, _, dated := ParseName(filepath.Base(path)); dated {
+		t.Errorf("flat clone %s should not parse as dated", path)
+	}
+
+	// Flat names are still made unique
+	os.Mkdir(path, 0755)
+	path, _, _ = CloneScript(base, "https://github.com/user/repo.git", true)
+	if filepath.Base(path) != "user-repo-2" {
+		t.Errorf("second flat clone got %s, want user-repo-2", path)
+	}
+
+	// A template without a date has nothing to leave out
+	t.Cleanup(func() { SetCloneNameTemplate(DefaultCloneNameTemplate) })
+	SetCloneNameTemplate("{{.Repo}}")
+	if name, _ := FlatCloneDirName("git@github.com:user/repo.git"); name != "repo" {
+		t.Errorf("got %q, want repo", name)
+	}
+
+	// The date is left out wherever the template puts it
+	SetCloneNameTemplate("{{.User}}-{{.Date}}-{{.Repo}}")
+	if name, _ := FlatCloneDirName("git@github.com:user/repo.git"); name != "user-repo" {
+		t.Errorf("got %q, want user-repo", name)
+	}
+	SetCloneNameTemplate("{{.Repo}}@{{.Date}}")
+	if name, _ := FlatCloneDirName("git@github.com:user/repo.git"); name != "repo" {
+		t.Errorf("got %q, want repo", name)
+	}
+	if name, _ := CloneDirName("git@github.com:user/repo.git"); name != "repo-"+DatePrefix() {
+		t.Errorf("got %q, want the date kept without --flat", name)
+	}
+}
+
+func TestCloneNameTemplate(t *testing.T) {
+	t.Cleanup(func() { SetCloneNameTemplate(DefaultCloneNameTemplate) })
+	date := DatePrefix()
+
+	tmpl := `{{if eq .Host "github.com"}}gh{{else if eq .Host "gitlab.com"}}gl{{else}}{{.Host}}{{end}}-{{.User}}-{{.Repo}}`
+	if err := SetCloneNameTemplate(tmpl); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"git@github.com:tobi/try.git", "gh-tobi-try"},
+		{"https://gitlab.com/group/project", "gl-group-project"},
+		{"https://git.example.org/team/tool.git", "git.example.org-team-tool"},
+	}
+	for _, tt := range tests {
+		if got, err := CloneDirName(tt.url); err != nil || got != tt.want {
+			t.Errorf("CloneDirName(%q) = %q, %v; want %q", tt.url, got, err, tt.want)
+		}
+	}
+
+	// Unsafe characters are replaced
+	if err := SetCloneNameTemplate("{{.Date}} {{.Host}}/{{.User}}:{{.Repo}}"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := CloneDirName("git@github.com:user/repo.git"); got != date+"-github.com-user-repo" {
+		t.Errorf("got %q, want a sanitized name", got)
+	}
+
+	// The default matches the original naming
+	SetCloneNameTemplate(DefaultCloneNameTemplate)
+	if got, _ := CloneDirName("git@github.com:user/repo.git"); got != date+"-user-repo" {
+		t.Errorf("default template gave %q", got)
+	}
+}
+
+func TestValidateCloneNameTemplate(t *testing.T) {
+	for _, text := range []string{"{{.Repo", "{{.Owner}}-{{.Repo}}", "{{/* nothing */}}", "///"} {
+		if err := ValidateCloneNameTemplate(text); err == nil {
+			t.Errorf("ValidateCloneNameTemplate(%q) should fail", text)
+		}
+	}
+	if err := SetCloneNameTemplate("{{.Nope}}"); err == nil {
+		t.Error("an invalid template should be rejected")
+	}
+	if got, _ := CloneDirName("git@github.com:user/repo.git"); !strings.HasSuffix(got, "-user-repo") {
+		t.Errorf("a rejected template should leave the old one in place, got %q", got)
+	}
+}
+
+func TestCloneIntoScript(t *testing.T) {
+	base := t.TempDir()
+	ws := filepath.Join(base, "2025-01-19-redis")
+	os.Mkdir(ws, 0755)
+	os.WriteFile(filepath.Join(base, "notes.txt"), nil, 0644)
+	const url = "https://github.com/redis/hiredis.git"
+
+	path, gotURL, err := CloneIntoScript(base, ws, url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	real, _ := filepath.EvalSymlinks(ws)
+	if path != filepath.Join(real, "hiredis") || gotURL != url {
+		t.Errorf("got %s from %s, want %s/hiredis", path, gotURL, real)
+	}
+	if _, err := os.Stat(filepath.Join(base, PendingMetaDir)); !os.IsNotExist(err) {
+		t.Error("a clone inside a workspace isn't a workspace and gets no meta")
+	}
+
+	// A taken name gets a suffix
+	os.Mkdir(path, 0755)
+	if path, _, _ := CloneIntoScript(base, ws, url); filepath.Base(path) != "hiredis-2" {
+		t.Errorf("got %s, want hiredis-2", path)
+	}
+
+	for _, parent := range []string{base, t.TempDir(), filepath.Join(base, "missing"), filepath.Join(base, "notes.txt")} {
+		if _, _, err := CloneIntoScript(base, parent, url); err == nil {
+			t.Errorf("cloning into %s should fail", parent)
+		}
+	}
+}
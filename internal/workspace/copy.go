@@ -0,0 +1,174 @@
+package workspace
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// SymlinkMode chooses what CopyDirWithOptions does with symlinks.
+type SymlinkMode int
+
+const (
+	// SymlinksKeep copies symlinks as links, pointing where they did.
+	SymlinksKeep SymlinkMode = iota
+	// SymlinksSkip leaves symlinks out.
+	SymlinksSkip
+	// SymlinksFollow copies what symlinks point at. Broken links are kept
+	// as links, and a link back into a directory being copied is an error.
+	SymlinksFollow
+)
+
+// CopyOptions tunes CopyDirWithOptions. The zero value copies symlinks as
+// links.
+type CopyOptions struct {
+	Symlinks SymlinkMode
+}
+
+// modeBits are the parts of a file mode that a copy preserves.
+const modeBits = fs.ModePerm | fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky
+
+// CopyDir copies the directory src to dst, which must not exist: files,
+// subdirectories and symlinks, with their mode bits. Files are streamed,
+// so large ones aren't held in memory. Special files such as sockets are
+// skipped.
+func CopyDir(src, dst string) error {
+	return CopyDirWithOptions(src, dst, CopyOptions{})
+}
+
+// CopyDirWithOptions is CopyDir with control over symlinks.
+func CopyDirWithOptions(src, dst string, opts CopyOptions) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s: %w", src, ErrNotDirectory)
+	}
+	if _, err := os.Lstat(dst); err == nil {
+		return fmt.Errorf("%s: %w", dst, fs.ErrExist)
+	}
+
+	c := copier{opts: opts}
+	if err := c.copyDir(src, dst, info.Mode()); err != nil {
+		return err
+	}
+
+	// Directories get their modes once filled, so read-only ones can be
+	// copied too; deepest first, so a read-only parent doesn't block its
+	// children
+	for i := len(c.dirs) - 1; i >= 0; i-- {
+		if err := os.Chmod(c.dirs[i].path, c.dirs[i].mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copier is the state of one CopyDirWithOptions.
+type copier struct {
+	opts CopyOptions
+
+	// dirs are the directories created, with the modes they get at the end
+	dirs []dirMode
+
+	// parents are the real paths of the directories being copied, when
+	// following symlinks, to catch links that loop back
+	parents []string
+}
+
+type dirMode struct {
+	path string
+	mode os.FileMode
+}
+
+func (c *copier) copyDir(src, dst string, mode os.FileMode) error {
+	if c.opts.Symlinks == SymlinksFollow {
+		real, err := filepath.EvalSymlinks(src)
+		if err != nil {
+			return err
+		}
+		if slices.Contains(c.parents, real) {
+			return fmt.Errorf("%s: symlink loops back to %s", src, real)
+		}
+		c.parents = append(c.parents, real)
+		defer func() { c.parents = c.parents[:len(c.parents)-1] }()
+	}
+
+	if err := os.Mkdir(dst, 0700); err != nil {
+		return err
+	}
+	c.dirs = append(c.dirs, dirMode{dst, mode & modeBits})
+
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := c.copyEntry(filepath.Join(src, e.Name()), filepath.Join(dst, e.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *copier) copyEntry(src, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		switch c.opts.Symlinks {
+		case SymlinksSkip:
+			return nil
+		case SymlinksFollow:
+			if target, err := os.Stat(src); err == nil {
+				info = target
+				break
+			}
+			fallthrough
+		default:
+			link, err := os.Readlink(src)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, dst)
+		}
+	}
+
+	switch {
+	case info.IsDir():
+		return c.copyDir(src, dst, info.Mode())
+	case info.Mode().IsRegular():
+		return copyFile(src, dst, info.Mode()&modeBits)
+	}
+	return nil
+}
+
+// copyFile copies the regular file src to dst with mode, regardless of
+// the umask.
+func copyFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode.Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Chmod(mode); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,181 @@
+package workspace
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// copyFixture builds a tree with nested directories, unusual modes and
+// symlinks to a file, a directory and nothing.
+func copyFixture(t *testing.T) string {
+	t.Helper()
+	src := filepath.Join(t.TempDir(), "src")
+	os.MkdirAll(filepath.Join(src, "a", "b", "c"), 0755)
+	os.WriteFile(filepath.Join(src, "a", "b", "c", "deep.txt"), []byte("deep"), 0644)
+	os.WriteFile(filepath.Join(src, "run.sh"), []byte("#!/bin/sh"), 0644)
+	os.Chmod(filepath.Join(src, "run.sh"), 0750)
+	os.WriteFile(filepath.Join(src, "secret"), []byte("x"), 0644)
+	os.Chmod(filepath.Join(src, "secret"), 0600)
+	os.Symlink("secret", filepath.Join(src, "file-link"))
+	os.Symlink(filepath.Join("a", "b"), filepath.Join(src, "dir-link"))
+	os.Symlink("missing", filepath.Join(src, "broken-link"))
+	os.Mkdir(filepath.Join(src, "readonly"), 0755)
+	os.WriteFile(filepath.Join(src, "readonly", "f"), []byte("ro"), 0644)
+	os.Chmod(filepath.Join(src, "readonly"), 0555)
+	t.Cleanup(func() { os.Chmod(filepath.Join(src, "readonly"), 0755) })
+	return src
+}
+
+// copyDest returns where a test copies to, made writable again afterwards
+// so it can be cleaned up.
+func copyDest(t *testing.T) string {
+	t.Helper()
+	dst := filepath.Join(t.TempDir(), "dst")
+	t.Cleanup(func() { os.Chmod(filepath.Join(dst, "readonly"), 0755) })
+	return dst
+}
+
+func TestCopyDir(t *testing.T) {
+	src := copyFixture(t)
+	dst := copyDest(t)
+	if err := CopyDir(src, dst); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]string{
+		"a/b/c/deep.txt": "deep",
+		"run.sh":         "#!/bin/sh",
+		"readonly/f":     "ro",
+	} {
+		if data, err := os.ReadFile(filepath.Join(dst, name)); err != nil || string(data) != want {
+			t.Errorf("%s = %q, %v; want %q", name, data, err, want)
+		}
+	}
+
+	for name, want := range map[string]fs.FileMode{
+		"run.sh":   0750,
+		"secret":   0600,
+		"readonly": 0555,
+		"a/b/c":    0755,
+	} {
+		info, err := os.Stat(filepath.Join(dst, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := info.Mode().Perm(); got != want {
+			t.Errorf("%s: mode %v, want %v", name, got, want)
+		}
+	}
+
+	// Symlinks stay links, broken ones included
+	for name, want := range map[string]string{
+		"file-link":   "secret",
+		"dir-link":    filepath.Join("a", "b"),
+		"broken-link": "missing",
+	} {
+		if link, err := os.Readlink(filepath.Join(dst, name)); err != nil || link != want {
+			t.Errorf("%s -> %q, %v; want %q", name, link, err, want)
+		}
+	}
+
+	// The source is untouched
+	if data, err := os.ReadFile(filepath.Join(src, "a", "b", "c", "deep.txt")); err != nil || string(data) != "deep" {
+		t.Errorf("source changed: %q, %v", data, err)
+	}
+}
+
+func TestCopyDirSkipSymlinks(t *testing.T) {
+	src := copyFixture(t)
+	dst := copyDest(t)
+	if err := CopyDirWithOptions(src, dst, CopyOptions{Symlinks: SymlinksSkip}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"file-link", "dir-link", "broken-link"} {
+		if _, err := os.Lstat(filepath.Join(dst, name)); !os.IsNotExist(err) {
+			t.Errorf("%s should be skipped, got %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dst, "secret")); err != nil {
+		t.Errorf("regular files should still be copied: %v", err)
+	}
+}
+
+func TestCopyDirFollowSymlinks(t *testing.T) {
+	src := copyFixture(t)
+	dst := copyDest(t)
+	if err := CopyDirWithOptions(src, dst, CopyOptions{Symlinks: SymlinksFollow}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Links become copies of what they point at
+	info, err := os.Lstat(filepath.Join(dst, "file-link"))
+	if err != nil || !info.Mode().IsRegular() {
+		t.Fatalf("file-link should be a regular file: %v, %v", info, err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("file-link: mode %v, want the target's 0600", info.Mode().Perm())
+	}
+	info, err = os.Lstat(filepath.Join(dst, "dir-link"))
+	if err != nil || !info.IsDir() {
+		t.Fatalf("dir-link should be a directory: %v, %v", info, err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dst, "dir-link", "c", "deep.txt")); err != nil || string(data) != "deep" {
+		t.Errorf("dir-link/c/deep.txt = %q, %v", data, err)
+	}
+
+	// Broken links have nothing to follow
+	if link, err := os.Readlink(filepath.Join(dst, "broken-link")); err != nil || link != "missing" {
+		t.Errorf("broken-link -> %q, %v", link, err)
+	}
+}
+
+func TestCopyDirFollowLoop(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "src")
+	os.MkdirAll(filepath.Join(src, "a"), 0755)
+	os.Symlink("..", filepath.Join(src, "a", "up"))
+
+	err := CopyDirWithOptions(src, copyDest(t), CopyOptions{Symlinks: SymlinksFollow})
+	if err == nil {
+		t.Fatal("a link back to a parent should fail when following")
+	}
+
+	// Kept as a link, it is fine
+	if err := CopyDir(src, copyDest(t)); err != nil {
+		t.Errorf("keeping links: %v", err)
+	}
+}
+
+func TestCopyDirLargeFile(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "src")
+	os.Mkdir(src, 0755)
+	data := bytes.Repeat([]byte("0123456789abcdef"), 1<<16) // 1 MiB
+	os.WriteFile(filepath.Join(src, "big"), data, 0644)
+
+	dst := copyDest(t)
+	if err := CopyDir(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(filepath.Join(dst, "big"))
+	if err != nil || !bytes.Equal(got, data) {
+		t.Errorf("big file differs: %d bytes, %v", len(got), err)
+	}
+}
+
+func TestCopyDirRejects(t *testing.T) {
+	src := copyFixture(t)
+
+	if err := CopyDir(src, t.TempDir()); !errors.Is(err, fs.ErrExist) {
+		t.Errorf("existing destination: got %v", err)
+	}
+	if err := CopyDir(filepath.Join(src, "secret"), copyDest(t)); !errors.Is(err, ErrNotDirectory) {
+		t.Errorf("file source: got %v", err)
+	}
+	if err := CopyDir(filepath.Join(src, "missing"), copyDest(t)); !os.IsNotExist(err) {
+		t.Errorf("missing source: got %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package workspace
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultDateFormat is the layout of the date prefix of new workspace
+// names unless SetDateFormat chooses another.
+const DefaultDateFormat = "2006-01-02"
+
+// dateFormat is the layout of date prefixes, and datePattern matches a
+// name starting with one followed by "-".
+var (
+	dateFormat  = DefaultDateFormat
+	datePattern = mustDatePattern(DefaultDateFormat)
+)
+
+// dateTokens are the layout elements a date format may use. They all
+// format to a fixed number of digits, so a prefix is easy to find again.
+var dateTokens = []struct {
+	layout  string
+	pattern string
+}{
+	{"2006", `\d{4}`},
+	{"01", `\d{2}`},
+	{"02", `\d{2}`},
+	{"06", `\d{2}`},
+	{"15", `\d{2}`},
+	{"04", `\d{2}`},
+	{"05", `\d{2}`},
+}
+
+// dateSeparators may appear between layout elements. Anything else could
+// be unsafe in a file name or confused with the name itself.
+const dateSeparators = "-_."
+
+// SetDateFormat sets the layout, in time.Format syntax, used for the date
+// prefix of new workspaces and recognized by ParseName. See
+// ValidateDateFormat for what is allowed.
+func SetDateFormat(layout string) error {
+	re, err := compileDateFormat(layout)
+	if err != nil {
+		return err
+	}
+	dateFormat, datePattern = layout, re
+	return nil
+}
+
+// ValidateDateFormat checks that layout only uses zero-padded numeric
+// elements (2006, 06, 01, 02, 15, 04, 05) joined by "-", "_" or ".", and
+// has a year, month and day. Such layouts always give file-name-safe
+// prefixes of the same length.
+func ValidateDateFormat(layout string) error {
+	_, err := compileDateFormat(layout)
+	return err
+}
+
+// FormatDate formats t as a date prefix, without the trailing "-".
+func FormatDate(t time.Time) string {
+	return t.Format(dateFormat)
+}
+
+// compileDateFormat validates layout and returns a pattern matching a
+// name that starts with a date in it, followed by "-".
+func compileDateFormat(layout string) (*regexp.Regexp, error) {
+	var pattern strings.Builder
+	seen := map[string]bool{}
+	for rest := layout; rest != ""; {
+		if strings.IndexByte(dateSeparators, rest[0]) >= 0 {
+			pattern.WriteString(regexp.QuoteMeta(rest[:1]))
+			rest = rest[1:]
+			continue
+		}
+		matched := false
+		for _, tok := range dateTokens {
+			if strings.HasPrefix(rest, tok.layout) {
+				pattern.WriteString(tok.pattern)
+				seen[tok.layout] = true
+				rest = rest[len(tok.layout):]
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("invalid date format %q: unsupported %q (use 2006, 06, 01, 02, 15, 04, 05 and %q)",
+				layout, rest, dateSeparators)
+		}
+	}
+
+	if !(seen["2006"] || seen["06"]) || !seen["01"] || !seen["02"] {
+		return nil, fmt.Errorf("invalid date format %q: needs a year, month and day", layout)
+	}
+	return regexp.MustCompile("^" + pattern.String() + "-"), nil
+}
+
+func mustDatePattern(layout string) *regexp.Regexp {
+	re, err := compileDateFormat(layout)
+	if err != nil {
+		panic(err)
+	}
+	return re
+}
@@ -0,0 +1,81 @@
+package workspace
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// commitTimeWorkers caps how many git processes run at once.
+const commitTimeWorkers = 8
+
+// CommitTime returns the time of the latest commit in the repository at
+// path. It reports false if path is not a git repository or has no
+// commits.
+func CommitTime(path string) (time.Time, bool) {
+	if _, err := os.Stat(filepath.Join(path, ".git")); err != nil {
+		return time.Time{}, false
+	}
+
+	out, err := exec.Command("git", "-C", path, "log", "-1", "--format=%ct").Output()
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	secs, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(secs, 0), true
+}
+
+// ApplyCommitTimes sets CommitTime on every entry that is a git repository
+// and recomputes its score from it. Repositories are queried in parallel
+// since each one costs a git process.
+func ApplyCommitTimes(entries []Entry) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, commitTimeWorkers)
+	now := time.Now()
+
+	for i := range entries {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(e *Entry) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if t, ok := CommitTime(e.Path); ok {
+				e.CommitTime = t
+				e.BaseScore = recencyScore(e.Name, t, now)
+			}
+		}(&entries[i])
+	}
+
+	wg.Wait()
+}
+
+// Recency returns the time an entry was last worked on: its latest commit
+// when known, otherwise its modification time.
+func (e Entry) Recency() time.Time {
+	if !e.CommitTime.IsZero() {
+		return e.CommitTime
+	}
+	return e.ModTime
+}
+
+// SortByRecency sorts entries by Recency, most recent first, breaking
+// ties by name like SortEntries.
+func SortByRecency(entries []Entry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		ri, rj := entries[i].Recency(), entries[j].Recency()
+		if !ri.Equal(rj) {
+			return ri.After(rj)
+		}
+		return entries[i].Name < entries[j].Name
+	})
+}
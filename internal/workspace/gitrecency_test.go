@@ -0,0 +1,59 @@
+package workspace
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestApplyCommitTimes(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	tmpDir := t.TempDir()
+	repo := filepath.Join(tmpDir, "repo")
+	plain := filepath.Join(tmpDir, "plain")
+	os.Mkdir(repo, 0755)
+	os.Mkdir(plain, 0755)
+
+	commitTime := time.Now().Add(-48 * time.Hour).Truncate(time.Second)
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com",
+			"GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com",
+			"GIT_COMMITTER_DATE="+commitTime.Format(time.RFC3339),
+		)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	git("commit", "-q", "--allow-empty", "-m", "init")
+
+	now := time.Now()
+	entries := []Entry{
+		newEntry(tmpDir, "repo", now, now),
+		newEntry(tmpDir, "plain", now.Add(-time.Hour), now),
+	}
+	ApplyCommitTimes(entries)
+
+	if !entries[0].CommitTime.Equal(commitTime) {
+		t.Errorf("repo commit time = %v, want %v", entries[0].CommitTime, commitTime)
+	}
+	if !entries[1].CommitTime.IsZero() {
+		t.Errorf("plain dir should have no commit time, got %v", entries[1].CommitTime)
+	}
+
+	// The old commit now ranks the repo behind the plain directory
+	SortByRecency(entries)
+	if entries[0].Name != "plain" || entries[1].Name != "repo" {
+		t.Errorf("unexpected order: %s, %s", entries[0].Name, entries[1].Name)
+	}
+	if entries[1].BaseScore >= entries[0].BaseScore {
+		t.Error("repo score should reflect its older commit")
+	}
+}
@@ -0,0 +1,76 @@
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrNotDirectory is returned by Import for sources that aren't
+// directories.
+var ErrNotDirectory = errors.New("not a directory")
+
+// Import brings an existing directory under the tries folder, renaming it
+// YYYY-MM-DD-<name> with the date taken from its mtime. Names that already
+// carry a date prefix are kept. The directory is moved, or copied if copy
+// is true. Returns the new path.
+func Import(basePath, src string, copy bool) (string, error) {
+	absSrc, err := filepath.Abs(src)
+	if err != nil {
+		return "", err
+	}
+
+	info, err := os.Stat(absSrc)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s: %w", src, ErrNotDirectory)
+	}
+
+	// Importing the tries folder, something in it, or one of its parents
+	// would move it into itself
+	absBase, err := filepath.Abs(basePath)
+	if err != nil {
+		return "", err
+	}
+	if within(absSrc, absBase) {
+		return "", fmt.Errorf("%s contains the tries directory", src)
+	}
+	if within(absBase, absSrc) {
+		return "", fmt.Errorf("%s is already inside %s", src, basePath)
+	}
+
+	name := filepath.Base(absSrc)
+	if _, _, dated := ParseName(name); !dated {
+		name = FormatDate(info.ModTime()) + "-" + name
+	}
+	dest := filepath.Join(basePath, uniqueName(basePath, name))
+
+	if copy {
+		if err := CopyDir(absSrc, dest); err != nil {
+			os.RemoveAll(dest)
+			return "", err
+		}
+	} else if err := Move(absSrc, dest); err != nil {
+		return "", err
+	}
+
+	_ = SetMeta(basePath, filepath.Base(dest), Meta{Kind: KindImported, CreatedAt: time.Now()})
+
+	// Keep the original mtime so the workspace sorts where it belongs
+	_ = os.Chtimes(dest, info.ModTime(), info.ModTime())
+	return dest, nil
+}
+
+// within reports whether path is dir or inside it.
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
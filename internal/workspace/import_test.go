@@ -0,0 +1,98 @@
+package workspace
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestImportMove(t *testing.T) {
+	base := t.TempDir()
+	src := filepath.Join(t.TempDir(), "old-project")
+	os.Mkdir(src, 0755)
+	os.WriteFile(filepath.Join(src, "main.go"), []byte("package main"), 0644)
+
+	mtime := time.Date(2023, 6, 1, 12, 0, 0, 0, time.Local)
+	os.Chtimes(src, mtime, mtime)
+
+	dest, err := Import(base, src, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dest != filepath.Join(base, "2023-06-01-old-project") {
+		t.Errorf("unexpected destination %s", dest)
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Error("source should be moved away")
+	}
+	if data, err := os.ReadFile(filepath.Join(dest, "main.go")); err != nil || string(data) != "package main" {
+		t.Errorf("contents not moved: %q, %v", data, err)
+	}
+	if info, _ := os.Stat(dest); !info.ModTime().Equal(mtime) {
+		t.Errorf("mtime = %v, want %v", info.ModTime(), mtime)
+	}
+
+	// A second import of the same name is uniquified
+	os.Mkdir(src, 0755)
+	os.Chtimes(src, mtime, mtime)
+	dest2, err := Import(base, src, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(dest2) != "2023-06-01-old-project-2" {
+		t.Errorf("expected uniquified name, got %s", dest2)
+	}
+}
+
+func TestImportCopy(t *testing.T) {
+	base := t.TempDir()
+	src := filepath.Join(t.TempDir(), "2022-01-02-dated")
+	os.MkdirAll(filepath.Join(src, "sub"), 0755)
+	os.WriteFile(filepath.Join(src, "sub", "file.txt"), []byte("hi"), 0600)
+	os.Symlink("sub/file.txt", filepath.Join(src, "link"))
+
+	dest, err := Import(base, src, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Already dated names are kept
+	if filepath.Base(dest) != "2022-01-02-dated" {
+		t.Errorf("unexpected name %s", filepath.Base(dest))
+	}
+	if _, err := os.Stat(src); err != nil {
+		t.Error("source should be left in place when copying")
+	}
+	info, err := os.Stat(filepath.Join(dest, "sub", "file.txt"))
+	if err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("file not copied with its mode: %v, %v", info, err)
+	}
+	if link, err := os.Readlink(filepath.Join(dest, "link")); err != nil || link != "sub/file.txt" {
+		t.Errorf("symlink not preserved: %q, %v", link, err)
+	}
+}
+
+func TestImportRejects(t *testing.T) {
+	parent := t.TempDir()
+	base := filepath.Join(parent, "tries")
+	os.MkdirAll(filepath.Join(base, "inside"), 0755)
+	file := filepath.Join(parent, "file.txt")
+	os.WriteFile(file, nil, 0644)
+
+	if _, err := Import(base, file, false); !errors.Is(err, ErrNotDirectory) {
+		t.Errorf("importing a file: expected ErrNotDirectory, got %v", err)
+	}
+
+	for _, src := range []string{
+		filepath.Join(parent, "missing"),
+		base,
+		parent,
+		filepath.Join(base, "inside"),
+	} {
+		if _, err := Import(base, src, false); err == nil {
+			t.Errorf("Import(%q) should fail", src)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package workspace
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MetaFile records how each workspace try made came to be, keyed by
+// workspace name (relative to the tries folder). Like NotesFile, it sits
+// in the tries folder, so the workspaces themselves are left untouched.
+const MetaFile = ".try-meta.json"
+
+// PendingMetaDir holds, in the base directory, the meta of clones the shell
+// has yet to run, one file per clone, until ClaimPendingMeta finds the
+// clone there.
+const PendingMetaDir = ".try-pending"
+
+// pendingMetaGrace is how long a clone may go without a .git before its
+// pending meta is taken for that of a clone that failed or never ran.
+const pendingMetaGrace = time.Minute
+
+// Kind says how a workspace came to be.
+type Kind string
+
+// Workspace kinds. Workspaces without meta are KindUnknown.
+const (
+	KindUnknown  Kind = ""
+	KindCreated  Kind = "created"
+	KindCloned   Kind = "cloned"
+	KindImported Kind = "imported"
+)
+
+// Kinds lists the known kinds in display order.
+var Kinds = []Kind{KindCreated, KindCloned, KindImported}
+
+// String returns the kind's name, or "unknown".
+func (k Kind) String() string {
+	if k == KindUnknown {
+		return "unknown"
+	}
+	return string(k)
+}
+
+// Meta is what MetaFile records about a workspace.
+type Meta struct {
+	Kind      Kind      `json:"kind"`
+	CreatedAt time.Time `json:"createdAt"`
+	SourceURL string    `json:"sourceURL,omitempty"` // what a clone was cloned from
+}
+
+// LoadMeta returns the meta of every workspace in basePath that has some.
+// A missing meta file means none do.
+func LoadMeta(basePath string) (map[string]Meta, error) {
+	metas := map[string]Meta{}
+
+	data, err := os.ReadFile(filepath.Join(basePath, MetaFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return metas, nil
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &metas); err != nil {
+		return nil, err
+	}
+	return metas, nil
+}
+
+// GetMeta returns the meta of the workspace called name, or the zero Meta,
+// of KindUnknown, if it has none.
+func GetMeta(basePath, name string) (Meta, error) {
+	metas, err := LoadMeta(basePath)
+	if err != nil {
+		return Meta{}, err
+	}
+	return metas[name], nil
+}
+
+// SetMeta records meta for the workspace called name.
+func SetMeta(basePath, name string, meta Meta) error {
+	return updateMeta(basePath, func(metas map[string]Meta) {
+		metas[name] = meta
+	})
+}
+
+// RenameMeta moves the meta of workspace oldName to newName.
+func RenameMeta(basePath, oldName, newName string) error {
+	return updateMeta(basePath, func(metas map[string]Meta) {
+		if meta, ok := metas[oldName]; ok {
+			delete(metas, oldName)
+			metas[newName] = meta
+		}
+	})
+}
+
+// RemoveMeta drops the meta of the workspace called name, if any.
+func RemoveMeta(basePath, name string) error {
+	return updateMeta(basePath, func(metas map[string]Meta) {
+		delete(metas, name)
+	})
+}
+
+// updateMeta applies fn to the stored meta and writes it back. The file
+// is only touched if fn changed something.
+func updateMeta(basePath string, fn func(map[string]Meta)) error {
+	metas, err := LoadMeta(basePath)
+	if err != nil {
+		return err
+	}
+
+	before, _ := json.Marshal(metas)
+	fn(metas)
+	data, err := json.Marshal(metas)
+	if err != nil {
+		return err
+	}
+	if string(data) == string(before) {
+		return nil
+	}
+
+	path := filepath.Join(basePath, MetaFile)
+	if len(metas) == 0 {
+		err := os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func readMetaFile(path string) (Meta, error) {
+	var meta Meta
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Meta{}, err
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return Meta{}, err
+	}
+	return meta, nil
+}
+
+// pendingMetaPath is where meta for the workspace called name waits until
+// it exists.
+func pendingMetaPath(basePath, name string) string {
+	return filepath.Join(basePath, PendingMetaDir, name+".json")
+}
+
+// PendClone records the meta of a clone of url into path, inside basePath,
+// that the shell is about to run, for ClaimPendingMeta to pick up.
+func PendClone(basePath, path, url string) error {
+	meta := Meta{Kind: KindCloned, CreatedAt: time.Now(), SourceURL: url}
+	return writePendingMeta(basePath, filepath.Base(path), meta)
+}
+
+// writePendingMeta records meta for a workspace the shell will clone into.
+func writePendingMeta(basePath, name string, meta Meta) error {
+	path := pendingMetaPath(basePath, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// ClaimPendingMeta records the pending meta of every clone that has got
+// going, which is when it has a .git, and drops that of clones still
+// without one after pendingMetaGrace: they failed or never ran. Scans only
+// read meta, so this is left to exec, which pends the clones.
+func ClaimPendingMeta(basePath string) error {
+	dir := filepath.Join(basePath, PendingMetaDir)
+	files, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		name, ok := strings.CutSuffix(f.Name(), ".json")
+		if !ok {
+			continue
+		}
+		path := filepath.Join(dir, f.Name())
+
+		if _, err := os.Stat(filepath.Join(basePath, name, ".git")); err == nil {
+			// Unreadable meta is dropped like that of a failed clone
+			if meta, err := readMetaFile(path); err == nil {
+				if err := SetMeta(basePath, name, meta); err != nil {
+					return err
+				}
+			}
+			os.Remove(path)
+			continue
+		}
+		if info, err := f.Info(); err == nil && time.Since(info.ModTime()) > pendingMetaGrace {
+			os.Remove(path)
+		}
+	}
+
+	// Only succeeds once nothing is pending
+	os.Remove(dir)
+	return nil
+}
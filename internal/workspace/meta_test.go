@@ -0,0 +1,84 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMetaRoundTrip(t *testing.T) {
+	base := t.TempDir()
+
+	if meta, err := GetMeta(base, "redis"); err != nil || meta.Kind != KindUnknown {
+		t.Errorf("workspace without meta should be unknown, got %q, %v", meta.Kind, err)
+	}
+
+	for _, kind := range Kinds {
+		if err := SetMeta(base, "redis", Meta{Kind: kind}); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := GetMeta(base, "redis"); got.Kind != kind {
+			t.Errorf("wrote %q, read %q", kind, got.Kind)
+		}
+	}
+
+	created := time.Date(2025, 1, 19, 10, 30, 0, 0, time.UTC)
+	want := Meta{Kind: KindCloned, CreatedAt: created, SourceURL: "git@github.com:user/repo.git"}
+	if err := SetMeta(base, "redis", want); err != nil {
+		t.Fatal(err)
+	}
+	if err := RenameMeta(base, "redis", "valkey"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := GetMeta(base, "valkey"); !got.CreatedAt.Equal(created) || got.SourceURL != want.SourceURL {
+		t.Errorf("wrote %+v, read %+v", want, got)
+	}
+
+	if err := RemoveMeta(base, "valkey"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(base, MetaFile)); !os.IsNotExist(err) {
+		t.Error("meta file should be removed once empty")
+	}
+
+	// A corrupt file is an error, and scans list the kinds as unknown
+	os.WriteFile(filepath.Join(base, MetaFile), []byte("{not json"), 0644)
+	if _, err := GetMeta(base, "valkey"); err == nil {
+		t.Error("expected an error for a corrupt meta file")
+	}
+	os.Mkdir(filepath.Join(base, "valkey"), 0755)
+	if entries, err := Scan(base); err != nil || len(entries) != 1 || entries[0].Kind != KindUnknown {
+		t.Errorf("corrupt meta should scan as unknown, got %+v, %v", entries, err)
+	}
+}
+
+func TestMetaLeavesWorkspaceAlone(t *testing.T) {
+	base := t.TempDir()
+
+	path, err := Create(base, "fresh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(path); len(entries) != 0 {
+		t.Errorf("a new workspace should be empty, so git can clone into it; got %v", entries)
+	}
+
+	// Meta follows the workspace out of the way and back
+	if _, err := Trash(base, path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Restore(base); err != nil {
+		t.Fatal(err)
+	}
+	if meta, _ := GetMeta(base, filepath.Base(path)); meta.Kind != KindCreated {
+		t.Errorf("restored workspace has kind %q", meta.Kind)
+	}
+
+	if err := Delete(base, path); err != nil {
+		t.Fatal(err)
+	}
+	if metas, _ := LoadMeta(base); len(metas) != 0 {
+		t.Errorf("deleting should drop the meta, got %v", metas)
+	}
+}
@@ -0,0 +1,145 @@
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// rename is os.Rename, replaced in tests to take Move's copy path.
+var rename = os.Rename
+
+// Move moves the directory or file src to dst, which must not exist. It
+// renames it where it can; across filesystems, where a rename fails with
+// EXDEV, it copies src, mode bits included, and then removes it. Every
+// move of a workspace goes through Move.
+func Move(src, dst string) error {
+	err := rename(src, dst)
+	if err == nil || !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	switch {
+	case info.IsDir():
+		err = CopyDir(src, dst)
+	case info.Mode()&os.ModeSymlink != 0:
+		var link string
+		if link, err = os.Readlink(src); err == nil {
+			err = os.Symlink(link, dst)
+		}
+	default:
+		err = copyFile(src, dst, info.Mode()&modeBits)
+	}
+	if err != nil {
+		os.RemoveAll(dst)
+		return err
+	}
+	return os.RemoveAll(src)
+}
+
+// MoveToBase moves the workspace at path, inside basePath, to the top of
+// another tries folder, destBase, taking its note, tags and meta along. The name
+// is kept, with a suffix if destBase already has one like it. Returns the
+// new path.
+//
+// If the workspace was moved but its note, tags or meta couldn't be
+// written to destBase, the new path is returned together with the error;
+// they are then still in basePath.
+func MoveToBase(basePath, path, destBase string) (string, error) {
+	realBase, realTarget, err := resolveInside(basePath, path)
+	if err != nil {
+		return "", err
+	}
+
+	absDest, err := filepath.Abs(destBase)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(absDest)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s: %w", destBase, ErrNotDirectory)
+	}
+	realDest, err := filepath.EvalSymlinks(absDest)
+	if err != nil {
+		return "", err
+	}
+	if realDest == realBase {
+		return "", fmt.Errorf("%s is already in %s", filepath.Base(realTarget), destBase)
+	}
+	if within(realTarget, realDest) {
+		return "", fmt.Errorf("can't move %s into itself", filepath.Base(realTarget))
+	}
+
+	src, err := os.Lstat(realTarget)
+	if err != nil {
+		return "", err
+	}
+
+	dest := filepath.Join(realDest, uniqueName(realDest, filepath.Base(realTarget)))
+	if err := Move(realTarget, dest); err != nil {
+		return "", err
+	}
+
+	// A copy across filesystems loses the mtime, which orders the list
+	_ = os.Chtimes(dest, src.ModTime(), src.ModTime())
+
+	rel, err := filepath.Rel(realBase, realTarget)
+	if err != nil {
+		return dest, err
+	}
+	if err := moveSidecars(realBase, rel, realDest, filepath.Base(dest)); err != nil {
+		return dest, fmt.Errorf("failed to move note, tags and meta: %w", err)
+	}
+	return dest, nil
+}
+
+// moveSidecars moves the note, tags and meta of workspace name in basePath
+// to newName in destBase. They are written to destBase before being dropped
+// from basePath, so a failure loses nothing.
+func moveSidecars(basePath, name, destBase, newName string) error {
+	note, err := GetNote(basePath, name)
+	if err != nil {
+		return err
+	}
+	tags, err := GetTags(basePath, name)
+	if err != nil {
+		return err
+	}
+	metas, err := LoadMeta(basePath)
+	if err != nil {
+		return err
+	}
+
+	if note != "" {
+		if err := SetNote(destBase, newName, note); err != nil {
+			return err
+		}
+	}
+	if len(tags) > 0 {
+		if err := SetTags(destBase, newName, tags); err != nil {
+			return err
+		}
+	}
+	if meta, ok := metas[name]; ok {
+		if err := SetMeta(destBase, newName, meta); err != nil {
+			return err
+		}
+	}
+
+	if err := RemoveNote(basePath, name); err != nil {
+		return err
+	}
+	if err := RemoveTags(basePath, name); err != nil {
+		return err
+	}
+	return RemoveMeta(basePath, name)
+}
@@ -0,0 +1,156 @@
+package workspace
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestMoveAcrossFilesystems(t *testing.T) {
+	// Renames fail as they would between two filesystems
+	rename = func(oldpath, newpath string) error {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: syscall.EXDEV}
+	}
+	t.Cleanup(func() { rename = os.Rename })
+
+	src := filepath.Join(t.TempDir(), "project")
+	os.MkdirAll(filepath.Join(src, "bin"), 0755)
+	os.WriteFile(filepath.Join(src, "bin", "run"), []byte("#!/bin/sh"), 0644)
+	os.Chmod(filepath.Join(src, "bin", "run"), 0750)
+	os.WriteFile(filepath.Join(src, "secret"), []byte("x"), 0644)
+	os.Chmod(filepath.Join(src, "secret"), 0600)
+	os.Symlink("secret", filepath.Join(src, "link"))
+	os.Chmod(filepath.Join(src, "bin"), 0555)
+
+	dst := filepath.Join(t.TempDir(), "project")
+	t.Cleanup(func() { os.Chmod(filepath.Join(dst, "bin"), 0755) })
+	if err := Move(src, dst); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Lstat(src); !os.IsNotExist(err) {
+		t.Error("source should be removed after copying")
+	}
+	for name, want := range map[string]os.FileMode{
+		"bin":     0555,
+		"bin/run": 0750,
+		"secret":  0600,
+	} {
+		info, err := os.Stat(filepath.Join(dst, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := info.Mode().Perm(); got != want {
+			t.Errorf("%s: mode %v, want %v", name, got, os.FileMode(want))
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(dst, "bin", "run")); string(data) != "#!/bin/sh" {
+		t.Errorf("contents not copied: %q", data)
+	}
+	if link, err := os.Readlink(filepath.Join(dst, "link")); err != nil || link != "secret" {
+		t.Errorf("symlink = %q, %v", link, err)
+	}
+
+	// Other errors are returned as they are, without copying
+	rename = func(oldpath, newpath string) error {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: syscall.EACCES}
+	}
+	other := filepath.Join(t.TempDir(), "other")
+	os.Mkdir(other, 0755)
+	if err := Move(other, filepath.Join(t.TempDir(), "other")); !errors.Is(err, syscall.EACCES) {
+		t.Errorf("got %v, want EACCES", err)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Error("a failed move should leave the source alone")
+	}
+}
+
+func TestMoveToBase(t *testing.T) {
+	base := t.TempDir()
+	dest := t.TempDir()
+
+	src := filepath.Join(base, "2025-01-19-redis")
+	os.Mkdir(src, 0755)
+	os.WriteFile(filepath.Join(src, "main.go"), []byte("package main"), 0644)
+	mtime := time.Date(2025, 1, 19, 12, 0, 0, 0, time.Local)
+	os.Chtimes(src, mtime, mtime)
+	SetNote(base, "2025-01-19-redis", "port 8080")
+	SetTags(base, "2025-01-19-redis", []string{"work"})
+	SetMeta(base, "2025-01-19-redis", Meta{Kind: KindCreated})
+	SetNote(base, "other", "stays")
+
+	moved, err := MoveToBase(base, src, dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved != filepath.Join(dest, "2025-01-19-redis") {
+		t.Errorf("moved to %s", moved)
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Error("source should be moved away")
+	}
+	if data, err := os.ReadFile(filepath.Join(moved, "main.go")); err != nil || string(data) != "package main" {
+		t.Errorf("contents not moved: %q, %v", data, err)
+	}
+	if info, _ := os.Stat(moved); !info.ModTime().Equal(mtime) {
+		t.Errorf("mtime = %v, want %v", info.ModTime(), mtime)
+	}
+
+	// The note, tags and meta follow it; the other note stays put
+	if note, _ := GetNote(dest, "2025-01-19-redis"); note != "port 8080" {
+		t.Errorf("note in destination = %q", note)
+	}
+	if tags, _ := GetTags(dest, "2025-01-19-redis"); !reflect.DeepEqual(tags, []string{"work"}) {
+		t.Errorf("tags in destination = %v", tags)
+	}
+	if meta, _ := GetMeta(dest, "2025-01-19-redis"); meta.Kind != KindCreated {
+		t.Errorf("kind in destination = %q", meta.Kind)
+	}
+	if notes, _ := LoadNotes(base); !reflect.DeepEqual(notes, map[string]string{"other": "stays"}) {
+		t.Errorf("notes left in source = %v", notes)
+	}
+	if tags, _ := LoadTags(base); len(tags) != 0 {
+		t.Errorf("tags left in source = %v", tags)
+	}
+
+	// A name taken in the destination gets a suffix
+	os.Mkdir(src, 0755)
+	moved2, err := MoveToBase(base, src, dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(moved2) != "2025-01-19-redis-2" {
+		t.Errorf("expected uniquified name, got %s", moved2)
+	}
+}
+
+func TestMoveToBaseRejects(t *testing.T) {
+	base := t.TempDir()
+	src := filepath.Join(base, "redis")
+	os.MkdirAll(filepath.Join(src, "sub"), 0755)
+	file := filepath.Join(t.TempDir(), "file")
+	os.WriteFile(file, nil, 0644)
+
+	if _, err := MoveToBase(base, src, base); err == nil {
+		t.Error("moving into the same tries directory should fail")
+	}
+	if _, err := MoveToBase(base, src, filepath.Join(src, "sub")); err == nil {
+		t.Error("moving into itself should fail")
+	}
+	if _, err := MoveToBase(base, src, filepath.Join(t.TempDir(), "missing")); !os.IsNotExist(err) {
+		t.Errorf("missing destination: got %v", err)
+	}
+	if _, err := MoveToBase(base, src, file); !errors.Is(err, ErrNotDirectory) {
+		t.Errorf("file destination: got %v", err)
+	}
+	if _, err := MoveToBase(base, base, t.TempDir()); !errors.Is(err, ErrBaseDir) {
+		t.Errorf("moving the tries directory: got %v", err)
+	}
+	if _, err := os.Stat(src); err != nil {
+		t.Error("rejected moves should leave the workspace in place")
+	}
+}
@@ -0,0 +1,100 @@
+package workspace
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// NotesFile holds the one-line notes attached to workspaces, keyed by
+// workspace name (relative to the tries folder).
+const NotesFile = ".try-notes.json"
+
+// LoadNotes returns all notes in basePath. A missing notes file means no
+// notes.
+func LoadNotes(basePath string) (map[string]string, error) {
+	notes := map[string]string{}
+
+	data, err := os.ReadFile(filepath.Join(basePath, NotesFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return notes, nil
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &notes); err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+// GetNote returns the note for the workspace called name, or "" if it has
+// none.
+func GetNote(basePath, name string) (string, error) {
+	notes, err := LoadNotes(basePath)
+	if err != nil {
+		return "", err
+	}
+	return notes[name], nil
+}
+
+// SetNote attaches note to the workspace called name. Notes are kept to a
+// single line; an empty note removes it.
+func SetNote(basePath, name, note string) error {
+	note = strings.TrimSpace(strings.ReplaceAll(note, "\n", " "))
+	return updateNotes(basePath, func(notes map[string]string) {
+		if note == "" {
+			delete(notes, name)
+		} else {
+			notes[name] = note
+		}
+	})
+}
+
+// RenameNote moves the note of workspace oldName to newName.
+func RenameNote(basePath, oldName, newName string) error {
+	return updateNotes(basePath, func(notes map[string]string) {
+		if note, ok := notes[oldName]; ok {
+			delete(notes, oldName)
+			notes[newName] = note
+		}
+	})
+}
+
+// RemoveNote drops the note of the workspace called name, if any.
+func RemoveNote(basePath, name string) error {
+	return updateNotes(basePath, func(notes map[string]string) {
+		delete(notes, name)
+	})
+}
+
+// updateNotes applies fn to the stored notes and writes them back. The
+// file is only touched if fn changed something.
+func updateNotes(basePath string, fn func(map[string]string)) error {
+	notes, err := LoadNotes(basePath)
+	if err != nil {
+		return err
+	}
+
+	before, _ := json.Marshal(notes)
+	fn(notes)
+	data, err := json.Marshal(notes)
+	if err != nil {
+		return err
+	}
+	if string(data) == string(before) {
+		return nil
+	}
+
+	path := filepath.Join(basePath, NotesFile)
+	if len(notes) == 0 {
+		err := os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return os.WriteFile(path, data, 0644)
+}
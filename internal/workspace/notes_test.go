@@ -0,0 +1,79 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetGetNote(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	if note, err := GetNote(tmpDir, "2025-01-19-redis"); err != nil || note != "" {
+		t.Fatalf("expected no note, got %q, %v", note, err)
+	}
+
+	if err := SetNote(tmpDir, "2025-01-19-redis", "  port 8080\ndemo "); err != nil {
+		t.Fatal(err)
+	}
+	note, err := GetNote(tmpDir, "2025-01-19-redis")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if note != "port 8080 demo" {
+		t.Errorf("got %q, want single-line note", note)
+	}
+
+	// Clearing the last note removes the file
+	if err := SetNote(tmpDir, "2025-01-19-redis", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, NotesFile)); !os.IsNotExist(err) {
+		t.Error("notes file should be removed when empty")
+	}
+}
+
+func TestRenameNote(t *testing.T) {
+	tmpDir := t.TempDir()
+	SetNote(tmpDir, "old", "broken, investigate")
+	SetNote(tmpDir, "other", "keep me")
+
+	if err := RenameNote(tmpDir, "old", "new"); err != nil {
+		t.Fatal(err)
+	}
+
+	notes, err := LoadNotes(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := notes["old"]; ok {
+		t.Error("old key should be gone")
+	}
+	if notes["new"] != "broken, investigate" || notes["other"] != "keep me" {
+		t.Errorf("unexpected notes after rename: %v", notes)
+	}
+}
+
+func TestDeleteRemovesNote(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"gone", "trashed", "kept"} {
+		os.Mkdir(filepath.Join(tmpDir, name), 0755)
+		SetNote(tmpDir, name, "note for "+name)
+	}
+
+	if err := Delete(tmpDir, filepath.Join(tmpDir, "gone")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Trash(tmpDir, filepath.Join(tmpDir, "trashed")); err != nil {
+		t.Fatal(err)
+	}
+
+	// The trashed one's note waits in the trash for a restore
+	notes, _ := LoadNotes(tmpDir)
+	if _, ok := notes["gone"]; ok || notes["kept"] != "note for kept" {
+		t.Errorf("expected gone's note removed and kept's to remain, got %v", notes)
+	}
+	if _, ok := notes["trashed"]; ok {
+		t.Errorf("trashed note should have moved with the workspace, got %v", notes)
+	}
+}
@@ -0,0 +1,73 @@
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotGitRepo is returned by GitRemote for workspaces that aren't git
+// repositories.
+var ErrNotGitRepo = errors.New("not a git repo")
+
+// IsGitRepo reports whether path is the top of a git repository (or a
+// worktree, whose .git is a file). It only stats, so it is cheap enough to
+// call for every workspace.
+func IsGitRepo(path string) bool {
+	_, err := os.Stat(filepath.Join(path, ".git"))
+	return err == nil
+}
+
+// GitRemote returns the URL of the origin remote of the repository at
+// path.
+func GitRemote(path string) (string, error) {
+	if !IsGitRepo(path) {
+		return "", ErrNotGitRepo
+	}
+
+	out, err := exec.Command("git", "-C", path, "remote", "get-url", "origin").Output()
+	if err != nil {
+		return "", fmt.Errorf("no origin remote")
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// GitState summarizes a repository's working tree.
+type GitState struct {
+	Branch  string // checked-out branch, or "HEAD" when detached
+	Changed int    // modified, staged and untracked paths
+}
+
+// GitStatus returns the branch and number of changed paths of the
+// repository at path.
+func GitStatus(path string) (GitState, error) {
+	if !IsGitRepo(path) {
+		return GitState{}, ErrNotGitRepo
+	}
+
+	out, err := exec.Command("git", "-C", path, "status", "--porcelain", "--branch").Output()
+	if err != nil {
+		return GitState{}, fmt.Errorf("git status failed: %w", err)
+	}
+	return parseGitStatus(string(out)), nil
+}
+
+// parseGitStatus reads the output of git status --porcelain --branch,
+// whose first line is e.g. "## main...origin/main [ahead 1]".
+func parseGitStatus(out string) GitState {
+	var s GitState
+	for _, line := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+		if branch, ok := strings.CutPrefix(line, "## "); ok {
+			branch = strings.TrimPrefix(branch, "No commits yet on ")
+			branch, _, _ = strings.Cut(branch, "...")
+			branch, _, _ = strings.Cut(branch, " ")
+			s.Branch = branch
+		} else if line != "" {
+			s.Changed++
+		}
+	}
+	return s
+}
@@ -0,0 +1,67 @@
+package workspace
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestGitRemote(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	plain := t.TempDir()
+	if _, err := GitRemote(plain); !errors.Is(err, ErrNotGitRepo) {
+		t.Errorf("plain directory: got %v, want ErrNotGitRepo", err)
+	}
+
+	repo := t.TempDir()
+	if out, err := exec.Command("git", "-C", repo, "init", "-q").CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+	if _, err := GitRemote(repo); err == nil {
+		t.Error("repo without origin should fail")
+	}
+
+	const url = "git@github.com:user/repo.git"
+	if out, err := exec.Command("git", "-C", repo, "remote", "add", "origin", url).CombinedOutput(); err != nil {
+		t.Fatalf("git remote add: %v\n%s", err, out)
+	}
+	if got, err := GitRemote(repo); err != nil || got != url {
+		t.Errorf("GitRemote = %q, %v; want %q", got, err, url)
+	}
+}
+
+func TestParseGitStatus(t *testing.T) {
+	tests := []struct {
+		out  string
+		want GitState
+	}{
+		{"## main...origin/main\n", GitState{Branch: "main"}},
+		{"## main...origin/main [ahead 1]\n M go.mod\n?? notes.txt\n", GitState{Branch: "main", Changed: 2}},
+		{"## No commits yet on master\n?? a\n", GitState{Branch: "master", Changed: 1}},
+		{"## HEAD (no branch)\n", GitState{Branch: "HEAD"}},
+	}
+	for _, tt := range tests {
+		if got := parseGitStatus(tt.out); got != tt.want {
+			t.Errorf("parseGitStatus(%q) = %+v, want %+v", tt.out, got, tt.want)
+		}
+	}
+}
+
+func TestIsGitRepo(t *testing.T) {
+	repo := t.TempDir()
+	os.Mkdir(filepath.Join(repo, ".git"), 0755)
+	worktree := t.TempDir()
+	os.WriteFile(filepath.Join(worktree, ".git"), []byte("gitdir: /elsewhere\n"), 0644)
+
+	if !IsGitRepo(repo) || !IsGitRepo(worktree) {
+		t.Error("directories with a .git dir or file are repos")
+	}
+	if IsGitRepo(t.TempDir()) {
+		t.Error("a plain directory is not a repo")
+	}
+}
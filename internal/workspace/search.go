@@ -0,0 +1,73 @@
+package workspace
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+// FileMatch is a workspace containing a file whose name matched a search.
+type FileMatch struct {
+	Entry Entry
+	File  string // name of the first matching file
+}
+
+// SearchOptions bounds a SearchFiles call.
+type SearchOptions struct {
+	// MaxFiles is how many file names to look at in total. Zero means no
+	// limit.
+	MaxFiles int
+
+	// Timeout stops the search once it has run this long. Zero means no
+	// limit.
+	Timeout time.Duration
+}
+
+// SearchFiles looks one level into each entry for a file or directory
+// whose name contains query, ignoring case, and returns the matching
+// entries in the order given. Hidden names are skipped. When a bound in
+// opts is hit the matches found so far are returned.
+func SearchFiles(entries []Entry, query string, opts SearchOptions) []FileMatch {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil
+	}
+
+	var deadline time.Time
+	if opts.Timeout > 0 {
+		deadline = time.Now().Add(opts.Timeout)
+	}
+
+	var matches []FileMatch
+	scanned := 0
+	for _, e := range entries {
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			break
+		}
+		if opts.MaxFiles > 0 && scanned >= opts.MaxFiles {
+			break
+		}
+
+		files, err := os.ReadDir(e.Path)
+		if err != nil {
+			continue
+		}
+
+		for _, f := range files {
+			if opts.MaxFiles > 0 && scanned >= opts.MaxFiles {
+				break
+			}
+			scanned++
+
+			if strings.HasPrefix(f.Name(), ".") {
+				continue
+			}
+			if strings.Contains(strings.ToLower(f.Name()), query) {
+				matches = append(matches, FileMatch{Entry: e, File: f.Name()})
+				break
+			}
+		}
+	}
+
+	return matches
+}
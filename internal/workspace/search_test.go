@@ -0,0 +1,58 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSearchFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := map[string][]string{
+		"2025-01-19-redis":  {"docker-compose.yml", "notes.md"},
+		"2025-01-18-python": {"main.py", ".Compose-hidden"},
+		"scratch":           {"Compose.txt"},
+	}
+	var entries []Entry
+	for _, name := range []string{"2025-01-19-redis", "2025-01-18-python", "scratch"} {
+		dir := filepath.Join(tmpDir, name)
+		os.MkdirAll(dir, 0755)
+		for _, f := range files[name] {
+			os.WriteFile(filepath.Join(dir, f), nil, 0644)
+		}
+		entries = append(entries, Entry{Name: name, Path: dir})
+	}
+
+	matches := SearchFiles(entries, "compose", SearchOptions{})
+	if len(matches) != 2 {
+		t.Fatalf("expected 2 matches, got %+v", matches)
+	}
+	if matches[0].Entry.Name != "2025-01-19-redis" || matches[0].File != "docker-compose.yml" {
+		t.Errorf("first match = %+v", matches[0])
+	}
+	if matches[1].Entry.Name != "scratch" || matches[1].File != "Compose.txt" {
+		t.Errorf("second match = %+v", matches[1])
+	}
+
+	if got := SearchFiles(entries, "  ", SearchOptions{}); got != nil {
+		t.Errorf("empty query should match nothing, got %+v", got)
+	}
+}
+
+func TestSearchFilesMaxFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	var entries []Entry
+	for _, name := range []string{"a", "b"} {
+		dir := filepath.Join(tmpDir, name)
+		os.MkdirAll(dir, 0755)
+		os.WriteFile(filepath.Join(dir, "target.txt"), nil, 0644)
+		entries = append(entries, Entry{Name: name, Path: dir})
+	}
+
+	matches := SearchFiles(entries, "target", SearchOptions{MaxFiles: 1})
+	if len(matches) != 1 || matches[0].Entry.Name != "a" {
+		t.Errorf("expected search to stop after one file, got %+v", matches)
+	}
+}
@@ -0,0 +1,184 @@
+package workspace
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// TagsFile holds the tags attached to workspaces, keyed by workspace name
+// (relative to the tries folder).
+const TagsFile = ".try-tags.json"
+
+// NormalizeTag returns tag without a leading # and surrounding space, in
+// lower case, so "#Work" and "work" are the same tag. Tags can't contain
+// spaces or commas, which separate them when typed; those are replaced
+// with hyphens.
+func NormalizeTag(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	tag = strings.TrimLeft(tag, "#")
+	return strings.Join(strings.FieldsFunc(tag, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	}), "-")
+}
+
+// ParseTags splits text like "#work, spike" into normalized tags.
+func ParseTags(text string) []string {
+	var tags []string
+	for _, f := range strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	}) {
+		if tag := NormalizeTag(f); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// LoadTags returns all tags in basePath. A missing tags file means no
+// tags.
+func LoadTags(basePath string) (map[string][]string, error) {
+	tags := map[string][]string{}
+
+	data, err := os.ReadFile(filepath.Join(basePath, TagsFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return tags, nil
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// GetTags returns the tags of the workspace called name, sorted.
+func GetTags(basePath, name string) ([]string, error) {
+	tags, err := LoadTags(basePath)
+	if err != nil {
+		return nil, err
+	}
+	return tags[name], nil
+}
+
+// HasTag reports whether tags contains tag, compared after normalizing.
+func HasTag(tags []string, tag string) bool {
+	tag = NormalizeTag(tag)
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// AddTag tags the workspace called name. Adding a tag it already has does
+// nothing.
+func AddTag(basePath, name, tag string) error {
+	tag = NormalizeTag(tag)
+	if tag == "" {
+		return nil
+	}
+	return updateTags(basePath, func(tags map[string][]string) {
+		tags[name] = sortedTags(append(tags[name], tag))
+	})
+}
+
+// RemoveTag removes one tag from the workspace called name.
+func RemoveTag(basePath, name, tag string) error {
+	tag = NormalizeTag(tag)
+	return updateTags(basePath, func(tags map[string][]string) {
+		var kept []string
+		for _, t := range tags[name] {
+			if t != tag {
+				kept = append(kept, t)
+			}
+		}
+		setTags(tags, name, kept)
+	})
+}
+
+// SetTags replaces the tags of the workspace called name. No tags removes
+// its entry.
+func SetTags(basePath, name string, list []string) error {
+	var normalized []string
+	for _, t := range list {
+		if t = NormalizeTag(t); t != "" {
+			normalized = append(normalized, t)
+		}
+	}
+	return updateTags(basePath, func(tags map[string][]string) {
+		setTags(tags, name, sortedTags(normalized))
+	})
+}
+
+// RenameTags moves the tags of workspace oldName to newName.
+func RenameTags(basePath, oldName, newName string) error {
+	return updateTags(basePath, func(tags map[string][]string) {
+		if list, ok := tags[oldName]; ok {
+			delete(tags, oldName)
+			tags[newName] = list
+		}
+	})
+}
+
+// RemoveTags drops all tags of the workspace called name.
+func RemoveTags(basePath, name string) error {
+	return updateTags(basePath, func(tags map[string][]string) {
+		delete(tags, name)
+	})
+}
+
+// setTags stores list under name, or deletes name if list is empty.
+func setTags(tags map[string][]string, name string, list []string) {
+	if len(list) == 0 {
+		delete(tags, name)
+		return
+	}
+	tags[name] = list
+}
+
+// sortedTags sorts tags and drops duplicates.
+func sortedTags(tags []string) []string {
+	sort.Strings(tags)
+	out := tags[:0]
+	for i, t := range tags {
+		if i == 0 || t != tags[i-1] {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// updateTags applies fn to the stored tags and writes them back. The file
+// is only touched if fn changed something.
+func updateTags(basePath string, fn func(map[string][]string)) error {
+	tags, err := LoadTags(basePath)
+	if err != nil {
+		return err
+	}
+
+	before, _ := json.Marshal(tags)
+	fn(tags)
+	data, err := json.Marshal(tags)
+	if err != nil {
+		return err
+	}
+	if string(data) == string(before) {
+		return nil
+	}
+
+	path := filepath.Join(basePath, TagsFile)
+	if len(tags) == 0 {
+		err := os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return os.WriteFile(path, data, 0644)
+}
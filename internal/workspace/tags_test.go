@@ -0,0 +1,131 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeTag(t *testing.T) {
+	tests := map[string]string{
+		"work":       "work",
+		"#Work":      "work",
+		"  #spike  ": "spike",
+		"two words":  "two-words",
+		"#":          "",
+	}
+	for in, want := range tests {
+		if got := NormalizeTag(in); got != want {
+			t.Errorf("NormalizeTag(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	if got := ParseTags("#work, spike  #Work"); !reflect.DeepEqual(got, []string{"work", "spike", "work"}) {
+		t.Errorf("ParseTags = %v", got)
+	}
+}
+
+func TestAddRemoveTag(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	if err := AddTag(tmpDir, "redis", "#spike"); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddTag(tmpDir, "redis", "Work"); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddTag(tmpDir, "redis", "work"); err != nil {
+		t.Fatal(err)
+	}
+
+	tags, err := GetTags(tmpDir, "redis")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tags, []string{"spike", "work"}) {
+		t.Errorf("got %v, want sorted tags without duplicates", tags)
+	}
+	if !HasTag(tags, "#WORK") || HasTag(tags, "home") {
+		t.Errorf("HasTag gave the wrong answer for %v", tags)
+	}
+
+	if err := RemoveTag(tmpDir, "redis", "#work"); err != nil {
+		t.Fatal(err)
+	}
+	if tags, _ := GetTags(tmpDir, "redis"); !reflect.DeepEqual(tags, []string{"spike"}) {
+		t.Errorf("got %v after removing work", tags)
+	}
+
+	// Removing the last tag removes the file
+	if err := RemoveTag(tmpDir, "redis", "spike"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, TagsFile)); !os.IsNotExist(err) {
+		t.Error("tags file should be removed when empty")
+	}
+}
+
+func TestSetTags(t *testing.T) {
+	tmpDir := t.TempDir()
+	AddTag(tmpDir, "redis", "old")
+
+	if err := SetTags(tmpDir, "redis", []string{"#b", "a", "", "b"}); err != nil {
+		t.Fatal(err)
+	}
+	if tags, _ := GetTags(tmpDir, "redis"); !reflect.DeepEqual(tags, []string{"a", "b"}) {
+		t.Errorf("got %v", tags)
+	}
+
+	if err := SetTags(tmpDir, "redis", nil); err != nil {
+		t.Fatal(err)
+	}
+	if tags, _ := LoadTags(tmpDir); len(tags) != 0 {
+		t.Errorf("expected no tags, got %v", tags)
+	}
+}
+
+func TestRenameTags(t *testing.T) {
+	tmpDir := t.TempDir()
+	AddTag(tmpDir, "old", "work")
+	AddTag(tmpDir, "other", "home")
+
+	if err := RenameTags(tmpDir, "old", "new"); err != nil {
+		t.Fatal(err)
+	}
+
+	tags, err := LoadTags(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := tags["old"]; ok {
+		t.Error("old key should be gone")
+	}
+	if !reflect.DeepEqual(tags["new"], []string{"work"}) || !reflect.DeepEqual(tags["other"], []string{"home"}) {
+		t.Errorf("unexpected tags after rename: %v", tags)
+	}
+}
+
+func TestDeleteRemovesTags(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"gone", "trashed", "kept"} {
+		os.Mkdir(filepath.Join(tmpDir, name), 0755)
+		AddTag(tmpDir, name, "work")
+	}
+
+	if err := Delete(tmpDir, filepath.Join(tmpDir, "gone")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Trash(tmpDir, filepath.Join(tmpDir, "trashed")); err != nil {
+		t.Fatal(err)
+	}
+
+	// The trashed one's tags wait in the trash for a restore
+	tags, _ := LoadTags(tmpDir)
+	if tags["gone"] != nil || tags["kept"] == nil {
+		t.Errorf("expected gone's tags removed and kept's to remain, got %v", tags)
+	}
+	if tags["trashed"] != nil {
+		t.Errorf("trashed tags should have moved with the workspace, got %v", tags)
+	}
+}
@@ -0,0 +1,53 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestListTemplates(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	for _, name := range []string{"rails", "go-cli", ".hidden"} {
+		os.MkdirAll(filepath.Join(tmpDir, name), 0755)
+	}
+	os.WriteFile(filepath.Join(tmpDir, "README"), nil, 0644)
+
+	names, err := ListTemplates(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 || names[0] != "go-cli" || names[1] != "rails" {
+		t.Errorf("got %v, want [go-cli rails]", names)
+	}
+}
+
+func TestListTemplatesMissingDir(t *testing.T) {
+	names, err := ListTemplates(filepath.Join(t.TempDir(), "nope"))
+	if err != nil {
+		t.Fatalf("missing dir should not be an error: %v", err)
+	}
+	if names == nil || len(names) != 0 {
+		t.Errorf("got %v, want empty list", names)
+	}
+}
+
+func TestTemplatePath(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tmpDir, "rails"), 0755)
+
+	path, err := TemplatePath(tmpDir, "rails")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != filepath.Join(tmpDir, "rails") {
+		t.Errorf("got %s", path)
+	}
+
+	for _, name := range []string{"missing", "../rails", ""} {
+		if _, err := TemplatePath(tmpDir, name); err == nil {
+			t.Errorf("TemplatePath(%q) should fail", name)
+		}
+	}
+}
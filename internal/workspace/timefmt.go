@@ -0,0 +1,61 @@
+package workspace
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeFormat chooses how a workspace's last activity is shown.
+type TimeFormat string
+
+// Time formats. The zero TimeFormat is TimeRelative.
+const (
+	TimeRelative TimeFormat = "relative" // e.g. "3d ago", "2mo ago"
+	TimeAbsolute TimeFormat = "absolute" // e.g. "2025-01-19 14:05"
+)
+
+// absoluteTimeLayout always gives the same width, so a column of absolute
+// times lines up.
+const absoluteTimeLayout = "2006-01-02 15:04"
+
+// ParseTimeFormat returns the TimeFormat named s; empty means relative.
+func ParseTimeFormat(s string) (TimeFormat, error) {
+	switch TimeFormat(s) {
+	case "", TimeRelative:
+		return TimeRelative, nil
+	case TimeAbsolute:
+		return TimeAbsolute, nil
+	}
+	return "", fmt.Errorf("unknown time format %q (valid: relative, absolute)", s)
+}
+
+// FormatTime formats t, as seen at now, in format f.
+func FormatTime(t, now time.Time, f TimeFormat) string {
+	if f == TimeAbsolute {
+		return t.Local().Format(absoluteTimeLayout)
+	}
+
+	d := now.Sub(t)
+	if d < time.Minute {
+		return "just now"
+	}
+	if d < time.Hour {
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	}
+	if d < 24*time.Hour {
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	}
+	days := int(d.Hours() / 24)
+	if days < 7 {
+		return fmt.Sprintf("%dd ago", days)
+	}
+	// Months and years are counted as 30 and 365 days; close enough for
+	// a list that only needs to tell old from very old
+	if days < 30 {
+		return fmt.Sprintf("%dw ago", days/7)
+	}
+	if days < 365 {
+		return fmt.Sprintf("%dmo ago", days/30)
+	}
+	return fmt.Sprintf("%dy ago", days/365)
+}
@@ -0,0 +1,45 @@
+package workspace
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatTime(t *testing.T) {
+	now := time.Date(2025, 1, 19, 14, 5, 0, 0, time.Local)
+
+	tests := []struct {
+		ago    time.Duration
+		format TimeFormat
+		want   string
+	}{
+		{30 * time.Second, TimeRelative, "just now"},
+		{5 * time.Minute, "", "5m ago"},
+		{3 * time.Hour, TimeRelative, "3h ago"},
+		{50 * time.Hour, TimeRelative, "2d ago"},
+		{15 * 24 * time.Hour, TimeRelative, "2w ago"},
+		{29 * 24 * time.Hour, TimeRelative, "4w ago"},
+		{6 * 7 * 24 * time.Hour, TimeRelative, "1mo ago"},
+		{91 * 24 * time.Hour, TimeRelative, "3mo ago"},
+		{364 * 24 * time.Hour, TimeRelative, "12mo ago"},
+		{2 * 365 * 24 * time.Hour, TimeRelative, "2y ago"},
+		{0, TimeAbsolute, "2025-01-19 14:05"},
+		{15 * 24 * time.Hour, TimeAbsolute, "2025-01-04 14:05"},
+	}
+	for _, tt := range tests {
+		if got := FormatTime(now.Add(-tt.ago), now, tt.format); got != tt.want {
+			t.Errorf("FormatTime(-%v, %q) = %q, want %q", tt.ago, tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestParseTimeFormat(t *testing.T) {
+	for s, want := range map[string]TimeFormat{"": TimeRelative, "relative": TimeRelative, "absolute": TimeAbsolute} {
+		if got, err := ParseTimeFormat(s); err != nil || got != want {
+			t.Errorf("ParseTimeFormat(%q) = %q, %v", s, got, err)
+		}
+	}
+	if _, err := ParseTimeFormat("iso"); err == nil {
+		t.Error("unknown format should fail")
+	}
+}
@@ -0,0 +1,150 @@
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TrashDir is the directory inside the tries folder that holds deleted
+// workspaces until they are restored or purged.
+const TrashDir = ".trash"
+
+// trashStampFormat names each trashed item's folder; it sorts
+// chronologically as a string.
+const trashStampFormat = "2006-01-02T15-04-05.000000000"
+
+// trashOriginFile records where a trashed workspace came from, relative to
+// the tries folder.
+const trashOriginFile = ".origin"
+
+// ErrTrashEmpty is returned by Restore when there is nothing to restore.
+var ErrTrashEmpty = errors.New("trash is empty")
+
+// Trash moves a workspace into <basePath>/.trash/<timestamp>/ so it can be
+// restored later, note, tags and meta included. Like Delete, it refuses paths
+// outside basePath. Returns the workspace's new location.
+func Trash(basePath, path string) (string, error) {
+	realBase, realTarget, err := resolveInside(basePath, path)
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(realBase, realTarget)
+	if err != nil {
+		return "", err
+	}
+
+	stamp := time.Now().Format(trashStampFormat)
+	slot := filepath.Join(realBase, TrashDir, stamp)
+	if err := os.MkdirAll(slot, 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(slot, trashOriginFile), []byte(rel), 0644); err != nil {
+		return "", err
+	}
+
+	dest := filepath.Join(slot, filepath.Base(realTarget))
+	if err := Move(realTarget, dest); err != nil {
+		os.RemoveAll(slot)
+		return "", err
+	}
+
+	// The note, tags and meta wait under the trashed name for Restore
+	renameSidecars(realBase, rel, trashedName(stamp, rel))
+
+	return dest, nil
+}
+
+// Restore moves the most recently trashed workspace back to where it was,
+// with its note, tags and meta, uniquifying the name if something has taken its
+// place since. Returns the restored path, or ErrTrashEmpty.
+func Restore(basePath string) (string, error) {
+	slots, err := trashSlots(basePath)
+	if err != nil {
+		return "", err
+	}
+	if len(slots) == 0 {
+		return "", ErrTrashEmpty
+	}
+
+	slot := filepath.Join(basePath, TrashDir, slots[len(slots)-1])
+	data, err := os.ReadFile(filepath.Join(slot, trashOriginFile))
+	if err != nil {
+		return "", fmt.Errorf("failed to read trash entry: %w", err)
+	}
+	rel := strings.TrimSpace(string(data))
+
+	parent := filepath.Join(basePath, filepath.Dir(rel))
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return "", err
+	}
+
+	name := uniqueName(parent, filepath.Base(rel))
+	dest := filepath.Join(parent, name)
+	if err := Move(filepath.Join(slot, filepath.Base(rel)), dest); err != nil {
+		return "", err
+	}
+
+	renameSidecars(basePath, trashedName(slots[len(slots)-1], rel), filepath.Join(filepath.Dir(rel), name))
+
+	return dest, os.RemoveAll(slot)
+}
+
+// PurgeTrash permanently removes workspaces trashed more than olderThan
+// ago and returns how many were removed.
+func PurgeTrash(basePath string, olderThan time.Duration) (int, error) {
+	slots, err := trashSlots(basePath)
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+	purged := 0
+	for _, name := range slots {
+		stamp, err := time.ParseInLocation(trashStampFormat, name, time.Local)
+		if err != nil || !stamp.Before(cutoff) {
+			continue
+		}
+		slot := filepath.Join(basePath, TrashDir, name)
+		if data, err := os.ReadFile(filepath.Join(slot, trashOriginFile)); err == nil {
+			removeSidecars(basePath, trashedName(name, strings.TrimSpace(string(data))))
+		}
+		if err := os.RemoveAll(slot); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+
+	return purged, nil
+}
+
+// trashedName is the name the note, tags and meta of the workspace at rel
+// are kept under while it is in the trash slot stamp.
+func trashedName(stamp, rel string) string {
+	return filepath.Join(TrashDir, stamp, filepath.Base(rel))
+}
+
+// trashSlots returns the trash's timestamp folders, oldest first.
+func trashSlots(basePath string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(basePath, TrashDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var slots []string
+	for _, e := range entries {
+		if e.IsDir() {
+			slots = append(slots, e.Name())
+		}
+	}
+	sort.Strings(slots)
+	return slots, nil
+}
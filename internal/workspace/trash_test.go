@@ -0,0 +1,163 @@
+package workspace
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTrashAndRestore(t *testing.T) {
+	tmpDir := t.TempDir()
+	target := filepath.Join(tmpDir, "2024-01-15-project")
+	if err := os.Mkdir(target, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(target, "file.txt"), []byte("test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	trashed, err := Trash(tmpDir, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Error("original should be gone after trashing")
+	}
+	if _, err := os.Stat(filepath.Join(trashed, "file.txt")); err != nil {
+		t.Errorf("trashed copy should keep its contents: %v", err)
+	}
+
+	// Trash is hidden from scans
+	entries, err := Scan(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected trash to be hidden, got %v", entries)
+	}
+
+	restored, err := Restore(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(restored) != "2024-01-15-project" {
+		t.Errorf("restored to %s", restored)
+	}
+	if _, err := os.Stat(filepath.Join(restored, "file.txt")); err != nil {
+		t.Errorf("restored dir should keep its contents: %v", err)
+	}
+
+	if _, err := Restore(tmpDir); !errors.Is(err, ErrTrashEmpty) {
+		t.Errorf("expected ErrTrashEmpty, got %v", err)
+	}
+}
+
+func TestRestoreMostRecentAndCollision(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"first", "second"} {
+		p := filepath.Join(tmpDir, name)
+		if err := os.Mkdir(p, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Trash(tmpDir, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Something new took the name in the meantime
+	if err := os.Mkdir(filepath.Join(tmpDir, "second"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := Restore(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(restored) != "second-2" {
+		t.Errorf("expected second-2, got %s", filepath.Base(restored))
+	}
+}
+
+func TestTrashKeepsNoteAndTags(t *testing.T) {
+	tmpDir := t.TempDir()
+	target := filepath.Join(tmpDir, "redis")
+	if err := os.Mkdir(target, 0755); err != nil {
+		t.Fatal(err)
+	}
+	SetNote(tmpDir, "redis", "port 6380")
+	SetTags(tmpDir, "redis", []string{"work"})
+
+	if _, err := Trash(tmpDir, target); err != nil {
+		t.Fatal(err)
+	}
+	if note, _ := GetNote(tmpDir, "redis"); note != "" {
+		t.Errorf("note should leave with the workspace, got %q", note)
+	}
+
+	// Restored under a new name, the note and tags follow it
+	if err := os.Mkdir(target, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Restore(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	if note, _ := GetNote(tmpDir, "redis-2"); note != "port 6380" {
+		t.Errorf("restored note = %q", note)
+	}
+	if tags, _ := GetTags(tmpDir, "redis-2"); !reflect.DeepEqual(tags, []string{"work"}) {
+		t.Errorf("restored tags = %v", tags)
+	}
+	if notes, _ := LoadNotes(tmpDir); len(notes) != 1 {
+		t.Errorf("expected only the restored note, got %v", notes)
+	}
+}
+
+func TestTrashSafety(t *testing.T) {
+	tmpDir := t.TempDir()
+	outsideDir := t.TempDir()
+
+	if _, err := Trash(tmpDir, outsideDir); err == nil {
+		t.Error("expected error when trashing outside base path")
+	}
+}
+
+func TestPurgeTrash(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	stamp := time.Now().Add(-8 * 24 * time.Hour).Format(trashStampFormat)
+	old := filepath.Join(tmpDir, TrashDir, stamp)
+	if err := os.MkdirAll(filepath.Join(old, "ancient"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(old, trashOriginFile), []byte("ancient\n"), 0644)
+	SetNote(tmpDir, filepath.Join(TrashDir, stamp, "ancient"), "long gone")
+
+	p := filepath.Join(tmpDir, "recent")
+	if err := os.Mkdir(p, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Trash(tmpDir, p); err != nil {
+		t.Fatal(err)
+	}
+
+	purged, err := PurgeTrash(tmpDir, 7*24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged != 1 {
+		t.Errorf("expected 1 purged, got %d", purged)
+	}
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Error("old trash should be purged")
+	}
+	if notes, _ := LoadNotes(tmpDir); len(notes) != 0 {
+		t.Errorf("purged workspace's note should go too, got %v", notes)
+	}
+
+	if _, err := Restore(tmpDir); err != nil {
+		t.Errorf("recent trash should survive purge: %v", err)
+	}
+}
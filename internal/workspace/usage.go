@@ -0,0 +1,128 @@
+package workspace
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+)
+
+// Usage summarizes what a workspace holds.
+type Usage struct {
+	Files       int   // regular files counted
+	Bytes       int64 // their total size
+	Truncated   bool  // the walk stopped early; there is more than this
+	Approximate bool  // directories were skipped; there may be more than this
+}
+
+// Add returns the usage of u and v together, as of two workspaces.
+func (u Usage) Add(v Usage) Usage {
+	return Usage{
+		Files:       u.Files + v.Files,
+		Bytes:       u.Bytes + v.Bytes,
+		Truncated:   u.Truncated || v.Truncated,
+		Approximate: u.Approximate || v.Approximate,
+	}
+}
+
+// Size formats u's bytes with FormatBytes, followed by a "+" if the walk
+// was cut short, e.g. "3.4 MB+".
+func (u Usage) Size() string {
+	if u.Truncated || u.Approximate {
+		return FormatBytes(u.Bytes) + "+"
+	}
+	return FormatBytes(u.Bytes)
+}
+
+// FormatBytes formats n in decimal units, e.g. "340 B" or "3.4 MB".
+func FormatBytes(n int64) string {
+	if n < 1000 {
+		return fmt.Sprintf("%d B", n)
+	}
+	size := float64(n)
+	for _, unit := range []string{"kB", "MB", "GB", "TB"} {
+		size /= 1000
+		if size < 1000 || unit == "TB" {
+			return fmt.Sprintf("%.1f %s", size, unit)
+		}
+	}
+	return ""
+}
+
+// DefaultUsageSkip names the directories a size walk doesn't descend into
+// unless configured otherwise: dependency and build trees that are large,
+// slow to walk and easy to recreate.
+var DefaultUsageSkip = []string{"node_modules", ".git", "target"}
+
+// UsageOptions bounds a size walk.
+type UsageOptions struct {
+	MaxFiles int      // stop after this many files; zero means no limit
+	MaxDepth int      // directory levels to descend below dir; zero means no limit
+	Skip     []string // directory names not to descend into
+}
+
+// DirUsage counts the regular files under dir and adds up their sizes,
+// stopping after maxFiles files (zero means no limit). Symlinks are not
+// followed, and unreadable subdirectories are skipped.
+func DirUsage(dir string, maxFiles int) (Usage, error) {
+	return DirUsageWithOptions(dir, UsageOptions{MaxFiles: maxFiles})
+}
+
+// DirUsageWithOptions is DirUsage bounded by opts. Directories it skips,
+// by name or depth, set Approximate on the result.
+func DirUsageWithOptions(dir string, opts UsageOptions) (Usage, error) {
+	skip := make(map[string]bool, len(opts.Skip))
+	for _, name := range opts.Skip {
+		skip[name] = true
+	}
+
+	var u Usage
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if path == dir {
+				return nil
+			}
+			if skip[d.Name()] || (opts.MaxDepth > 0 && depthBelow(dir, path) > opts.MaxDepth) {
+				u.Approximate = true
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if opts.MaxFiles > 0 && u.Files >= opts.MaxFiles {
+			u.Truncated = true
+			return fs.SkipAll
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		u.Files++
+		u.Bytes += info.Size()
+		return nil
+	})
+	return u, err
+}
+
+// depthBelow returns how many directory levels path is below dir, 1 for
+// dir's own children.
+func depthBelow(dir, path string) int {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return 0
+	}
+	depth := 1
+	for _, c := range rel {
+		if c == filepath.Separator {
+			depth++
+		}
+	}
+	return depth
+}
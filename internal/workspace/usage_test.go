@@ -0,0 +1,76 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDirUsage(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.txt"), make([]byte, 100), 0644)
+	os.MkdirAll(filepath.Join(dir, "src", "deep"), 0755)
+	os.WriteFile(filepath.Join(dir, "src", "b.go"), make([]byte, 20), 0644)
+	os.WriteFile(filepath.Join(dir, "src", "deep", "c.go"), make([]byte, 3), 0644)
+	os.Symlink(filepath.Join(dir, "a.txt"), filepath.Join(dir, "link"))
+
+	u, err := DirUsage(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Files != 3 || u.Bytes != 123 || u.Truncated {
+		t.Errorf("got %+v, want 3 files of 123 bytes", u)
+	}
+
+	u, err = DirUsage(dir, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Files != 2 || !u.Truncated {
+		t.Errorf("got %+v, want a walk stopped at 2 files", u)
+	}
+
+	// Exactly at the limit is not truncated
+	if u, _ := DirUsage(dir, 3); u.Truncated {
+		t.Errorf("got %+v, want all 3 files without truncation", u)
+	}
+
+	if _, err := DirUsage(filepath.Join(dir, "missing"), 0); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
+
+func TestDirUsageBounded(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.txt"), make([]byte, 100), 0644)
+	os.MkdirAll(filepath.Join(dir, "node_modules", "left-pad"), 0755)
+	os.WriteFile(filepath.Join(dir, "node_modules", "left-pad", "index.js"), make([]byte, 50), 0644)
+	os.MkdirAll(filepath.Join(dir, "src", "deep"), 0755)
+	os.WriteFile(filepath.Join(dir, "src", "b.go"), make([]byte, 20), 0644)
+	os.WriteFile(filepath.Join(dir, "src", "deep", "c.go"), make([]byte, 3), 0644)
+
+	tests := []struct {
+		name   string
+		opts   UsageOptions
+		files  int
+		bytes  int64
+		approx bool
+	}{
+		{"unbounded", UsageOptions{}, 4, 173, false},
+		{"skip", UsageOptions{Skip: DefaultUsageSkip}, 3, 123, true},
+		{"depth", UsageOptions{MaxDepth: 1}, 2, 120, true},
+		{"depth fits", UsageOptions{MaxDepth: 2}, 4, 173, false},
+		{"both", UsageOptions{MaxDepth: 1, Skip: DefaultUsageSkip}, 2, 120, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := DirUsageWithOptions(dir, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if u.Files != tt.files || u.Bytes != tt.bytes || u.Approximate != tt.approx || u.Truncated {
+				t.Errorf("got %+v, want %d files of %d bytes, approximate %v", u, tt.files, tt.bytes, tt.approx)
+			}
+		})
+	}
+}
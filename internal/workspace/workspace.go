@@ -2,10 +2,13 @@
 package workspace
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -13,10 +16,19 @@ import (
 
 // Entry represents a directory in the tries folder.
 type Entry struct {
-	Name      string    // Directory name (basename)
-	Path      string    // Full path
-	ModTime   time.Time // Last modification time
-	BaseScore float64   // Pre-computed score based on recency
+	Name        string    // Directory name (basename)
+	Path        string    // Full path
+	ModTime     time.Time // Last modification time
+	CommitTime  time.Time // Latest git commit; zero unless ApplyCommitTimes found one
+	CreatedDate time.Time // Date from the YYYY-MM-DD- prefix; zero if none
+	BaseScore   float64   // Pre-computed score based on recency
+	Symlink     bool      // Entry is a symlink to a directory, or with IsFile to a file
+	IsFile      bool      // Entry is a regular file, listed with ScanOptions.IncludeFiles
+	Broken      bool      // Entry is a symlink whose target is missing or loops
+	Hidden      bool      // Entry's name starts with ".", listed with ScanOptions.IncludeHidden
+	Kind        Kind      // How the workspace came to be, from MetaFile
+	CreatedAt   time.Time // When try made the workspace, from MetaFile; zero if unknown
+	SourceURL   string    // What the workspace was cloned from, from MetaFile
 }
 
 // DefaultPath returns the default tries directory path.
@@ -37,66 +49,364 @@ func expandPath(path string) string {
 	return path
 }
 
+// NormalizePath expands ~, makes path absolute and cleans it, so that
+// "./tries", "~/src/tries/" and "/home/me/src/tries" all compare equal.
+func NormalizePath(path string) string {
+	if path == "~" {
+		path, _ = os.UserHomeDir()
+	}
+	path = expandPath(path)
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
 // EnsureDir creates the directory if it doesn't exist.
 func EnsureDir(path string) error {
 	return os.MkdirAll(path, 0755)
 }
 
+// ScanBatch is a group of entries delivered by ScanStream.
+type ScanBatch struct {
+	Entries []Entry
+	Err     error
+}
+
+// ScanOptions controls how the tries directory is scanned.
+type ScanOptions struct {
+	// MaxDepth is how many directory levels to descend. At 1 (or 0) only
+	// the top level is listed. Deeper scans treat directories that contain
+	// subdirectories as topic folders and list their leaves instead, using
+	// the path relative to the base as the entry name. Date-prefixed
+	// directories are always leaves.
+	MaxDepth int
+
+	// BatchSize is how many entries ScanStream delivers at a time.
+	// Zero delivers everything in a single batch.
+	BatchSize int
+
+	// IncludeFiles also lists regular files, for tries that are a single
+	// script. Hidden files are still skipped unless IncludeHidden is set.
+	IncludeFiles bool
+
+	// IncludeHidden also lists entries whose names start with ".". try's
+	// own files (see sidecars) are skipped regardless.
+	IncludeHidden bool
+}
+
 // Scan reads all directories in basePath and returns them sorted by recency.
 func Scan(basePath string) ([]Entry, error) {
-	entries, err := os.ReadDir(basePath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return []Entry{}, nil
+	return ScanWithOptions(basePath, ScanOptions{})
+}
+
+// ScanWithOptions is like Scan but honors opts.
+func ScanWithOptions(basePath string, opts ScanOptions) ([]Entry, error) {
+	return ScanContext(context.Background(), basePath, opts)
+}
+
+// ScanContext is like ScanWithOptions but gives up when ctx is done, such
+// as on a hung network mount. It then returns the entries found so far,
+// sorted, with an error wrapping ctx.Err().
+func ScanContext(ctx context.Context, basePath string, opts ScanOptions) ([]Entry, error) {
+	result := []Entry{}
+	scan := ScanStreamContext(ctx, basePath, opts)
+	for {
+		select {
+		case batch, ok := <-scan:
+			if !ok {
+				SortEntries(result)
+				if err := ctx.Err(); err != nil {
+					return result, scanStopped(basePath, err)
+				}
+				return result, nil
+			}
+			if batch.Err != nil {
+				return nil, batch.Err
+			}
+			result = append(result, batch.Entries...)
+		case <-ctx.Done():
+			// The walk may be stuck in a read it can't abandon
+			SortEntries(result)
+			return result, scanStopped(basePath, ctx.Err())
 		}
-		return nil, err
 	}
+}
 
-	now := time.Now()
-	datePrefix := regexp.MustCompile(`^\d{4}-\d{2}-\d{2}-`)
+// scanStopped is the error for a scan of basePath cut short by err.
+func scanStopped(basePath string, err error) error {
+	return fmt.Errorf("scan of %s stopped early: %w", basePath, err)
+}
 
-	var result []Entry
-	for _, e := range entries {
-		// Skip hidden directories
-		if strings.HasPrefix(e.Name(), ".") {
-			continue
+// ScanStream reads basePath in the background and delivers entries in
+// batches of opts.BatchSize as they are stat'd, so callers can render large
+// directories incrementally. Entries arrive unsorted; call SortEntries once
+// the channel is closed.
+func ScanStream(basePath string, opts ScanOptions) <-chan ScanBatch {
+	return ScanStreamContext(context.Background(), basePath, opts)
+}
+
+// ScanStreamContext is like ScanStream but stops once ctx is done, closing
+// the channel without delivering the rest. A read that blocks can't be
+// interrupted, so callers that must not hang should also select on
+// ctx.Done() while waiting for a batch.
+func ScanStreamContext(ctx context.Context, basePath string, opts ScanOptions) <-chan ScanBatch {
+	ch := make(chan ScanBatch)
+
+	// send delivers a batch unless ctx ends first
+	send := func(b ScanBatch) bool {
+		select {
+		case ch <- b:
+			return true
+		case <-ctx.Done():
+			return false
 		}
+	}
 
-		// Only include directories
-		if !e.IsDir() {
-			continue
+	maxDepth := opts.MaxDepth
+	if maxDepth < 1 {
+		maxDepth = 1
+	}
+
+	go func() {
+		defer close(ch)
+
+		now := time.Now()
+		var batch []Entry
+		// Without readable meta, workspaces are just of unknown kind
+		metas, _ := LoadMeta(basePath)
+
+		var walk func(rel string, depth int) error
+		walk = func(rel string, depth int) error {
+			entries, err := os.ReadDir(filepath.Join(basePath, rel))
+			if err != nil {
+				return err
+			}
+
+			for _, e := range entries {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+
+				// Skip try's own files, and hidden entries unless asked
+				hidden := strings.HasPrefix(e.Name(), ".")
+				if sidecars[e.Name()] || (hidden && !opts.IncludeHidden) {
+					continue
+				}
+
+				name := filepath.Join(rel, e.Name())
+
+				// Symlinks count if they point at a directory (or a file,
+				// with IncludeFiles). Stat fails on dangling links and on
+				// loops, which are listed as broken so they can be deleted.
+				symlink := e.Type()&os.ModeSymlink != 0
+				isFile := false
+				var info os.FileInfo
+				if symlink {
+					target, err := os.Stat(filepath.Join(basePath, name))
+					if err != nil {
+						if link, err := e.Info(); err == nil {
+							entry := newEntry(basePath, name, link.ModTime(), now)
+							entry.Symlink = true
+							entry.Broken = true
+							entry.Hidden = hidden
+							batch = append(batch, entry)
+						}
+						continue
+					}
+					isFile = target.Mode().IsRegular()
+					if !target.IsDir() && !(isFile && opts.IncludeFiles) {
+						continue
+					}
+					info = target
+				} else if !e.IsDir() {
+					// Only include directories, and files if asked
+					if !opts.IncludeFiles || !e.Type().IsRegular() {
+						continue
+					}
+					isFile = true
+				}
+
+				// Symlinked folders are never descended into, so a link
+				// back up the tree can't make the walk loop
+				_, _, dated := ParseName(e.Name())
+				if depth < maxDepth && !dated && !symlink && !isFile && isTopicFolder(filepath.Join(basePath, name)) {
+					// Topic folder: list what's inside instead. Unreadable
+					// subfolders are skipped like unreadable entries, and a
+					// stop is noticed by the next iteration.
+					_ = walk(name, depth+1)
+					continue
+				}
+
+				if info == nil {
+					fi, err := e.Info()
+					if err != nil {
+						continue
+					}
+					info = fi
+				}
+
+				entry := newEntry(basePath, name, info.ModTime(), now)
+				entry.Symlink = symlink
+				entry.IsFile = isFile
+				entry.Hidden = hidden
+				if !isFile {
+					meta := metas[name]
+					entry.Kind = meta.Kind
+					entry.CreatedAt = meta.CreatedAt
+					entry.SourceURL = meta.SourceURL
+				}
+				batch = append(batch, entry)
+				if opts.BatchSize > 0 && len(batch) >= opts.BatchSize {
+					if !send(ScanBatch{Entries: batch}) {
+						return ctx.Err()
+					}
+					batch = nil
+				}
+			}
+			return nil
 		}
 
-		info, err := e.Info()
-		if err != nil {
-			continue
+		if err := walk("", 1); err != nil {
+			// A stopped scan just ends; the caller knows why
+			if !os.IsNotExist(err) && ctx.Err() == nil {
+				send(ScanBatch{Err: err})
+			}
+			return
+		}
+
+		if len(batch) > 0 {
+			send(ScanBatch{Entries: batch})
 		}
+	}()
+
+	return ch
+}
+
+// sidecars are the files and directories try keeps in the tries directory
+// for itself. Scans never list them, whether or not they are hidden, so a
+// new one only needs adding here.
+var sidecars = map[string]bool{
+	CacheFile:      true,
+	NotesFile:      true,
+	TagsFile:       true,
+	MetaFile:       true,
+	PendingMetaDir: true,
+	TrashDir:       true,
+	ArchiveDir:     true,
+}
 
-		mtime := info.ModTime()
-		hoursSinceAccess := now.Sub(mtime).Hours()
+// renameSidecars moves the note, tags and meta of workspace oldName to
+// newName. They are best-effort; leftovers are harmless.
+func renameSidecars(basePath, oldName, newName string) {
+	_ = RenameNote(basePath, oldName, newName)
+	_ = RenameTags(basePath, oldName, newName)
+	_ = RenameMeta(basePath, oldName, newName)
+}
+
+// removeSidecars drops the note, tags and meta of the workspace called
+// name, best-effort like renameSidecars.
+func removeSidecars(basePath, name string) {
+	_ = RemoveNote(basePath, name)
+	_ = RemoveTags(basePath, name)
+	_ = RemoveMeta(basePath, name)
+}
 
-		// Base score from recency: 3.0 / sqrt(hours + 1)
-		baseScore := 3.0 / sqrt(hoursSinceAccess+1)
+// vcsMarkers are the directories that make a folder a repository, and so
+// a workspace rather than a topic folder.
+var vcsMarkers = []string{".git", ".hg", ".svn", ".jj"}
 
-		// Bonus for date-prefixed directories
-		if datePrefix.MatchString(e.Name()) {
-			baseScore += 2.0
+// isTopicFolder reports whether the undated directory dir groups
+// workspaces rather than being one: it isn't a repository, and it either
+// holds a dated workspace or holds only directories. A project with src/
+// and a README is a workspace; web/ holding 2024-01-15-react-demo/ is not.
+func isTopicFolder(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	subdirs, files, datedChild := false, false, false
+	for _, e := range entries {
+		if slices.Contains(vcsMarkers, e.Name()) {
+			return false
 		}
+		if strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		switch {
+		case e.IsDir():
+			subdirs = true
+			if _, _, dated := ParseName(e.Name()); dated {
+				datedChild = true
+			}
+		case e.Type().IsRegular():
+			files = true
+		}
+	}
+	return datedChild || (subdirs && !files)
+}
 
-		result = append(result, Entry{
-			Name:      e.Name(),
-			Path:      filepath.Join(basePath, e.Name()),
-			ModTime:   mtime,
-			BaseScore: baseScore,
-		})
+// newEntry builds an Entry and computes its recency score. name may be a
+// path relative to basePath for nested entries.
+func newEntry(basePath, name string, mtime, now time.Time) Entry {
+	created, _, _ := ParseName(filepath.Base(name))
+
+	return Entry{
+		Name:        name,
+		Path:        filepath.Join(basePath, name),
+		ModTime:     mtime,
+		CreatedDate: created,
+		BaseScore:   recencyScore(name, mtime, now),
 	}
+}
 
-	// Sort by modification time (most recent first)
-	sort.Slice(result, func(i, j int) bool {
-		return result[i].ModTime.After(result[j].ModTime)
-	})
+// recencyScore scores an entry last active at t.
+func recencyScore(name string, t, now time.Time) float64 {
+	hoursSinceAccess := now.Sub(t).Hours()
 
-	return result, nil
+	// Base score from recency: 3.0 / sqrt(hours + 1)
+	baseScore := 3.0 / sqrt(hoursSinceAccess+1)
+
+	// Bonus for date-prefixed directories
+	if _, _, hasDate := ParseName(filepath.Base(name)); hasDate {
+		baseScore += 2.0
+	}
+
+	return baseScore
+}
+
+// ParseName splits a directory name into its date prefix, in the format
+// set by SetDateFormat (YYYY-MM-DD- by default), and the remaining label.
+// Names without a valid date prefix are returned whole as the label with
+// hasDate false.
+//
+// The prefix is pure ASCII, so it is found by byte offset: a name with a
+// multibyte character where the date should be simply has no date, and the
+// label of a dated name always starts on a character boundary.
+func ParseName(name string) (date time.Time, label string, hasDate bool) {
+	loc := datePattern.FindStringIndex(name)
+	if loc == nil {
+		return time.Time{}, name, false
+	}
+
+	date, err := time.ParseInLocation(dateFormat, name[:loc[1]-1], time.Local)
+	if err != nil {
+		return time.Time{}, name, false
+	}
+
+	return date, name[loc[1]:], true
+}
+
+// SortEntries sorts entries by modification time, most recent first.
+// Entries with the same mtime are ordered by name, so the order doesn't
+// change between runs.
+func SortEntries(entries []Entry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].ModTime.Equal(entries[j].ModTime) {
+			return entries[i].ModTime.After(entries[j].ModTime)
+		}
+		return entries[i].Name < entries[j].Name
+	})
 }
 
 // sqrt is a simple square root approximation using Newton's method.
@@ -121,14 +431,98 @@ func Touch(path string) error {
 }
 
 // Create creates a new date-prefixed directory and returns its path.
+// Names that would escape basePath are rejected (see ValidateName).
 func Create(basePath, name string) (string, error) {
-	// Sanitize name: replace spaces with hyphens
-	name = strings.ReplaceAll(strings.TrimSpace(name), " ", "-")
+	if err := validateCreateName(name); err != nil {
+		return "", err
+	}
+	return createDir(basePath, DirName(name, true))
+}
+
+// CreateRaw creates a new directory without the date prefix and returns its path.
+func CreateRaw(basePath, name string) (string, error) {
+	if err := validateCreateName(name); err != nil {
+		return "", err
+	}
+	return createDir(basePath, DirName(name, false))
+}
+
+// validateCreateName is ValidateName for a name that must also keep some
+// letters or digits once sanitized.
+func validateCreateName(name string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	if sanitizeDirName(name) == "" {
+		return fmt.Errorf("invalid workspace name %q: needs a letter or digit", name)
+	}
+	return nil
+}
+
+// DirName returns the directory name Create (dated) or CreateRaw gives
+// name before making it unique: sanitized (see sanitizeDirName), and for
+// dated names prefixed with today's date. A created directory named
+// otherwise got a suffix because this one was taken.
+func DirName(name string, dated bool) string {
+	name = sanitizeDirName(name)
+	if dated {
+		return fmt.Sprintf("%s-%s", DatePrefix(), name)
+	}
+	return name
+}
+
+// lowercaseNames makes sanitizeDirName lowercase names; see
+// SetLowercaseNames.
+var lowercaseNames bool
+
+// SetLowercaseNames sets whether new workspace and clone names are
+// lowercased, so Repo.JS is created as repo.js.
+func SetLowercaseNames(enabled bool) {
+	lowercaseNames = enabled
+}
+
+// nameSeparatorRuns matches runs of characters that are neither letters nor
+// digits, in any script.
+var nameSeparatorRuns = regexp.MustCompile(`[^\p{L}\p{N}]+`)
 
-	// Create date prefix
-	datePrefix := time.Now().Format("2006-01-02")
-	dirName := fmt.Sprintf("%s-%s", datePrefix, name)
+// sanitizeDirName makes name easy to type and safe as a directory name:
+// every run of characters other than letters and digits becomes a single
+// "-", except a lone "." or "_" between two words, as in repo.js or
+// my_tool, and separators are trimmed from the ends, so the name can't be
+// hidden or a path. It lowercases name if SetLowercaseNames asked to.
+func sanitizeDirName(name string) string {
+	if lowercaseNames {
+		name = strings.ToLower(name)
+	}
+	name = nameSeparatorRuns.ReplaceAllStringFunc(name, func(run string) string {
+		if run == "." || run == "_" {
+			return run
+		}
+		return "-"
+	})
+	return strings.Trim(name, "-._")
+}
 
+// ValidateName checks that name can be used as a workspace name: it must
+// not be empty and must not contain path separators or ".." that would
+// place the directory outside the tries folder.
+func ValidateName(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("workspace name cannot be empty")
+	}
+	if strings.ContainsAny(name, `/\`) || strings.ContainsRune(name, filepath.Separator) {
+		return fmt.Errorf("invalid workspace name %q: must not contain path separators", name)
+	}
+	if name == "." || name == ".." {
+		return fmt.Errorf("invalid workspace name %q", name)
+	}
+	return nil
+}
+
+// createDir creates dirName inside basePath, uniquifying it if needed,
+// and records it as KindCreated.
+func createDir(basePath, dirName string) (string, error) {
 	// Ensure unique name
 	dirName = uniqueName(basePath, dirName)
 
@@ -136,6 +530,9 @@ func Create(basePath, name string) (string, error) {
 	if err := os.MkdirAll(fullPath, 0755); err != nil {
 		return "", err
 	}
+	if err := SetMeta(basePath, dirName, Meta{Kind: KindCreated, CreatedAt: time.Now()}); err != nil {
+		return "", err
+	}
 
 	return fullPath, nil
 }
@@ -154,40 +551,87 @@ func uniqueName(basePath, name string) string {
 	}
 }
 
-// DatePrefix returns today's date in YYYY-MM-DD format.
+// DatePrefix returns today's date in the date prefix format.
 func DatePrefix() string {
-	return time.Now().Format("2006-01-02")
+	return FormatDate(time.Now())
 }
 
-// Delete removes a directory and all its contents.
+// Delete removes a directory and all its contents, along with its note,
+// tags and meta.
 // It validates that the path is inside basePath for safety.
 func Delete(basePath, path string) error {
+	realBase, realTarget, err := resolveInside(basePath, path)
+	if err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(realTarget); err != nil {
+		return err
+	}
+
+	if rel, err := filepath.Rel(realBase, realTarget); err == nil {
+		removeSidecars(realBase, rel)
+	}
+	return nil
+}
+
+// IsEmpty reports whether the workspace at path holds nothing, i.e. it was
+// created and never used.
+func IsEmpty(path string) (bool, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return false, err
+	}
+	return len(entries) == 0, nil
+}
+
+// ErrBaseDir is returned when asked to delete the tries directory itself.
+var ErrBaseDir = errors.New("refusing to delete base directory")
+
+// resolveInside resolves basePath and path to absolute, symlink-free paths
+// and checks that path is a proper descendant of basePath. A path that
+// resolves to basePath itself fails with ErrBaseDir.
+func resolveInside(basePath, path string) (realBase, realTarget string, err error) {
 	// Resolve to absolute paths
 	absBase, err := filepath.Abs(basePath)
 	if err != nil {
-		return fmt.Errorf("failed to resolve base path: %w", err)
+		return "", "", fmt.Errorf("failed to resolve base path: %w", err)
 	}
 
 	absTarget, err := filepath.Abs(path)
 	if err != nil {
-		return fmt.Errorf("failed to resolve target path: %w", err)
+		return "", "", fmt.Errorf("failed to resolve target path: %w", err)
 	}
 
 	// Resolve symlinks
-	realBase, err := filepath.EvalSymlinks(absBase)
+	realBase, err = filepath.EvalSymlinks(absBase)
 	if err != nil {
-		return fmt.Errorf("failed to resolve base symlinks: %w", err)
+		return "", "", fmt.Errorf("failed to resolve base symlinks: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(absTarget); err == nil && resolved == realBase {
+		return "", "", ErrBaseDir
 	}
 
-	realTarget, err := filepath.EvalSymlinks(absTarget)
+	// Only the target's parents are resolved, so a symlinked workspace is
+	// removed as a link, even a broken one, and what it points at is left
+	// alone
+	parent, err := filepath.EvalSymlinks(filepath.Dir(absTarget))
 	if err != nil {
-		return fmt.Errorf("failed to resolve target symlinks: %w", err)
+		return "", "", fmt.Errorf("failed to resolve target symlinks: %w", err)
 	}
+	realTarget = filepath.Join(parent, filepath.Base(absTarget))
 
-	// Safety check: target must be inside base
-	if !strings.HasPrefix(realTarget, realBase+string(filepath.Separator)) {
-		return fmt.Errorf("safety check failed: %s is not inside %s", realTarget, realBase)
+	// Safety check: target must be strictly inside base
+	rel, err := filepath.Rel(realBase, realTarget)
+	if err != nil {
+		return "", "", fmt.Errorf("safety check failed: %s is not inside %s", realTarget, realBase)
+	}
+	if rel == "." {
+		return "", "", ErrBaseDir
+	}
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", "", fmt.Errorf("safety check failed: %s is not inside %s", realTarget, realBase)
 	}
 
-	return os.RemoveAll(realTarget)
+	return realBase, realTarget, nil
 }
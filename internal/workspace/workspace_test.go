@@ -1,8 +1,13 @@
 package workspace
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"testing"
 	"time"
 )
@@ -52,6 +57,40 @@ func TestScan(t *testing.T) {
 	}
 }
 
+func TestScanSameModTime(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	same := time.Date(2025, 1, 19, 12, 0, 0, 0, time.Local)
+	newer := same.Add(time.Hour)
+	dirs := map[string]time.Time{
+		"delta": same, "alpha": same, "charlie": same, "bravo": same, "newest": newer,
+	}
+	for d, mtime := range dirs {
+		path := filepath.Join(tmpDir, d)
+		if err := os.Mkdir(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{"newest", "alpha", "bravo", "charlie", "delta"}
+	for run := 0; run < 5; run++ {
+		entries, err := Scan(tmpDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, e := range entries {
+			got = append(got, e.Name)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Fatalf("run %d: got %v, want %v", run, got, want)
+		}
+	}
+}
+
 func TestScanEmpty(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -75,6 +114,389 @@ func TestScanNonExistent(t *testing.T) {
 	}
 }
 
+func TestScanStream(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	for i := 0; i < 5; i++ {
+		if err := os.Mkdir(filepath.Join(tmpDir, fmt.Sprintf("dir-%d", i)), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var batches, total int
+	for batch := range ScanStream(tmpDir, ScanOptions{BatchSize: 2}) {
+		if batch.Err != nil {
+			t.Fatal(batch.Err)
+		}
+		if len(batch.Entries) > 2 {
+			t.Errorf("batch larger than requested: %d", len(batch.Entries))
+		}
+		batches++
+		total += len(batch.Entries)
+	}
+
+	if batches != 3 {
+		t.Errorf("expected 3 batches, got %d", batches)
+	}
+	if total != 5 {
+		t.Errorf("expected 5 entries, got %d", total)
+	}
+}
+
+func TestScanSkipsSidecars(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Mkdir(filepath.Join(tmpDir, "redis"), 0755)
+	os.Mkdir(filepath.Join(tmpDir, "try-pins"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, "try-pins.json"), nil, 0644)
+
+	// Sidecars are skipped by name, not only for being hidden
+	sidecars["try-pins"] = true
+	sidecars["try-pins.json"] = true
+	t.Cleanup(func() {
+		delete(sidecars, "try-pins")
+		delete(sidecars, "try-pins.json")
+	})
+
+	entries, err := ScanWithOptions(tmpDir, ScanOptions{IncludeFiles: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name != "redis" {
+		t.Errorf("expected only redis, got %+v", entries)
+	}
+}
+
+func TestScanIncludeHidden(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, dir := range []string{"redis", ".config-try", TrashDir, PendingMetaDir} {
+		os.Mkdir(filepath.Join(tmpDir, dir), 0755)
+	}
+	os.WriteFile(filepath.Join(tmpDir, CacheFile), nil, 0644)
+	os.WriteFile(filepath.Join(tmpDir, NotesFile), nil, 0644)
+
+	entries, err := ScanWithOptions(tmpDir, ScanOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name != "redis" {
+		t.Errorf("hidden entries should be skipped by default, got %+v", entries)
+	}
+
+	entries, err = ScanWithOptions(tmpDir, ScanOptions{IncludeHidden: true, IncludeFiles: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hidden := map[string]bool{}
+	for _, e := range entries {
+		hidden[e.Name] = e.Hidden
+	}
+	if len(hidden) != 2 || !hidden[".config-try"] || hidden["redis"] {
+		t.Errorf("want redis and a hidden .config-try, and no sidecars, got %+v", entries)
+	}
+}
+
+func TestScanContext(t *testing.T) {
+	tmpDir := t.TempDir()
+	for i := 0; i < 20; i++ {
+		os.Mkdir(filepath.Join(tmpDir, fmt.Sprintf("dir-%d", i)), 0755)
+	}
+
+	entries, err := ScanContext(context.Background(), tmpDir, ScanOptions{})
+	if err != nil || len(entries) != 20 {
+		t.Fatalf("got %d entries, %v; want 20", len(entries), err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+	if _, err := ScanContext(ctx, tmpDir, ScanOptions{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expired deadline: got %v, want DeadlineExceeded", err)
+	}
+
+	// A stream stopped partway closes without delivering the rest
+	ctx, cancel = context.WithCancel(context.Background())
+	scan := ScanStreamContext(ctx, tmpDir, ScanOptions{BatchSize: 1})
+	<-scan
+	cancel()
+	got := 1
+	for batch := range scan {
+		got += len(batch.Entries)
+	}
+	if got > 2 {
+		t.Errorf("stopped stream delivered %d entries, want at most 2", got)
+	}
+}
+
+func TestScanDepth(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	dirs := []string{
+		"web/2024-01-15-react-demo",
+		"web/svelte",
+		"ml/2024-02-01-mnist",
+		"2024-03-01-top-level/src",
+		"plain",
+	}
+	for _, d := range dirs {
+		if err := os.MkdirAll(filepath.Join(tmpDir, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Depth 1 sees only the top level
+	entries, err := Scan(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 {
+		t.Errorf("expected 4 top-level entries, got %d", len(entries))
+	}
+
+	entries, err = ScanWithOptions(tmpDir, ScanOptions{MaxDepth: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]Entry{}
+	for _, e := range entries {
+		got[e.Name] = e
+	}
+
+	want := []string{
+		filepath.Join("web", "2024-01-15-react-demo"),
+		filepath.Join("web", "svelte"),
+		filepath.Join("ml", "2024-02-01-mnist"),
+		"2024-03-01-top-level", // dated dirs are leaves even with subdirs
+		"plain",
+	}
+	if len(got) != len(want) {
+		t.Errorf("expected %d entries, got %v", len(want), entries)
+	}
+	for _, name := range want {
+		e, ok := got[name]
+		if !ok {
+			t.Errorf("missing entry %s", name)
+			continue
+		}
+		if e.Path != filepath.Join(tmpDir, name) {
+			t.Errorf("path for %s: got %s", name, e.Path)
+		}
+	}
+
+	// Date-prefix bonus applies to the leaf basename
+	dated := got[filepath.Join("web", "2024-01-15-react-demo")]
+	undated := got[filepath.Join("web", "svelte")]
+	if dated.BaseScore <= undated.BaseScore {
+		t.Errorf("expected dated leaf to score higher: %f <= %f", dated.BaseScore, undated.BaseScore)
+	}
+}
+
+func TestScanSymlinks(t *testing.T) {
+	tmpDir := t.TempDir()
+	outside := t.TempDir()
+
+	os.Mkdir(filepath.Join(tmpDir, "real"), 0755)
+	os.Symlink(outside, filepath.Join(tmpDir, "linked"))
+	os.WriteFile(filepath.Join(outside, "file.txt"), nil, 0644)
+	os.Symlink(filepath.Join(outside, "file.txt"), filepath.Join(tmpDir, "file-link"))
+	os.Symlink(filepath.Join(tmpDir, "missing"), filepath.Join(tmpDir, "dangling"))
+	os.Symlink(filepath.Join(tmpDir, "loop"), filepath.Join(tmpDir, "loop"))
+
+	entries, err := Scan(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]Entry{}
+	for _, e := range entries {
+		got[e.Name] = e
+	}
+	if len(got) != 4 {
+		t.Fatalf("expected real, linked, dangling and loop, got %v", got)
+	}
+	if e, ok := got["linked"]; !ok || !e.Symlink || e.Broken {
+		t.Error("symlinked directory should be listed and marked as a symlink")
+	}
+	if e, ok := got["real"]; !ok || e.Symlink || e.Broken {
+		t.Error("real directory should be listed and not marked as a symlink")
+	}
+	for _, name := range []string{"dangling", "loop"} {
+		if e, ok := got[name]; !ok || !e.Symlink || !e.Broken {
+			t.Errorf("%s should be listed and flagged as broken, got %+v", name, e)
+		}
+	}
+}
+
+func TestTrashBrokenSymlink(t *testing.T) {
+	tmpDir := t.TempDir()
+	outside := t.TempDir()
+	dangling := filepath.Join(tmpDir, "dangling")
+	linked := filepath.Join(tmpDir, "linked")
+	os.Symlink(filepath.Join(tmpDir, "missing"), dangling)
+	os.Symlink(outside, linked)
+
+	for _, link := range []string{dangling, linked} {
+		if _, err := Trash(tmpDir, link); err != nil {
+			t.Fatalf("Trash(%s): %v", link, err)
+		}
+		if _, err := os.Lstat(link); !os.IsNotExist(err) {
+			t.Errorf("%s should be gone", link)
+		}
+	}
+	if _, err := os.Stat(outside); err != nil {
+		t.Error("a link's target should be left alone")
+	}
+}
+
+func TestScanIncludeFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	os.Mkdir(filepath.Join(tmpDir, "2025-01-19-redis"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, "scrape.py"), nil, 0644)
+	os.WriteFile(filepath.Join(tmpDir, ".hidden.sh"), nil, 0644)
+	os.WriteFile(filepath.Join(tmpDir, NotesFile), nil, 0644)
+	os.Symlink(filepath.Join(tmpDir, "scrape.py"), filepath.Join(tmpDir, "scrape-link"))
+
+	entries, err := ScanWithOptions(tmpDir, ScanOptions{IncludeFiles: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]Entry{}
+	for _, e := range entries {
+		got[e.Name] = e
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected the directory, the file and the link, got %v", got)
+	}
+	if got["2025-01-19-redis"].IsFile {
+		t.Error("directory should not be marked as a file")
+	}
+	if e := got["scrape.py"]; !e.IsFile || e.Symlink {
+		t.Errorf("scrape.py = %+v, want a file", e)
+	}
+	if e := got["scrape-link"]; !e.IsFile || !e.Symlink {
+		t.Errorf("scrape-link = %+v, want a symlinked file", e)
+	}
+
+	// Without the option files stay out
+	entries, err = Scan(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected only the directory, got %d entries", len(entries))
+	}
+}
+
+func TestScanDepthUndatedWorkspace(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Undated workspaces with folders of their own are still workspaces
+	os.MkdirAll(filepath.Join(tmpDir, "webapp", "src"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, "webapp", "package.json"), []byte("{}"), 0644)
+	os.MkdirAll(filepath.Join(tmpDir, "tool", ".git"), 0755)
+	os.MkdirAll(filepath.Join(tmpDir, "tool", "cmd"), 0755)
+	// A topic folder may keep a README beside its dated workspaces
+	os.MkdirAll(filepath.Join(tmpDir, "notes", "2024-01-15-idea", "src"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, "notes", "README.md"), nil, 0644)
+
+	entries, err := ScanWithOptions(tmpDir, ScanOptions{MaxDepth: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name)
+	}
+	sort.Strings(names)
+	want := []string{filepath.Join("notes", "2024-01-15-idea"), "tool", "webapp"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("got %v, want %v", names, want)
+	}
+}
+
+func TestScanDepthSymlinkLoop(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// A link back to the base inside a topic folder must not be walked
+	os.MkdirAll(filepath.Join(tmpDir, "topic", "leaf"), 0755)
+	os.Symlink(tmpDir, filepath.Join(tmpDir, "topic", "up"))
+
+	entries, err := ScanWithOptions(tmpDir, ScanOptions{MaxDepth: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("expected topic/leaf and topic/up, got %+v", entries)
+	}
+}
+
+func TestParseName(t *testing.T) {
+	tests := []struct {
+		name      string
+		wantDate  string
+		wantLabel string
+		wantDated bool
+	}{
+		{"2024-01-15-redis-test", "2024-01-15", "redis-test", true},
+		{"2024-01-15-", "2024-01-15", "", true},
+		{"2024-13-45-bad-date", "", "2024-13-45-bad-date", false},
+		{"2024-01-15", "", "2024-01-15", false},
+		{"2024-01-15_underscore", "", "2024-01-15_underscore", false},
+		{"plain-name", "", "plain-name", false},
+		{"", "", "", false},
+		{"2024-01-15-日本語-メモ", "2024-01-15", "日本語-メモ", true},
+		{"2024-01-15-🚀-launch", "2024-01-15", "🚀-launch", true},
+		{"2024-01-1日-x", "", "2024-01-1日-x", false},
+		{"日本語-2024-01-15", "", "日本語-2024-01-15", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			date, label, dated := ParseName(tt.name)
+			if dated != tt.wantDated {
+				t.Fatalf("hasDate: got %v, want %v", dated, tt.wantDated)
+			}
+			if label != tt.wantLabel {
+				t.Errorf("label: got %q, want %q", label, tt.wantLabel)
+			}
+			if tt.wantDated && date.Format("2006-01-02") != tt.wantDate {
+				t.Errorf("date: got %s, want %s", date.Format("2006-01-02"), tt.wantDate)
+			}
+			if !tt.wantDated && !date.IsZero() {
+				t.Errorf("date should be zero, got %s", date)
+			}
+		})
+	}
+}
+
+func TestScanCreatedDate(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, d := range []string{"2024-01-15-project", "undated"} {
+		if err := os.Mkdir(filepath.Join(tmpDir, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := Scan(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, e := range entries {
+		switch e.Name {
+		case "2024-01-15-project":
+			if got := e.CreatedDate.Format("2006-01-02"); got != "2024-01-15" {
+				t.Errorf("CreatedDate: got %s", got)
+			}
+		case "undated":
+			if !e.CreatedDate.IsZero() {
+				t.Errorf("CreatedDate should be zero, got %s", e.CreatedDate)
+			}
+		}
+	}
+}
+
 func TestCreate(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -95,6 +517,11 @@ func TestCreate(t *testing.T) {
 	if base != expected {
 		t.Errorf("expected %s, got %s", expected, base)
 	}
+
+	meta, _ := GetMeta(tmpDir, base)
+	if meta.Kind != KindCreated || meta.SourceURL != "" || time.Since(meta.CreatedAt) > time.Minute {
+		t.Errorf("meta = %+v, want created just now", meta)
+	}
 }
 
 func TestCreateUnique(t *testing.T) {
@@ -122,6 +549,110 @@ func TestCreateUnique(t *testing.T) {
 	}
 }
 
+func TestCreateRaw(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	path, err := CreateRaw(tmpDir, "stable project")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if base := filepath.Base(path); base != "stable-project" {
+		t.Errorf("expected stable-project, got %s", base)
+	}
+
+	// Collisions are still uniquified
+	path2, err := CreateRaw(tmpDir, "stable project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if base := filepath.Base(path2); base != "stable-project-2" {
+		t.Errorf("expected stable-project-2, got %s", base)
+	}
+}
+
+func TestCreateRejectsEscapingNames(t *testing.T) {
+	for _, name := range []string{"../evil", "a/b", "/etc/evil", "..", ""} {
+		t.Run(name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+
+			if _, err := Create(tmpDir, name); err == nil {
+				t.Errorf("Create(%q) should fail", name)
+			}
+			if _, err := CreateRaw(tmpDir, name); err == nil {
+				t.Errorf("CreateRaw(%q) should fail", name)
+			}
+
+			// Nothing may be created, inside or outside the base
+			entries, _ := os.ReadDir(tmpDir)
+			if len(entries) != 0 {
+				t.Errorf("expected no directories, got %d", len(entries))
+			}
+			if _, err := os.Stat(filepath.Join(filepath.Dir(tmpDir), "evil")); !os.IsNotExist(err) {
+				t.Error("directory escaped the base path")
+			}
+		})
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"redis-test", false},
+		{"quick test", false},
+		{"v1.2", false},
+		{"", true},
+		{"   ", true},
+		{"..", true},
+		{"a/b", true},
+		{`a\b`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateName(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateName(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSanitizeDirName(t *testing.T) {
+	tests := []struct {
+		name  string
+		want  string
+		lower string
+	}{
+		{"Repo.JS", "Repo.JS", "repo.js"},
+		{"my repo!", "my-repo", "my-repo"},
+		{"my_tool", "my_tool", "my_tool"},
+		{"  --a...b__c-- ", "a-b-c", "a-b-c"},
+		{".hidden", "hidden", "hidden"},
+		{"Café Ölmühle", "Café-Ölmühle", "café-ölmühle"},
+		{"東京 メモ", "東京-メモ", "東京-メモ"},
+		{"!!!", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizeDirName(tt.name); got != tt.want {
+				t.Errorf("sanitizeDirName(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+			SetLowercaseNames(true)
+			defer SetLowercaseNames(false)
+			if got := sanitizeDirName(tt.name); got != tt.lower {
+				t.Errorf("lowercased sanitizeDirName(%q) = %q, want %q", tt.name, got, tt.lower)
+			}
+		})
+	}
+
+	if _, err := CreateRaw(t.TempDir(), "!!!"); err == nil {
+		t.Error("a name with no letters or digits should be rejected")
+	}
+}
+
 func TestTouch(t *testing.T) {
 	tmpDir := t.TempDir()
 	testDir := filepath.Join(tmpDir, "test")
@@ -173,6 +704,69 @@ func TestDeleteSafety(t *testing.T) {
 	}
 }
 
+func TestDeleteBaseDir(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Trailing slashes and symlinks still resolve to the base itself
+	link := filepath.Join(t.TempDir(), "link")
+	os.Symlink(tmpDir, link)
+
+	for _, target := range []string{tmpDir, tmpDir + "/", link} {
+		if err := Delete(tmpDir, target); !errors.Is(err, ErrBaseDir) {
+			t.Errorf("Delete(%q): expected ErrBaseDir, got %v", target, err)
+		}
+	}
+
+	if _, err := os.Stat(tmpDir); err != nil {
+		t.Error("base directory should still exist")
+	}
+}
+
+func TestDeleteBaseParent(t *testing.T) {
+	parent := t.TempDir()
+	base := filepath.Join(parent, "tries")
+	os.Mkdir(base, 0755)
+
+	err := Delete(base, parent)
+	if err == nil {
+		t.Fatal("expected error when deleting the base's parent")
+	}
+	if errors.Is(err, ErrBaseDir) {
+		t.Error("parent of base should fail the containment check, not ErrBaseDir")
+	}
+
+	// A sibling sharing the base's name as a prefix is outside too
+	sibling := filepath.Join(parent, "tries-other")
+	os.Mkdir(sibling, 0755)
+	if err := Delete(base, sibling); err == nil {
+		t.Error("expected error when deleting a sibling of base")
+	}
+}
+
+func TestNormalizePath(t *testing.T) {
+	home, _ := os.UserHomeDir()
+	cwd, _ := os.Getwd()
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"/tmp/tries", "/tmp/tries"},
+		{"/tmp/tries/", "/tmp/tries"},
+		{"/tmp//tries/./", "/tmp/tries"},
+		{"./tries", filepath.Join(cwd, "tries")},
+		{"tries/../other", filepath.Join(cwd, "other")},
+		{"~/src/tries/", filepath.Join(home, "src", "tries")},
+		{"~", home},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizePath(tt.input); got != tt.want {
+			t.Errorf("NormalizePath(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
 func TestDatePrefix(t *testing.T) {
 	prefix := DatePrefix()
 	expected := time.Now().Format("2006-01-02")
@@ -180,3 +774,86 @@ func TestDatePrefix(t *testing.T) {
 		t.Errorf("expected %s, got %s", expected, prefix)
 	}
 }
+
+func TestDateFormat(t *testing.T) {
+	t.Cleanup(func() { SetDateFormat(DefaultDateFormat) })
+
+	tests := []struct {
+		layout string
+		name   string
+		label  string
+		date   time.Time
+	}{
+		{"2006-01-02-1504", "2025-01-19-0930-redis", "redis", time.Date(2025, 1, 19, 9, 30, 0, 0, time.Local)},
+		{"20060102", "20250119-redis-test", "redis-test", time.Date(2025, 1, 19, 0, 0, 0, 0, time.Local)},
+		{"06.01.02", "25.01.19-x", "x", time.Date(2025, 1, 19, 0, 0, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.layout, func(t *testing.T) {
+			if err := SetDateFormat(tt.layout); err != nil {
+				t.Fatal(err)
+			}
+			date, label, ok := ParseName(tt.name)
+			if !ok || label != tt.label || !date.Equal(tt.date) {
+				t.Errorf("ParseName(%q) = %v, %q, %v", tt.name, date, label, ok)
+			}
+
+			// The default format no longer counts as a date
+			if _, _, ok := ParseName("2025-01-19-other"); ok && tt.layout != DefaultDateFormat {
+				t.Error("a different format should not be recognized")
+			}
+
+			// New names round-trip through ParseName
+			path, err := Create(t.TempDir(), "demo")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, label, ok := ParseName(filepath.Base(path)); !ok || label != "demo" {
+				t.Errorf("created %s, which ParseName doesn't split", filepath.Base(path))
+			}
+		})
+	}
+}
+
+func TestValidateDateFormat(t *testing.T) {
+	for _, layout := range []string{"2006-01-02", "2006-01-02-1504", "20060102", "2006_01_02.15-04-05"} {
+		if err := ValidateDateFormat(layout); err != nil {
+			t.Errorf("ValidateDateFormat(%q) = %v", layout, err)
+		}
+	}
+	for _, layout := range []string{"", "2006/01/02", "2006-01-02 15:04", "Jan 2 2006", "2006-01", "2006-1-2", "15-04"} {
+		if err := ValidateDateFormat(layout); err == nil {
+			t.Errorf("ValidateDateFormat(%q) should fail", layout)
+		}
+	}
+	if err := SetDateFormat("2006/01/02"); err == nil || DatePrefix() != time.Now().Format(DefaultDateFormat) {
+		t.Error("an invalid format should be rejected and leave the format unchanged")
+	}
+}
+
+func TestIsEmpty(t *testing.T) {
+	base := t.TempDir()
+
+	created, err := Create(base, "unused")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := IsEmpty(created); err != nil || !ok {
+		t.Errorf("a fresh workspace should be empty, got %v, %v", ok, err)
+	}
+
+	os.WriteFile(filepath.Join(created, ".envrc"), nil, 0644)
+	if ok, _ := IsEmpty(created); ok {
+		t.Error("a workspace with a hidden file is not empty")
+	}
+
+	bare := filepath.Join(base, "bare")
+	os.Mkdir(bare, 0755)
+	if ok, _ := IsEmpty(bare); !ok {
+		t.Error("a directory with nothing in it should be empty")
+	}
+
+	if _, err := IsEmpty(filepath.Join(base, "missing")); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
@@ -11,6 +11,8 @@ var version = "dev"
 
 func main() {
 	cli.Version = version
+	// Errors, including cli.ErrCancelled, exit with 1 so the shell
+	// wrapper doesn't act on the output
 	if err := cli.Execute(); err != nil {
 		os.Exit(1)
 	}
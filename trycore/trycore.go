@@ -0,0 +1,192 @@
+// Package trycore is try's workspace logic without the shell or the TUI,
+// for embedding in other programs. A Dir lists, resolves, creates, clones
+// and deletes the workspaces in a tries directory; everything returns
+// plain data and paths, and nothing prints, prompts or emits shell code.
+//
+//	tries := trycore.Open("~/src/tries")
+//	ws, err := tries.Resolve("redis")
+//	if err == nil {
+//		fmt.Println(ws.Path)
+//	}
+package trycore
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tobi/try/internal/fuzzy"
+	"github.com/tobi/try/internal/workspace"
+)
+
+// ErrNoMatch is returned by Resolve when nothing matches the query.
+var ErrNoMatch = fuzzy.ErrNoMatch
+
+// Workspace describes one entry of a tries directory.
+type Workspace struct {
+	Name      string    // name, or path relative to the tries directory when nested
+	Path      string    // absolute path
+	ModTime   time.Time // last modification, which orders List
+	CreatedAt time.Time // when try made it, or the date in its name; zero if unknown
+	Kind      string    // "created", "cloned", "imported" or "unknown"
+	SourceURL string    // what it was cloned from, if anything
+	Note      string
+	Tags      []string
+	Symlink   bool // a symlink rather than a directory
+	Broken    bool // a symlink whose target is gone
+	IsFile    bool // a single file, listed with IncludeFiles
+	Hidden    bool // named with a leading ".", listed with IncludeHidden
+}
+
+// Dir is a tries directory. The zero Depth lists only the top level.
+type Dir struct {
+	Path          string // the tries directory
+	Depth         int    // directory levels to scan, as with --depth
+	IncludeFiles  bool   // list regular files as well as directories
+	IncludeHidden bool   // list entries whose names start with "."
+}
+
+// Open returns the tries directory at path, with ~ expanded and the path
+// made absolute and clean. An empty path means the default, $TRY_PATH or
+// ~/src/tries.
+func Open(path string) Dir {
+	if path == "" {
+		path = workspace.DefaultPath()
+	}
+	return Dir{Path: workspace.NormalizePath(path)}
+}
+
+// List returns the workspaces in d, most recently modified first.
+func (d Dir) List() ([]Workspace, error) {
+	entries, err := d.scan()
+	if err != nil {
+		return nil, err
+	}
+	return d.workspaces(entries)
+}
+
+// Resolve returns the workspace query names, as 'go-try which' does: a name,
+// or name without the date prefix, equal to query wins outright, else the
+// best match, ranked as Match ranks them, if it is unambiguous. It fails
+// with ErrNoMatch, or with an error listing the candidates.
+func (d Dir) Resolve(query string) (Workspace, error) {
+	entries, err := d.scan()
+	if err != nil {
+		return Workspace{}, err
+	}
+	notes, tags, err := d.labels()
+	if err != nil {
+		return Workspace{}, err
+	}
+	e, err := fuzzy.BestMatch(query, entries, notes, tags)
+	if err != nil {
+		return Workspace{}, err
+	}
+	ws, err := d.workspaces([]workspace.Entry{e})
+	if err != nil {
+		return Workspace{}, err
+	}
+	return ws[0], nil
+}
+
+// Match returns every workspace matching query, best first, ranked as
+// the picker's filter ranks them: by name, then by tag, then by note.
+func (d Dir) Match(query string) ([]Workspace, error) {
+	entries, err := d.scan()
+	if err != nil {
+		return nil, err
+	}
+	notes, tags, err := d.labels()
+	if err != nil {
+		return nil, err
+	}
+	return d.workspaces(fuzzy.Rank(entries, query, notes, tags))
+}
+
+// labels loads the notes and tags of d's workspaces, which queries also
+// match.
+func (d Dir) labels() (map[string]string, map[string][]string, error) {
+	notes, err := workspace.LoadNotes(d.Path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read notes: %w", err)
+	}
+	tags, err := workspace.LoadTags(d.Path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read tags: %w", err)
+	}
+	return notes, tags, nil
+}
+
+// Create makes a new workspace named name, with today's date prefix if
+// dated is set, and returns its path. The name is made unique if taken.
+func (d Dir) Create(name string, dated bool) (string, error) {
+	if err := workspace.EnsureDir(d.Path); err != nil {
+		return "", err
+	}
+	if dated {
+		return workspace.Create(d.Path, name)
+	}
+	return workspace.CreateRaw(d.Path, name)
+}
+
+// Clone clones the git repository at url into a new workspace and returns
+// its path. git's progress is discarded.
+func (d Dir) Clone(url string) (string, error) {
+	if err := workspace.EnsureDir(d.Path); err != nil {
+		return "", err
+	}
+	return workspace.CloneWithOptions(d.Path, url, workspace.CloneOptions{})
+}
+
+// Delete moves the workspace at path to the trash, from where 'try undo'
+// can bring it back, and returns where it went. path must be inside d.
+func (d Dir) Delete(path string) (string, error) {
+	return workspace.Trash(d.Path, path)
+}
+
+// scan lists d's entries, most recent first.
+func (d Dir) scan() ([]workspace.Entry, error) {
+	entries, err := workspace.ScanWithOptions(d.Path, workspace.ScanOptions{
+		MaxDepth:      d.Depth,
+		IncludeFiles:  d.IncludeFiles,
+		IncludeHidden: d.IncludeHidden,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan tries directory: %w", err)
+	}
+	return entries, nil
+}
+
+// workspaces describes entries, with their notes and tags.
+func (d Dir) workspaces(entries []workspace.Entry) ([]Workspace, error) {
+	notes, err := workspace.LoadNotes(d.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notes: %w", err)
+	}
+	tags, err := workspace.LoadTags(d.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tags: %w", err)
+	}
+
+	result := make([]Workspace, len(entries))
+	for i, e := range entries {
+		created := e.CreatedAt
+		if created.IsZero() {
+			created = e.CreatedDate
+		}
+		result[i] = Workspace{
+			Name:      e.Name,
+			Path:      e.Path,
+			ModTime:   e.ModTime,
+			CreatedAt: created,
+			Kind:      e.Kind.String(),
+			SourceURL: e.SourceURL,
+			Note:      notes[e.Name],
+			Tags:      tags[e.Name],
+			Symlink:   e.Symlink,
+			Broken:    e.Broken,
+			IsFile:    e.IsFile,
+			Hidden:    e.Hidden,
+		}
+	}
+	return result, nil
+}
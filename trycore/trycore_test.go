@@ -0,0 +1,74 @@
+package trycore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tobi/try/internal/workspace"
+)
+
+func TestDir(t *testing.T) {
+	tries := Open(t.TempDir())
+
+	redis, err := tries.Create("redis test", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(redis, "-redis-test") || filepath.Dir(redis) != tries.Path {
+		t.Errorf("Create = %q, want a dated redis-test in %s", redis, tries.Path)
+	}
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(redis, old, old)
+
+	dotfiles, err := tries.Create("dotfiles", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(dotfiles) != "dotfiles" {
+		t.Errorf("undated Create = %q", dotfiles)
+	}
+	workspace.SetNote(tries.Path, "dotfiles", "keep")
+	workspace.SetTags(tries.Path, "dotfiles", []string{"home"})
+
+	list, err := tries.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].Path != dotfiles || list[1].Path != redis {
+		t.Fatalf("List = %+v, want dotfiles then redis", list)
+	}
+	if ws := list[0]; ws.Note != "keep" || len(ws.Tags) != 1 || ws.Tags[0] != "home" || ws.Kind != "created" {
+		t.Errorf("dotfiles = %+v, want its note, tags and kind", ws)
+	}
+	if list[1].CreatedAt.IsZero() {
+		t.Error("redis should have a creation time")
+	}
+
+	ws, err := tries.Resolve("redistest")
+	if err != nil || ws.Path != redis {
+		t.Errorf("Resolve = %+v, %v; want %s", ws, err, redis)
+	}
+	if ws, err := tries.Resolve("home"); err != nil || ws.Path != dotfiles {
+		t.Errorf("Resolve by tag = %+v, %v; want %s", ws, err, dotfiles)
+	}
+	if _, err := tries.Resolve("mysql"); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("Resolve of nothing: got %v, want ErrNoMatch", err)
+	}
+	if matches, err := tries.Match("e"); err != nil || len(matches) != 2 {
+		t.Errorf("Match = %+v, %v; want both", matches, err)
+	}
+
+	if _, err := tries.Delete(redis); err != nil {
+		t.Fatal(err)
+	}
+	if list, _ := tries.List(); len(list) != 1 {
+		t.Errorf("after Delete, List = %+v", list)
+	}
+	if _, err := tries.Delete(t.TempDir()); err == nil {
+		t.Error("Delete outside the tries directory should fail")
+	}
+}